@@ -0,0 +1,35 @@
+// Package crypto aead认证加密模式工具包
+package crypto
+
+import (
+	"crypto/cipher"
+	"fmt"
+)
+
+/*
+本仓库的aead加密函数统一使用如下形式：
+XxxEncrypt(key, nonce, plaintext, additionalData []byte) ([]byte, error)
+XxxDecrypt(key, nonce, ciphertext, additionalData []byte) ([]byte, error)
+密文为加密结果与认证标签的拼接，不包含nonce，nonce需要由调用方生成并与密文一起保存。
+同一密钥下nonce绝对不能重复使用，nonce较短（12字节）的算法在随机生成nonce时需要控制单个密钥的加密次数。
+*/
+
+// aeadEncrypt aead加密
+func aeadEncrypt(aead cipher.AEAD, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("nonce length must be %d", aead.NonceSize())
+	}
+	return aead.Seal(nil, nonce, plaintext, additionalData), nil
+}
+
+// aeadDecrypt aead解密
+func aeadDecrypt(aead cipher.AEAD, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("nonce length must be %d", aead.NonceSize())
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("open failed: %w", err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,41 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// mustHex 解析16进制字符串，用于测试向量
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func Test_AeadNonceSize(t *testing.T) {
+	key := make([]byte, 32)
+	tests := []struct {
+		name    string
+		encrypt func(key, nonce, plaintext, additionalData []byte) ([]byte, error)
+		decrypt func(key, nonce, ciphertext, additionalData []byte) ([]byte, error)
+		key     []byte
+	}{
+		{name: "aes-gcm", encrypt: AesGcmEncrypt, decrypt: AesGcmDecrypt, key: key[:16]},
+		{name: "sm4-gcm", encrypt: Sm4GcmEncrypt, decrypt: Sm4GcmDecrypt, key: key[:16]},
+		{name: "chacha20-poly1305", encrypt: ChaCha20Poly1305Encrypt, decrypt: ChaCha20Poly1305Decrypt, key: key},
+		{name: "xchacha20-poly1305", encrypt: XChaCha20Poly1305Encrypt, decrypt: XChaCha20Poly1305Decrypt, key: key},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nonce := make([]byte, 8)
+			if _, err := tt.encrypt(tt.key, nonce, []byte("Hello World"), nil); err == nil {
+				t.Errorf("encrypt with wrong nonce size error = nil, wantErr true")
+			}
+			if _, err := tt.decrypt(tt.key, nonce, make([]byte, 32), nil); err == nil {
+				t.Errorf("decrypt with wrong nonce size error = nil, wantErr true")
+			}
+		})
+	}
+}
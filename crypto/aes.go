@@ -49,3 +49,42 @@ func AesCbcDecrypt(key, iv, ciphertext []byte, padding string) (plaintext []byte
 	decrypter.CryptBlocks(plaintext, ciphertext)
 	return UnPadding(padding, plaintext), nil
 }
+
+// AesGcmEncrypt gcm模式的aes加密
+// @param key 密钥
+// @param nonce 12字节随机数
+// @param plaintext 明文
+// @param additionalData 附加认证数据，可以为空
+func AesGcmEncrypt(key, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newAesGcm(key)
+	if err != nil {
+		return nil, err
+	}
+	return aeadEncrypt(aead, nonce, plaintext, additionalData)
+}
+
+// AesGcmDecrypt gcm模式的aes解密
+// @param key 密钥
+// @param nonce 12字节随机数
+// @param ciphertext 密文
+// @param additionalData 附加认证数据，需要与加密时一致
+func AesGcmDecrypt(key, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := newAesGcm(key)
+	if err != nil {
+		return nil, err
+	}
+	return aeadDecrypt(aead, nonce, ciphertext, additionalData)
+}
+
+// newAesGcm 创建aes-gcm
+func newAesGcm(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create block failed: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm failed: %w", err)
+	}
+	return aead, nil
+}
@@ -104,3 +104,60 @@ func Test_AesCbcDecrypt(t *testing.T) {
 		})
 	}
 }
+
+func Test_AesGcmEncryptDecrypt(t *testing.T) {
+	tests := []struct {
+		name           string
+		key            string
+		nonce          string
+		plaintext      string
+		additionalData string
+		want           string
+	}{
+		{
+			// The Galois/Counter Mode of Operation (GCM) 测试用例2
+			name:      "#1",
+			key:       "00000000000000000000000000000000",
+			nonce:     "000000000000000000000000",
+			plaintext: "00000000000000000000000000000000",
+			want:      "0388dace60b6a392f328c2b971b2fe78ab6e47d42cec13bdf53a67b21257bddf",
+		},
+		{
+			// The Galois/Counter Mode of Operation (GCM) 测试用例4
+			name:  "#2",
+			key:   "feffe9928665731c6d6a8f9467308308",
+			nonce: "cafebabefacedbaddecaf888",
+			plaintext: "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a72" +
+				"1c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39",
+			additionalData: "feedfacedeadbeeffeedfacedeadbeefabaddad2",
+			want: "42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e" +
+				"21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e0915bc94fbc" +
+				"3221a5db94fae95ae7121a47",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, nonce := mustHex(tt.key), mustHex(tt.nonce)
+			plaintext, additionalData := mustHex(tt.plaintext), mustHex(tt.additionalData)
+			got, err := AesGcmEncrypt(key, nonce, plaintext, additionalData)
+			if err != nil {
+				t.Errorf("AesGcmEncrypt() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, mustHex(tt.want)) {
+				t.Errorf("AesGcmEncrypt() got = %x, want %v", got, tt.want)
+			}
+			got, err = AesGcmDecrypt(key, nonce, got, additionalData)
+			if err != nil {
+				t.Errorf("AesGcmDecrypt() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, plaintext) {
+				t.Errorf("AesGcmDecrypt() got = %x, want %x", got, plaintext)
+			}
+			if _, err := AesGcmDecrypt(key, nonce, mustHex(tt.want), []byte("tampered")); err == nil {
+				t.Errorf("AesGcmDecrypt() with tampered additional data error = nil, wantErr true")
+			}
+		})
+	}
+}
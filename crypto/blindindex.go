@@ -0,0 +1,152 @@
+// Package crypto 盲索引工具包
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+/*
+盲索引（blind index）用于对随机化加密的字段做等值查询：
+密文列继续使用AES/SM4等随机化加密，另外保存一列由明文计算出的带密钥哈希（HMAC），查询时对查询值计算相同的哈希即可。
+索引密钥由KeyProvider中的主密钥通过DeriveKey派生（info为"tutils-blind-index/"+索引名称），
+因此可以与加密共用同一套密钥管理，且不同索引之间、索引与加密之间的密钥互不相关。
+截断哈希结果可以让不同明文发生碰撞，从而降低通过索引列推测明文分布的风险，查询时需要再解密密文确认。
+*/
+
+// blindIndexInfoPrefix 盲索引密钥派生info前缀
+const blindIndexInfoPrefix = "tutils-blind-index/"
+
+// BlindIndexConfig 盲索引配置
+type BlindIndexConfig struct {
+	Name      string              // 索引名称，同一个构造器内唯一
+	Hash      string              // 哈希算法，HashSha256或HashSm3
+	Bits      int                 // 截断后保留的位数，0表示不截断
+	Normalize func(string) string // 计算前对明文的规范化处理，可以为空
+}
+
+// BlindIndexer 盲索引构造器，一个字段可以配置多个索引，例如完整值与后四位
+type BlindIndexer struct {
+	provider KeyProvider        // 密钥提供者
+	keyID    string             // 主密钥id
+	configs  []BlindIndexConfig // 索引配置
+}
+
+// NewBlindIndexer 创建盲索引构造器
+// @param provider 密钥提供者
+// @param keyID 主密钥id
+// @param configs 索引配置
+func NewBlindIndexer(provider KeyProvider, keyID string, configs ...BlindIndexConfig) (*BlindIndexer, error) {
+	if provider == nil {
+		return nil, errors.New("key provider is nil")
+	}
+	names := make(map[string]struct{}, len(configs))
+	for _, config := range configs {
+		if config.Name == "" {
+			return nil, errors.New("blind index name is empty")
+		}
+		if _, ok := names[config.Name]; ok {
+			return nil, fmt.Errorf("duplicate blind index: %s", config.Name)
+		}
+		names[config.Name] = struct{}{}
+		if _, err := newHash(config.Hash); err != nil {
+			return nil, err
+		}
+		if config.Bits < 0 {
+			return nil, fmt.Errorf("invalid bits of blind index %s", config.Name)
+		}
+	}
+	return &BlindIndexer{provider: provider, keyID: keyID, configs: configs}, nil
+}
+
+// Index 计算指定索引的值
+// @param name 索引名称
+// @param value 明文
+func (b *BlindIndexer) Index(name, value string) ([]byte, error) {
+	for _, config := range b.configs {
+		if config.Name == name {
+			return b.compute(config, value)
+		}
+	}
+	return nil, fmt.Errorf("blind index not found: %s", name)
+}
+
+// Indexes 计算全部索引的值，key为索引名称
+// @param value 明文
+func (b *BlindIndexer) Indexes(value string) (map[string][]byte, error) {
+	indexes := make(map[string][]byte, len(b.configs))
+	for _, config := range b.configs {
+		index, err := b.compute(config, value)
+		if err != nil {
+			return nil, err
+		}
+		indexes[config.Name] = index
+	}
+	return indexes, nil
+}
+
+// compute 计算索引值
+func (b *BlindIndexer) compute(config BlindIndexConfig, value string) ([]byte, error) {
+	masterKey, err := b.provider.Key(b.keyID)
+	if err != nil {
+		return nil, fmt.Errorf("get key failed: %w", err)
+	}
+	key, err := DeriveKey(config.Hash, masterKey, blindIndexInfoPrefix+config.Name, 32)
+	if err != nil {
+		return nil, err
+	}
+	if config.Normalize != nil {
+		value = config.Normalize(value)
+	}
+	sum, err := HmacSum(config.Hash, key, []byte(value))
+	if err != nil {
+		return nil, err
+	}
+	if config.Bits == 0 || config.Bits >= len(sum)*8 {
+		return sum, nil
+	}
+	// 保留前Bits位，最后一个字节多余的低位置0
+	sum = sum[:(config.Bits+7)/8]
+	if rem := config.Bits % 8; rem != 0 {
+		sum[len(sum)-1] &= byte(0xff << (8 - rem))
+	}
+	return sum, nil
+}
+
+// NormalizeTrimLower 去除首尾空白并转为小写
+func NormalizeTrimLower(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// NormalizeDigits 只保留数字，例如将"138-0013-8000"规范化为"13800138000"
+func NormalizeDigits(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, value)
+}
+
+// NormalizeLast 返回保留最后n个字符的规范化函数，常与NormalizeDigits组合计算后四位索引
+func NormalizeLast(n int) func(string) string {
+	return func(value string) string {
+		runes := []rune(value)
+		if len(runes) <= n {
+			return value
+		}
+		return string(runes[len(runes)-n:])
+	}
+}
+
+// NormalizeChain 按顺序组合多个规范化函数
+func NormalizeChain(normalizers ...func(string) string) func(string) string {
+	return func(value string) string {
+		for _, normalize := range normalizers {
+			value = normalize(value)
+		}
+		return value
+	}
+}
@@ -0,0 +1,87 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_BlindIndexer(t *testing.T) {
+	provider := StaticKeyProvider{"pii": []byte("0123456789abcdef0123456789abcdef")}
+	indexer, err := NewBlindIndexer(provider, "pii",
+		BlindIndexConfig{Name: "phone", Hash: HashSm3, Bits: 32, Normalize: NormalizeDigits},
+		BlindIndexConfig{Name: "phone_last4", Hash: HashSha256, Bits: 12,
+			Normalize: NormalizeChain(NormalizeDigits, NormalizeLast(4))},
+		BlindIndexConfig{Name: "email", Hash: HashSha256, Normalize: NormalizeTrimLower},
+	)
+	if err != nil {
+		t.Fatalf("NewBlindIndexer() error = %v", err)
+	}
+
+	indexes, err := indexer.Indexes("138-0013-8000")
+	if err != nil {
+		t.Fatalf("Indexes() error = %v", err)
+	}
+	if len(indexes["phone"]) != 4 || len(indexes["phone_last4"]) != 2 || indexes["phone_last4"][1]&0x0f != 0 {
+		t.Errorf("Indexes() got wrong truncation: %x", indexes)
+	}
+	phone, err := indexer.Index("phone", "13800138000")
+	if err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if !bytes.Equal(phone, indexes["phone"]) {
+		t.Errorf("Index() got = %x, want %x", phone, indexes["phone"])
+	}
+	last4, err := indexer.Index("phone_last4", "13912348000")
+	if err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if !bytes.Equal(last4, indexes["phone_last4"]) {
+		t.Errorf("Index() got = %x, want %x", last4, indexes["phone_last4"])
+	}
+	email1, _ := indexer.Index("email", " Foo@Example.com")
+	email2, _ := indexer.Index("email", "foo@example.com")
+	if len(email1) != 32 || !bytes.Equal(email1, email2) {
+		t.Errorf("Index() got = %x and %x", email1, email2)
+	}
+	if _, err := indexer.Index("unknown", "foo"); err == nil {
+		t.Errorf("Index() with unknown name error = nil, wantErr true")
+	}
+
+	// 不同密钥得到的索引不同
+	other, err := NewBlindIndexer(StaticKeyProvider{"pii": []byte("another key")}, "pii",
+		BlindIndexConfig{Name: "phone", Hash: HashSm3, Bits: 32, Normalize: NormalizeDigits})
+	if err != nil {
+		t.Fatalf("NewBlindIndexer() error = %v", err)
+	}
+	if got, _ := other.Index("phone", "13800138000"); bytes.Equal(got, phone) {
+		t.Errorf("Index() with different key got same index")
+	}
+}
+
+func Test_NewBlindIndexerInvalid(t *testing.T) {
+	provider := StaticKeyProvider{}
+	tests := []struct {
+		name    string
+		configs []BlindIndexConfig
+	}{
+		{name: "empty-name", configs: []BlindIndexConfig{{Hash: HashSha256}}},
+		{name: "duplicate", configs: []BlindIndexConfig{{Name: "a", Hash: HashSha256}, {Name: "a", Hash: HashSm3}}},
+		{name: "hash", configs: []BlindIndexConfig{{Name: "a", Hash: "md5"}}},
+		{name: "sha1", configs: []BlindIndexConfig{{Name: "a", Hash: OtpHashSha1}}},
+		{name: "bits", configs: []BlindIndexConfig{{Name: "a", Hash: HashSm3, Bits: -1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewBlindIndexer(provider, "pii", tt.configs...); err == nil {
+				t.Errorf("NewBlindIndexer() error = nil, wantErr true")
+			}
+		})
+	}
+	indexer, err := NewBlindIndexer(provider, "pii", BlindIndexConfig{Name: "a", Hash: HashSm3})
+	if err != nil {
+		t.Fatalf("NewBlindIndexer() error = %v", err)
+	}
+	if _, err := indexer.Index("a", "foo"); err == nil {
+		t.Errorf("Index() with missing key error = nil, wantErr true")
+	}
+}
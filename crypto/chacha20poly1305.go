@@ -0,0 +1,75 @@
+// Package crypto chacha20-poly1305认证加密工具包
+package crypto
+
+import (
+	"crypto/cipher"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+/*
+chacha20-poly1305不依赖aes硬件指令，在不支持aes指令集的arm设备上性能明显优于aes。
+xchacha20-poly1305使用24字节nonce，可以安全地随机生成nonce而无需担心碰撞。
+*/
+
+// ChaCha20Poly1305Encrypt chacha20-poly1305加密
+// @param key 32字节密钥
+// @param nonce 12字节随机数
+// @param plaintext 明文
+// @param additionalData 附加认证数据，可以为空
+func ChaCha20Poly1305Encrypt(key, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newChaCha20Poly1305(key, chacha20poly1305.New)
+	if err != nil {
+		return nil, err
+	}
+	return aeadEncrypt(aead, nonce, plaintext, additionalData)
+}
+
+// ChaCha20Poly1305Decrypt chacha20-poly1305解密
+// @param key 32字节密钥
+// @param nonce 12字节随机数
+// @param ciphertext 密文
+// @param additionalData 附加认证数据，需要与加密时一致
+func ChaCha20Poly1305Decrypt(key, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := newChaCha20Poly1305(key, chacha20poly1305.New)
+	if err != nil {
+		return nil, err
+	}
+	return aeadDecrypt(aead, nonce, ciphertext, additionalData)
+}
+
+// XChaCha20Poly1305Encrypt xchacha20-poly1305加密
+// @param key 32字节密钥
+// @param nonce 24字节随机数
+// @param plaintext 明文
+// @param additionalData 附加认证数据，可以为空
+func XChaCha20Poly1305Encrypt(key, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newChaCha20Poly1305(key, chacha20poly1305.NewX)
+	if err != nil {
+		return nil, err
+	}
+	return aeadEncrypt(aead, nonce, plaintext, additionalData)
+}
+
+// XChaCha20Poly1305Decrypt xchacha20-poly1305解密
+// @param key 32字节密钥
+// @param nonce 24字节随机数
+// @param ciphertext 密文
+// @param additionalData 附加认证数据，需要与加密时一致
+func XChaCha20Poly1305Decrypt(key, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := newChaCha20Poly1305(key, chacha20poly1305.NewX)
+	if err != nil {
+		return nil, err
+	}
+	return aeadDecrypt(aead, nonce, ciphertext, additionalData)
+}
+
+// newChaCha20Poly1305 创建chacha20-poly1305或xchacha20-poly1305
+func newChaCha20Poly1305(key []byte, create func(key []byte) (cipher.AEAD, error)) (cipher.AEAD, error) {
+	aead, err := create(key)
+	if err != nil {
+		return nil, fmt.Errorf("create aead failed: %w", err)
+	}
+	return aead, nil
+}
@@ -0,0 +1,73 @@
+package crypto
+
+import (
+	"reflect"
+	"testing"
+)
+
+// chachaTestPlaintext RFC 8439 第2.8.2节使用的明文
+const chachaTestPlaintext = "Ladies and Gentlemen of the class of '99: If I could offer you only one tip " +
+	"for the future, sunscreen would be it."
+
+func Test_ChaCha20Poly1305EncryptDecrypt(t *testing.T) {
+	key := mustHex("808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f")
+	additionalData := mustHex("50515253c0c1c2c3c4c5c6c7")
+	tests := []struct {
+		name    string
+		nonce   []byte
+		encrypt func(key, nonce, plaintext, additionalData []byte) ([]byte, error)
+		decrypt func(key, nonce, ciphertext, additionalData []byte) ([]byte, error)
+		want    []byte
+	}{
+		{
+			// RFC 8439 第2.8.2节测试向量
+			name:    "chacha20-poly1305",
+			nonce:   mustHex("070000004041424344454647"),
+			encrypt: ChaCha20Poly1305Encrypt,
+			decrypt: ChaCha20Poly1305Decrypt,
+			want: mustHex("d31a8d34648e60db7b86afbc53ef7ec2a4aded51296e08fea9e2b5a736ee62d6" +
+				"3dbea45e8ca9671282fafb69da92728b1a71de0a9e060b2905d6a5b67ecd3b36" +
+				"92ddbd7f2d778b8c9803aee328091b58fab324e4fad675945585808b4831d7bc" +
+				"3ff4def08e4b7a9de576d26586cec64b6116" +
+				"1ae10b594f09e26a7e902ecbd0600691"),
+		},
+		{
+			// draft-irtf-cfrg-xchacha 附录A.3.1测试向量
+			name:    "xchacha20-poly1305",
+			nonce:   mustHex("404142434445464748494a4b4c4d4e4f5051525354555657"),
+			encrypt: XChaCha20Poly1305Encrypt,
+			decrypt: XChaCha20Poly1305Decrypt,
+			want: mustHex("bd6d179d3e83d43b9576579493c0e939572a1700252bfaccbed2902c21396cbb" +
+				"731c7f1b0b4aa6440bf3a82f4eda7e39ae64c6708c54c216cb96b72e1213b452" +
+				"2f8c9ba40db5d945b11b69b982c1bb9e3f3fac2bc369488f76b2383565d3fff9" +
+				"21f9664c97637da9768812f615c68b13b52e" +
+				"c0875924c1c7987947deafd8780acf49"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.encrypt(key, tt.nonce, []byte(chachaTestPlaintext), additionalData)
+			if err != nil {
+				t.Errorf("encrypt error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("encrypt got = %x, want %x", got, tt.want)
+			}
+			got, err = tt.decrypt(key, tt.nonce, tt.want, additionalData)
+			if err != nil {
+				t.Errorf("decrypt error = %v", err)
+				return
+			}
+			if string(got) != chachaTestPlaintext {
+				t.Errorf("decrypt got = %s, want %s", got, chachaTestPlaintext)
+			}
+			if _, err := tt.decrypt(key, tt.nonce, tt.want, nil); err == nil {
+				t.Errorf("decrypt without additional data error = nil, wantErr true")
+			}
+			if _, err := tt.encrypt(key[:16], tt.nonce, nil, nil); err == nil {
+				t.Errorf("encrypt with short key error = nil, wantErr true")
+			}
+		})
+	}
+}
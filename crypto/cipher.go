@@ -0,0 +1,232 @@
+// Package crypto 按名称选择加密算法的统一入口
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+/*
+Encrypt/Decrypt通过算法名称选择认证加密算法，便于通过配置或struct tag在国密与国际算法之间切换。
+与XxxEncrypt系列函数不同，需要nonce的算法会在加密时随机生成nonce并拼接在密文之前：
+密文格式为：nonce || 加密结果 || 认证标签。
+aes-siv与sm4-siv为确定性加密，没有nonce，相同明文总会得到相同密文，只应当用于需要等值查询的字段。
+*/
+
+// 加密算法枚举
+const (
+	// CipherAesGcm aes-gcm，16、24或32字节密钥
+	CipherAesGcm = "aes-gcm"
+	// CipherSm4Gcm sm4-gcm，16字节密钥
+	CipherSm4Gcm = "sm4-gcm"
+	// CipherAesGcmSiv aes-gcm-siv，16或32字节密钥
+	CipherAesGcmSiv = "aes-gcm-siv"
+	// CipherSm4GcmSiv sm4-gcm-siv，16字节密钥
+	CipherSm4GcmSiv = "sm4-gcm-siv"
+	// CipherChaCha20Poly1305 chacha20-poly1305，32字节密钥
+	CipherChaCha20Poly1305 = "chacha20-poly1305"
+	// CipherXChaCha20Poly1305 xchacha20-poly1305，32字节密钥
+	CipherXChaCha20Poly1305 = "xchacha20-poly1305"
+	// CipherAesSiv aes-siv确定性加密，32、48或64字节密钥
+	CipherAesSiv = "aes-siv"
+	// CipherSm4Siv sm4-siv确定性加密，32字节密钥
+	CipherSm4Siv = "sm4-siv"
+)
+
+// cipherSuite 加密算法描述
+type cipherSuite struct {
+	keySize   int    // 推荐密钥长度，用于从主密钥派生密钥
+	nonceSize int    // nonce长度，0表示确定性加密
+	hash      string // 派生密钥使用的哈希算法
+	encrypt   func(key, nonce, plaintext, additionalData []byte) ([]byte, error)
+	decrypt   func(key, nonce, ciphertext, additionalData []byte) ([]byte, error)
+}
+
+// cipherMap 加密算法映射
+var cipherMap = map[string]cipherSuite{
+	CipherAesGcm:            {32, 12, HashSha256, AesGcmEncrypt, AesGcmDecrypt},
+	CipherSm4Gcm:            {16, 12, HashSm3, Sm4GcmEncrypt, Sm4GcmDecrypt},
+	CipherAesGcmSiv:         {32, 12, HashSha256, AesGcmSivEncrypt, AesGcmSivDecrypt},
+	CipherSm4GcmSiv:         {16, 12, HashSm3, Sm4GcmSivEncrypt, Sm4GcmSivDecrypt},
+	CipherChaCha20Poly1305:  {32, 12, HashSha256, ChaCha20Poly1305Encrypt, ChaCha20Poly1305Decrypt},
+	CipherXChaCha20Poly1305: {32, 24, HashSha256, XChaCha20Poly1305Encrypt, XChaCha20Poly1305Decrypt},
+	CipherAesSiv: {64, 0, HashSha256,
+		func(key, _, plaintext, additionalData []byte) ([]byte, error) {
+			return AesSivEncrypt(key, plaintext, sivAdditionalData(additionalData)...)
+		},
+		func(key, _, ciphertext, additionalData []byte) ([]byte, error) {
+			return AesSivDecrypt(key, ciphertext, sivAdditionalData(additionalData)...)
+		}},
+	CipherSm4Siv: {32, 0, HashSm3,
+		func(key, _, plaintext, additionalData []byte) ([]byte, error) {
+			return Sm4SivEncrypt(key, plaintext, sivAdditionalData(additionalData)...)
+		},
+		func(key, _, ciphertext, additionalData []byte) ([]byte, error) {
+			return Sm4SivDecrypt(key, ciphertext, sivAdditionalData(additionalData)...)
+		}},
+}
+
+// Encrypt 使用指定算法加密，需要nonce的算法会随机生成nonce并拼接在密文之前
+// @param cipherName 加密算法
+// @param key 密钥
+// @param plaintext 明文
+// @param additionalData 附加认证数据，可以为空
+func Encrypt(cipherName string, key, plaintext, additionalData []byte) ([]byte, error) {
+	suite, err := getCipherSuite(cipherName)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, suite.nonceSize)
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce failed: %w", err)
+	}
+	ciphertext, err := suite.encrypt(key, nonce, plaintext, additionalData)
+	if err != nil {
+		return nil, err
+	}
+	return append(nonce, ciphertext...), nil
+}
+
+// Decrypt 使用指定算法解密Encrypt的加密结果
+// @param cipherName 加密算法
+// @param key 密钥
+// @param ciphertext 密文
+// @param additionalData 附加认证数据，需要与加密时一致
+func Decrypt(cipherName string, key, ciphertext, additionalData []byte) ([]byte, error) {
+	suite, err := getCipherSuite(cipherName)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < suite.nonceSize {
+		return nil, errors.New("ciphertext too short")
+	}
+	return suite.decrypt(key, ciphertext[:suite.nonceSize], ciphertext[suite.nonceSize:], additionalData)
+}
+
+// CipherConfig 基于KeyProvider的加密配置，实际使用的密钥由主密钥通过DeriveKey派生，
+// 派生方式与StructCrypter一致，因此同一配置下不同方式加密的数据可以互相解密
+type CipherConfig struct {
+	Cipher   string      // 加密算法
+	Encoding string      // 密文编码方式，为空时使用base64
+	Provider KeyProvider // 密钥提供者
+	KeyID    string      // 主密钥id
+}
+
+// CipherConfigProvider 加密配置提供者，用作EncryptedStringOf、JsonEncryptedStringOf等泛型类型的类型参数，
+// 使不同字段可以使用不同的算法与密钥（例如按租户区分的密钥），通常实现为空结构体：
+//
+//	type PhoneConfig struct{}
+//
+//	func (PhoneConfig) CipherConfig() (*crypto.CipherConfig, error) {
+//		return &crypto.CipherConfig{Cipher: crypto.CipherSm4Gcm, Provider: provider, KeyID: "phone"}, nil
+//	}
+//
+// 类型参数的零值会被用于获取配置，因此CipherConfig方法不能依赖接收者中的字段
+type CipherConfigProvider interface {
+	CipherConfig() (*CipherConfig, error)
+}
+
+// resolveCipherConfig 获取并校验加密配置
+func resolveCipherConfig(provider CipherConfigProvider) (*CipherConfig, error) {
+	config, err := provider.CipherConfig()
+	if err != nil {
+		return nil, fmt.Errorf("get cipher config failed: %w", err)
+	}
+	if config == nil {
+		return nil, errors.New("cipher config is nil")
+	}
+	if err = config.validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// validate 校验配置
+func (c *CipherConfig) validate() error {
+	if c.Provider == nil {
+		return errors.New("key provider is nil")
+	}
+	if _, err := getCipherSuite(c.Cipher); err != nil {
+		return err
+	}
+	if !isEncodingSupported(c.encoding()) {
+		return fmt.Errorf("unsupported encoding: %s", c.Encoding)
+	}
+	return nil
+}
+
+// encoding 获取编码方式
+func (c *CipherConfig) encoding() string {
+	if c.Encoding == "" {
+		return EncodingBase64
+	}
+	return c.Encoding
+}
+
+// encrypt 加密
+func (c *CipherConfig) encrypt(plaintext []byte) ([]byte, error) {
+	key, err := deriveCipherKey(c.Provider, c.KeyID, c.Cipher)
+	if err != nil {
+		return nil, err
+	}
+	return Encrypt(c.Cipher, key, plaintext, nil)
+}
+
+// decrypt 解密
+func (c *CipherConfig) decrypt(ciphertext []byte) ([]byte, error) {
+	key, err := deriveCipherKey(c.Provider, c.KeyID, c.Cipher)
+	if err != nil {
+		return nil, err
+	}
+	return Decrypt(c.Cipher, key, ciphertext, nil)
+}
+
+// encryptToString 加密并编码
+func (c *CipherConfig) encryptToString(plaintext []byte) (string, error) {
+	ciphertext, err := c.encrypt(plaintext)
+	if err != nil {
+		return "", err
+	}
+	return Encode(c.encoding(), ciphertext)
+}
+
+// decryptString 解码并解密
+func (c *CipherConfig) decryptString(ciphertext string) ([]byte, error) {
+	raw, err := Decode(c.encoding(), ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return c.decrypt(raw)
+}
+
+// deriveCipherKey 从KeyProvider获取主密钥，并派生指定算法使用的密钥
+func deriveCipherKey(provider KeyProvider, keyID, cipherName string) ([]byte, error) {
+	suite, err := getCipherSuite(cipherName)
+	if err != nil {
+		return nil, err
+	}
+	masterKey, err := provider.Key(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("get key failed: %w", err)
+	}
+	return DeriveKey(suite.hash, masterKey, structCryptInfoPrefix+strings.ToLower(cipherName), suite.keySize)
+}
+
+// getCipherSuite 根据名称获取加密算法
+func getCipherSuite(cipherName string) (cipherSuite, error) {
+	suite, ok := cipherMap[strings.ToLower(cipherName)]
+	if !ok {
+		return cipherSuite{}, fmt.Errorf("unsupported cipher: %s", cipherName)
+	}
+	return suite, nil
+}
+
+// sivAdditionalData 将单个附加数据转换为siv的附加数据列表，为空时视为没有附加数据，与aead的语义保持一致
+func sivAdditionalData(additionalData []byte) [][]byte {
+	if len(additionalData) == 0 {
+		return nil
+	}
+	return [][]byte{additionalData}
+}
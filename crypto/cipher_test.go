@@ -0,0 +1,57 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_EncryptDecrypt(t *testing.T) {
+	plaintext := []byte("Hello World")
+	additionalData := []byte("user-id:1")
+	for name, suite := range cipherMap {
+		t.Run(name, func(t *testing.T) {
+			key := bytes.Repeat([]byte{1}, suite.keySize)
+			ciphertext, err := Encrypt(name, key, plaintext, additionalData)
+			if err != nil {
+				t.Fatalf("Encrypt() error = %v", err)
+			}
+			again, err := Encrypt(name, key, plaintext, additionalData)
+			if err != nil {
+				t.Fatalf("Encrypt() error = %v", err)
+			}
+			if deterministic := bytes.Equal(ciphertext, again); deterministic != (suite.nonceSize == 0) {
+				t.Errorf("Encrypt() deterministic = %v, want %v", deterministic, suite.nonceSize == 0)
+			}
+			got, err := Decrypt(name, key, ciphertext, additionalData)
+			if err != nil {
+				t.Fatalf("Decrypt() error = %v", err)
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Errorf("Decrypt() got = %s, want %s", got, plaintext)
+			}
+			if _, err = Decrypt(name, key, ciphertext, nil); err == nil {
+				t.Errorf("Decrypt() with wrong additional data error = nil, wantErr true")
+			}
+			ciphertext[len(ciphertext)-1] ^= 1
+			if _, err = Decrypt(name, key, ciphertext, additionalData); err == nil {
+				t.Errorf("Decrypt() with tampered ciphertext error = nil, wantErr true")
+			}
+		})
+	}
+}
+
+func Test_EncryptInvalidInput(t *testing.T) {
+	key := make([]byte, 16)
+	if _, err := Encrypt("des-ecb", key, nil, nil); err == nil {
+		t.Errorf("Encrypt() with unsupported cipher error = nil, wantErr true")
+	}
+	if _, err := Encrypt(CipherSm4Gcm, key[:8], nil, nil); err == nil {
+		t.Errorf("Encrypt() with invalid key error = nil, wantErr true")
+	}
+	if _, err := Decrypt(CipherSm4Gcm, key, make([]byte, 8), nil); err == nil {
+		t.Errorf("Decrypt() with short ciphertext error = nil, wantErr true")
+	}
+	if _, err := Encrypt("SM4-GCM", key, nil, nil); err != nil {
+		t.Errorf("Encrypt() with upper case name error = %v", err)
+	}
+}
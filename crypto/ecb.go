@@ -6,13 +6,17 @@ import (
 	"errors"
 )
 
-// EcbEncrypt ecb模式加密
+// EcbEncrypt ecb模式加密，分组密码实现MultiBlock时使用批量接口
 func EcbEncrypt(block cipher.Block, plaintext []byte) ([]byte, error) {
 	if len(plaintext)%block.BlockSize() != 0 {
 		return nil, errors.New("plaintext not full blocks")
 	}
 	blockSize := block.BlockSize()
 	ciphertext := make([]byte, len(plaintext))
+	if multi, ok := block.(MultiBlock); ok {
+		multi.EncryptBlocks(ciphertext, plaintext)
+		return ciphertext, nil
+	}
 	for start := 0; start < len(plaintext); start += blockSize {
 		end := start + blockSize
 		block.Encrypt(ciphertext[start:], plaintext[start:end])
@@ -27,6 +31,10 @@ func EcbDecrypt(block cipher.Block, ciphertext []byte) ([]byte, error) {
 	}
 	blockSize := block.BlockSize()
 	plaintext := make([]byte, len(ciphertext))
+	if multi, ok := block.(MultiBlock); ok {
+		multi.DecryptBlocks(plaintext, ciphertext)
+		return plaintext, nil
+	}
 	for start := 0; start < len(plaintext); start += blockSize {
 		end := start + blockSize
 		block.Decrypt(plaintext[start:], ciphertext[start:end])
@@ -0,0 +1,136 @@
+// Package crypto x25519/ecdh密钥协商与ecies加密工具包
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+/*
+ecies密文格式：临时公钥 || aes-256-gcm密文（包含16字节认证标签）
+其中对称密钥通过HKDF-SHA256派生：
+ikm  = ecdh(临时私钥, 接收方公钥)
+salt = 临时公钥 || 接收方公钥
+info = "tutils-ecies"
+由于每次加密都会生成新的临时密钥，派生出的对称密钥只使用一次，因此gcm使用全0的nonce。
+*/
+
+// eciesInfo ecies密钥派生使用的info
+const eciesInfo = "tutils-ecies"
+
+// CreateX25519PrivateKeyWithBase64 通过base64编码的32字节私钥构造x25519私钥
+func CreateX25519PrivateKeyWithBase64(privateKey string) (*ecdh.PrivateKey, error) {
+	k, err := base64.StdEncoding.DecodeString(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode with base64 failed: %w", err)
+	}
+	key, err := ecdh.X25519().NewPrivateKey(k)
+	if err != nil {
+		return nil, fmt.Errorf("create private key failed: %w", err)
+	}
+	return key, nil
+}
+
+// CreateX25519PublicKeyWithBase64 通过base64编码的32字节公钥构造x25519公钥
+func CreateX25519PublicKeyWithBase64(publicKey string) (*ecdh.PublicKey, error) {
+	k, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode with base64 failed: %w", err)
+	}
+	key, err := ecdh.X25519().NewPublicKey(k)
+	if err != nil {
+		return nil, fmt.Errorf("create public key failed: %w", err)
+	}
+	return key, nil
+}
+
+// EcdhPrivateKeyToBase64 将ecdh私钥导出为base64编码字符串
+func EcdhPrivateKeyToBase64(privateKey *ecdh.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(privateKey.Bytes())
+}
+
+// EcdhPublicKeyToBase64 将ecdh公钥导出为base64编码字符串
+func EcdhPublicKeyToBase64(publicKey *ecdh.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(publicKey.Bytes())
+}
+
+// EcdhSharedSecret 计算ecdh共享密钥，结果不应直接作为对称密钥使用，需要经过kdf派生
+// @param privateKey 己方私钥
+// @param publicKey 对方公钥，需要与私钥位于同一曲线
+func EcdhSharedSecret(privateKey *ecdh.PrivateKey, publicKey *ecdh.PublicKey) ([]byte, error) {
+	secret, err := privateKey.ECDH(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("ecdh failed: %w", err)
+	}
+	return secret, nil
+}
+
+// EciesEncrypt ecies加密，支持x25519与nist曲线的ecdh公钥
+// @param publicKey 接收方公钥
+// @param plaintext 明文内容
+func EciesEncrypt(publicKey *ecdh.PublicKey, plaintext []byte) ([]byte, error) {
+	ephemeral, err := publicKey.Curve().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate ephemeral key failed: %w", err)
+	}
+	ephemeralPublic := ephemeral.PublicKey().Bytes()
+	aead, err := eciesAead(ephemeral, publicKey, ephemeralPublic, publicKey.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	return aead.Seal(ephemeralPublic, nonce, plaintext, nil), nil
+}
+
+// EciesDecrypt ecies解密
+// @param privateKey 接收方私钥
+// @param ciphertext 密文
+func EciesDecrypt(privateKey *ecdh.PrivateKey, ciphertext []byte) ([]byte, error) {
+	size := len(privateKey.PublicKey().Bytes())
+	if len(ciphertext) < size {
+		return nil, errors.New("ciphertext too short")
+	}
+	ephemeralPublic, err := privateKey.Curve().NewPublicKey(ciphertext[:size])
+	if err != nil {
+		return nil, fmt.Errorf("parse ephemeral public key failed: %w", err)
+	}
+	aead, err := eciesAead(privateKey, ephemeralPublic, ciphertext[:size], privateKey.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	plaintext, err := aead.Open(nil, nonce, ciphertext[size:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("open failed: %w", err)
+	}
+	return plaintext, nil
+}
+
+// eciesAead 通过ecdh与hkdf派生aes-256-gcm
+func eciesAead(privateKey *ecdh.PrivateKey, publicKey *ecdh.PublicKey, ephemeralPublic, recipientPublic []byte) (cipher.AEAD, error) {
+	secret, err := EcdhSharedSecret(privateKey, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	salt := append(append([]byte{}, ephemeralPublic...), recipientPublic...)
+	key, err := hkdf.Key(sha256.New, secret, salt, eciesInfo, 32)
+	if err != nil {
+		return nil, fmt.Errorf("derive key failed: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create block failed: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm failed: %w", err)
+	}
+	return aead, nil
+}
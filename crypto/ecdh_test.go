@@ -0,0 +1,81 @@
+package crypto
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func Test_X25519SharedSecret(t *testing.T) {
+	// RFC 7748 第6.1节测试向量
+	alicePrivate, _ := hex.DecodeString("77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a")
+	bobPublic, _ := hex.DecodeString("de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f")
+	want, _ := hex.DecodeString("4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742")
+
+	privateKey, err := CreateX25519PrivateKeyWithBase64(base64.StdEncoding.EncodeToString(alicePrivate))
+	if err != nil {
+		t.Fatalf("CreateX25519PrivateKeyWithBase64() error = %v", err)
+	}
+	publicKey, err := CreateX25519PublicKeyWithBase64(base64.StdEncoding.EncodeToString(bobPublic))
+	if err != nil {
+		t.Fatalf("CreateX25519PublicKeyWithBase64() error = %v", err)
+	}
+	got, err := EcdhSharedSecret(privateKey, publicKey)
+	if err != nil {
+		t.Fatalf("EcdhSharedSecret() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EcdhSharedSecret() got = %x, want %x", got, want)
+	}
+	if got := EcdhPublicKeyToBase64(publicKey); got != base64.StdEncoding.EncodeToString(bobPublic) {
+		t.Errorf("EcdhPublicKeyToBase64() got = %v", got)
+	}
+	if got := EcdhPrivateKeyToBase64(privateKey); got != base64.StdEncoding.EncodeToString(alicePrivate) {
+		t.Errorf("EcdhPrivateKeyToBase64() got = %v", got)
+	}
+}
+
+func Test_EciesEncryptDecrypt(t *testing.T) {
+	plaintext := []byte("Hello World")
+	for _, curve := range []ecdh.Curve{ecdh.X25519(), ecdh.P256(), ecdh.P384()} {
+		t.Run(curveName(curve), func(t *testing.T) {
+			privateKey, err := curve.GenerateKey(rand.Reader)
+			if err != nil {
+				t.Fatalf("GenerateKey() error = %v", err)
+			}
+			ciphertext, err := EciesEncrypt(privateKey.PublicKey(), plaintext)
+			if err != nil {
+				t.Fatalf("EciesEncrypt() error = %v", err)
+			}
+			got, err := EciesDecrypt(privateKey, ciphertext)
+			if err != nil {
+				t.Fatalf("EciesDecrypt() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, plaintext) {
+				t.Errorf("EciesDecrypt() got = %v, want %v", got, plaintext)
+			}
+			ciphertext[len(ciphertext)-1] ^= 1
+			if _, err := EciesDecrypt(privateKey, ciphertext); err == nil {
+				t.Errorf("EciesDecrypt() with tampered ciphertext error = nil, wantErr true")
+			}
+			if _, err := EciesDecrypt(privateKey, ciphertext[:4]); err == nil {
+				t.Errorf("EciesDecrypt() with short ciphertext error = nil, wantErr true")
+			}
+		})
+	}
+}
+
+// curveName 获取曲线名称，用于子测试命名
+func curveName(curve ecdh.Curve) string {
+	switch curve {
+	case ecdh.X25519():
+		return "X25519"
+	case ecdh.P256():
+		return "P-256"
+	default:
+		return "P-384"
+	}
+}
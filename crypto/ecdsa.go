@@ -0,0 +1,159 @@
+// Package crypto ecdsa非对称签名工具包
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+/*
+ecdsa公私钥的导入导出格式与sm2保持一致：
+私钥为定长的标量D（P-256为32字节，P-384为48字节），
+公钥为定长的X||Y（P-256为64字节，P-384为96字节），不包含0x04前缀。
+签名使用asn.1编码，摘要算法根据曲线选择：P-256使用SHA-256，P-384使用SHA-384。
+*/
+
+// CreateEcdsaPrivateKeyWithBase64 通过base64编码字符串构造ecdsa私钥
+// @param curve 曲线，支持elliptic.P256()与elliptic.P384()
+// @param privateKey base64编码的私钥
+func CreateEcdsaPrivateKeyWithBase64(curve elliptic.Curve, privateKey string) (*ecdsa.PrivateKey, error) {
+	ecdhCurve, err := ecdsaToEcdhCurve(curve)
+	if err != nil {
+		return nil, err
+	}
+	k, err := base64.StdEncoding.DecodeString(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode with base64 failed: %w", err)
+	}
+	key, err := ecdhCurve.NewPrivateKey(k)
+	if err != nil {
+		return nil, fmt.Errorf("create private key failed: %w", err)
+	}
+	publicKey, err := ecdsaPublicKeyFromBytes(curve, key.PublicKey().Bytes()[1:])
+	if err != nil {
+		return nil, err
+	}
+	return &ecdsa.PrivateKey{PublicKey: *publicKey, D: new(big.Int).SetBytes(k)}, nil
+}
+
+// CreateEcdsaPublicKeyWithBase64 通过base64编码字符串构造ecdsa公钥
+// @param curve 曲线，支持elliptic.P256()与elliptic.P384()
+// @param publicKey base64编码的公钥（X||Y）
+func CreateEcdsaPublicKeyWithBase64(curve elliptic.Curve, publicKey string) (*ecdsa.PublicKey, error) {
+	ecdhCurve, err := ecdsaToEcdhCurve(curve)
+	if err != nil {
+		return nil, err
+	}
+	k, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode with base64 failed: %w", err)
+	}
+	// 借助ecdh校验公钥是否在曲线上
+	if _, err := ecdhCurve.NewPublicKey(append([]byte{4}, k...)); err != nil {
+		return nil, fmt.Errorf("create public key failed: %w", err)
+	}
+	return ecdsaPublicKeyFromBytes(curve, k)
+}
+
+// EcdsaPrivateKeyToBase64 将ecdsa私钥导出为base64编码字符串
+func EcdsaPrivateKeyToBase64(privateKey *ecdsa.PrivateKey) (string, error) {
+	key, err := privateKey.ECDH()
+	if err != nil {
+		return "", fmt.Errorf("convert private key failed: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key.Bytes()), nil
+}
+
+// EcdsaPublicKeyToBase64 将ecdsa公钥导出为base64编码字符串（X||Y）
+func EcdsaPublicKeyToBase64(publicKey *ecdsa.PublicKey) (string, error) {
+	key, err := publicKey.ECDH()
+	if err != nil {
+		return "", fmt.Errorf("convert public key failed: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key.Bytes()[1:]), nil
+}
+
+// EcdsaSign ecdsa签名，签名结果为asn.1编码
+// @param privateKey 私钥
+// @param data 待签名内容，函数内部会根据曲线计算摘要
+func EcdsaSign(privateKey *ecdsa.PrivateKey, data []byte) ([]byte, error) {
+	digest, err := ecdsaDigest(privateKey.Curve, data)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := ecdsa.SignASN1(rand.Reader, privateKey, digest)
+	if err != nil {
+		return nil, fmt.Errorf("sign failed: %w", err)
+	}
+	return signature, nil
+}
+
+// EcdsaVerify ecdsa验签，签名不匹配时返回错误
+// @param publicKey 公钥
+// @param data 签名原文
+// @param signature asn.1编码的签名
+func EcdsaVerify(publicKey *ecdsa.PublicKey, data, signature []byte) error {
+	digest, err := ecdsaDigest(publicKey.Curve, data)
+	if err != nil {
+		return err
+	}
+	if !ecdsa.VerifyASN1(publicKey, digest, signature) {
+		return errors.New("verify failed")
+	}
+	return nil
+}
+
+// ecdsaToEcdhCurve 获取ecdsa曲线对应的ecdh曲线
+func ecdsaToEcdhCurve(curve elliptic.Curve) (ecdh.Curve, error) {
+	switch curve {
+	case elliptic.P256():
+		return ecdh.P256(), nil
+	case elliptic.P384():
+		return ecdh.P384(), nil
+	default:
+		return nil, errors.New("unsupported curve")
+	}
+}
+
+// ecdsaHash 获取曲线对应的摘要算法
+func ecdsaHash(curve elliptic.Curve) (crypto.Hash, error) {
+	switch curve {
+	case elliptic.P256():
+		return crypto.SHA256, nil
+	case elliptic.P384():
+		return crypto.SHA384, nil
+	default:
+		return 0, errors.New("unsupported curve")
+	}
+}
+
+// ecdsaDigest 根据曲线计算摘要
+func ecdsaDigest(curve elliptic.Curve, data []byte) ([]byte, error) {
+	hash, err := ecdsaHash(curve)
+	if err != nil {
+		return nil, err
+	}
+	h := hash.New()
+	h.Write(data)
+	return h.Sum(nil), nil
+}
+
+// ecdsaPublicKeyFromBytes 通过X||Y构造ecdsa公钥
+func ecdsaPublicKeyFromBytes(curve elliptic.Curve, k []byte) (*ecdsa.PublicKey, error) {
+	size := (curve.Params().BitSize + 7) / 8
+	if len(k) != 2*size {
+		return nil, fmt.Errorf("public key bytes length must be %d", 2*size)
+	}
+	return &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(k[:size]),
+		Y:     new(big.Int).SetBytes(k[size:]),
+	}, nil
+}
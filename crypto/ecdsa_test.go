@@ -0,0 +1,83 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+)
+
+func Test_EcdsaKeyBase64(t *testing.T) {
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384()} {
+		t.Run(curve.Params().Name, func(t *testing.T) {
+			key, err := ecdsa.GenerateKey(curve, rand.Reader)
+			if err != nil {
+				t.Fatalf("GenerateKey() error = %v", err)
+			}
+			privateKey, err := EcdsaPrivateKeyToBase64(key)
+			if err != nil {
+				t.Fatalf("EcdsaPrivateKeyToBase64() error = %v", err)
+			}
+			gotPrivateKey, err := CreateEcdsaPrivateKeyWithBase64(curve, privateKey)
+			if err != nil {
+				t.Fatalf("CreateEcdsaPrivateKeyWithBase64() error = %v", err)
+			}
+			if !key.Equal(gotPrivateKey) {
+				t.Errorf("CreateEcdsaPrivateKeyWithBase64() got different key")
+			}
+			publicKey, err := EcdsaPublicKeyToBase64(&key.PublicKey)
+			if err != nil {
+				t.Fatalf("EcdsaPublicKeyToBase64() error = %v", err)
+			}
+			gotPublicKey, err := CreateEcdsaPublicKeyWithBase64(curve, publicKey)
+			if err != nil {
+				t.Fatalf("CreateEcdsaPublicKeyWithBase64() error = %v", err)
+			}
+			if !key.PublicKey.Equal(gotPublicKey) {
+				t.Errorf("CreateEcdsaPublicKeyWithBase64() got different key")
+			}
+		})
+	}
+}
+
+func Test_CreateEcdsaPublicKeyWithBase64Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		curve     elliptic.Curve
+		publicKey string
+	}{
+		{name: "base64", curve: elliptic.P256(), publicKey: "!!"},
+		{name: "length", curve: elliptic.P256(), publicKey: "AAAA"},
+		{name: "curve", curve: elliptic.P224(), publicKey: "AAAA"},
+		{name: "not-on-curve", curve: elliptic.P256(), publicKey: "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAEAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CreateEcdsaPublicKeyWithBase64(tt.curve, tt.publicKey); err == nil {
+				t.Errorf("CreateEcdsaPublicKeyWithBase64() error = nil, wantErr true")
+			}
+		})
+	}
+}
+
+func Test_EcdsaSignVerify(t *testing.T) {
+	data := []byte("Hello World")
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384()} {
+		t.Run(curve.Params().Name, func(t *testing.T) {
+			key, err := ecdsa.GenerateKey(curve, rand.Reader)
+			if err != nil {
+				t.Fatalf("GenerateKey() error = %v", err)
+			}
+			signature, err := EcdsaSign(key, data)
+			if err != nil {
+				t.Fatalf("EcdsaSign() error = %v", err)
+			}
+			if err := EcdsaVerify(&key.PublicKey, data, signature); err != nil {
+				t.Errorf("EcdsaVerify() error = %v", err)
+			}
+			if err := EcdsaVerify(&key.PublicKey, []byte("Hello World!"), signature); err == nil {
+				t.Errorf("EcdsaVerify() with tampered data error = nil, wantErr true")
+			}
+		})
+	}
+}
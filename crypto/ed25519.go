@@ -0,0 +1,67 @@
+// Package crypto ed25519非对称签名工具包
+package crypto
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// CreateEd25519PrivateKeyWithBase64 通过base64编码的32字节种子构造ed25519私钥
+func CreateEd25519PrivateKeyWithBase64(privateKey string) (ed25519.PrivateKey, error) {
+	k, err := base64.StdEncoding.DecodeString(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode with base64 failed: %w", err)
+	}
+	if len(k) != ed25519.SeedSize {
+		return nil, errors.New("private key bytes length must be 32")
+	}
+	return ed25519.NewKeyFromSeed(k), nil
+}
+
+// CreateEd25519PublicKeyWithBase64 通过base64编码字符串构造ed25519公钥
+func CreateEd25519PublicKeyWithBase64(publicKey string) (ed25519.PublicKey, error) {
+	k, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode with base64 failed: %w", err)
+	}
+	if len(k) != ed25519.PublicKeySize {
+		return nil, errors.New("public key bytes length must be 32")
+	}
+	return k, nil
+}
+
+// Ed25519PrivateKeyToBase64 将ed25519私钥的种子导出为base64编码字符串
+func Ed25519PrivateKeyToBase64(privateKey ed25519.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(privateKey.Seed())
+}
+
+// Ed25519PublicKeyToBase64 将ed25519公钥导出为base64编码字符串
+func Ed25519PublicKeyToBase64(publicKey ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(publicKey)
+}
+
+// Ed25519Sign ed25519签名
+// @param privateKey 私钥
+// @param data 待签名内容
+func Ed25519Sign(privateKey ed25519.PrivateKey, data []byte) ([]byte, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid private key length")
+	}
+	return ed25519.Sign(privateKey, data), nil
+}
+
+// Ed25519Verify ed25519验签，签名不匹配时返回错误
+// @param publicKey 公钥
+// @param data 签名原文
+// @param signature 签名
+func Ed25519Verify(publicKey ed25519.PublicKey, data, signature []byte) error {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return errors.New("invalid public key length")
+	}
+	if !ed25519.Verify(publicKey, data, signature) {
+		return errors.New("verify failed")
+	}
+	return nil
+}
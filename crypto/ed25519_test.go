@@ -0,0 +1,53 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func Test_Ed25519SignVerify(t *testing.T) {
+	// RFC 8032 第7.1节测试向量1
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	public, _ := hex.DecodeString("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")
+	want, _ := hex.DecodeString("e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e06522490155" +
+		"5fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b")
+
+	privateKey, err := CreateEd25519PrivateKeyWithBase64(base64.StdEncoding.EncodeToString(seed))
+	if err != nil {
+		t.Fatalf("CreateEd25519PrivateKeyWithBase64() error = %v", err)
+	}
+	publicKey, err := CreateEd25519PublicKeyWithBase64(base64.StdEncoding.EncodeToString(public))
+	if err != nil {
+		t.Fatalf("CreateEd25519PublicKeyWithBase64() error = %v", err)
+	}
+	if got := Ed25519PrivateKeyToBase64(privateKey); got != base64.StdEncoding.EncodeToString(seed) {
+		t.Errorf("Ed25519PrivateKeyToBase64() got = %v", got)
+	}
+	if got := Ed25519PublicKeyToBase64(publicKey); got != base64.StdEncoding.EncodeToString(public) {
+		t.Errorf("Ed25519PublicKeyToBase64() got = %v", got)
+	}
+	signature, err := Ed25519Sign(privateKey, []byte{})
+	if err != nil {
+		t.Fatalf("Ed25519Sign() error = %v", err)
+	}
+	if !reflect.DeepEqual(signature, want) {
+		t.Errorf("Ed25519Sign() got = %x, want %x", signature, want)
+	}
+	if err := Ed25519Verify(publicKey, []byte{}, signature); err != nil {
+		t.Errorf("Ed25519Verify() error = %v", err)
+	}
+	if err := Ed25519Verify(publicKey, []byte("Hello World"), signature); err == nil {
+		t.Errorf("Ed25519Verify() with tampered data error = nil, wantErr true")
+	}
+}
+
+func Test_CreateEd25519KeyWithBase64Invalid(t *testing.T) {
+	if _, err := CreateEd25519PrivateKeyWithBase64("AAAA"); err == nil {
+		t.Errorf("CreateEd25519PrivateKeyWithBase64() error = nil, wantErr true")
+	}
+	if _, err := CreateEd25519PublicKeyWithBase64("!!"); err == nil {
+		t.Errorf("CreateEd25519PublicKeyWithBase64() error = nil, wantErr true")
+	}
+}
@@ -0,0 +1,465 @@
+// Package crypto 密文文本编码工具包
+package crypto
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+/*
+加密结果为二进制数据，保存到文本字段或在接口中传输时需要编码，不同系统之间编码方式不一致（标准与url安全的base64、
+是否带填充等）是常见的互通问题，因此统一通过编码名称来指定：
+base64:       标准base64，带填充
+base64url:    url安全的base64，带填充
+base64raw:    标准base64，不带填充
+base64rawurl: url安全的base64，不带填充（jwt等场景使用）
+hex:          小写十六进制
+hexupper:     大写十六进制
+base32:       标准base32，带填充
+base58:       比特币字母表的base58，不包含易混淆的0、O、I、l
+解码十六进制时不区分大小写。
+EncryptToString等基于算法名称的函数以及AesCbcEncryptToString、Sm4GcmEncryptToString等与底层加密函数一一对应的函数
+均在加密后按指定编码方式编码，解密前先解码。
+其中XxxDecryptFromString返回原始的明文字节，明文为字符串时使用与EncryptString、DecryptString对应的
+XxxEncryptString与XxxDecryptString，输入输出均为字符串。
+*/
+
+// 编码方式枚举
+const (
+	// EncodingBase64 标准base64
+	EncodingBase64 = "base64"
+	// EncodingBase64Url url安全的base64
+	EncodingBase64Url = "base64url"
+	// EncodingBase64Raw 不带填充的标准base64
+	EncodingBase64Raw = "base64raw"
+	// EncodingBase64RawUrl 不带填充的url安全的base64
+	EncodingBase64RawUrl = "base64rawurl"
+	// EncodingHex 小写十六进制
+	EncodingHex = "hex"
+	// EncodingHexUpper 大写十六进制
+	EncodingHexUpper = "hexupper"
+	// EncodingBase32 标准base32
+	EncodingBase32 = "base32"
+	// EncodingBase58 base58
+	EncodingBase58 = "base58"
+)
+
+// encodingMap 编码方式映射
+var encodingMap = map[string]struct {
+	encode func(src []byte) string
+	decode func(s string) ([]byte, error)
+}{
+	EncodingBase64:       {base64.StdEncoding.EncodeToString, base64.StdEncoding.DecodeString},
+	EncodingBase64Url:    {base64.URLEncoding.EncodeToString, base64.URLEncoding.DecodeString},
+	EncodingBase64Raw:    {base64.RawStdEncoding.EncodeToString, base64.RawStdEncoding.DecodeString},
+	EncodingBase64RawUrl: {base64.RawURLEncoding.EncodeToString, base64.RawURLEncoding.DecodeString},
+	EncodingHex:          {hex.EncodeToString, hex.DecodeString},
+	EncodingHexUpper:     {func(src []byte) string { return strings.ToUpper(hex.EncodeToString(src)) }, hex.DecodeString},
+	EncodingBase32:       {base32.StdEncoding.EncodeToString, base32.StdEncoding.DecodeString},
+	EncodingBase58:       {base58Encode, base58Decode},
+}
+
+// Encode 使用指定编码方式编码
+// @param encoding 编码方式
+// @param src 原始数据
+func Encode(encoding string, src []byte) (string, error) {
+	codec, ok := encodingMap[strings.ToLower(encoding)]
+	if !ok {
+		return "", fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+	return codec.encode(src), nil
+}
+
+// Decode 使用指定编码方式解码
+// @param encoding 编码方式
+// @param s 编码后的字符串
+func Decode(encoding, s string) ([]byte, error) {
+	codec, ok := encodingMap[strings.ToLower(encoding)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+	dst, err := codec.decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s failed: %w", encoding, err)
+	}
+	return dst, nil
+}
+
+// isEncodingSupported 判断是否支持指定编码方式
+func isEncodingSupported(encoding string) bool {
+	_, ok := encodingMap[strings.ToLower(encoding)]
+	return ok
+}
+
+// EncryptToString 使用指定算法加密并编码，密文格式与Encrypt一致
+// @param cipherName 加密算法
+// @param key 密钥
+// @param plaintext 明文
+// @param additionalData 附加认证数据，可以为空
+// @param encoding 编码方式
+func EncryptToString(cipherName string, key, plaintext, additionalData []byte, encoding string) (string, error) {
+	if !isEncodingSupported(encoding) {
+		return "", fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+	ciphertext, err := Encrypt(cipherName, key, plaintext, additionalData)
+	if err != nil {
+		return "", err
+	}
+	return Encode(encoding, ciphertext)
+}
+
+// DecryptFromString 解码并使用指定算法解密
+// @param cipherName 加密算法
+// @param key 密钥
+// @param ciphertext 编码后的密文
+// @param additionalData 附加认证数据，需要与加密时一致
+// @param encoding 编码方式
+func DecryptFromString(cipherName string, key []byte, ciphertext string, additionalData []byte,
+	encoding string) ([]byte, error) {
+	raw, err := Decode(encoding, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return Decrypt(cipherName, key, raw, additionalData)
+}
+
+// EncryptString 使用指定算法加密字符串并编码
+// @param cipherName 加密算法
+// @param key 密钥
+// @param plaintext 明文
+// @param encoding 编码方式
+func EncryptString(cipherName string, key []byte, plaintext, encoding string) (string, error) {
+	return EncryptToString(cipherName, key, []byte(plaintext), nil, encoding)
+}
+
+// DecryptString 解码并使用指定算法解密为字符串
+// @param cipherName 加密算法
+// @param key 密钥
+// @param ciphertext 编码后的密文
+// @param encoding 编码方式
+func DecryptString(cipherName string, key []byte, ciphertext, encoding string) (string, error) {
+	plaintext, err := DecryptFromString(cipherName, key, ciphertext, nil, encoding)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// AesCbcEncryptToString cbc模式的aes加密并编码，密文与AesCbcEncrypt一致
+// @param key 密钥
+// @param iv 初始偏移向量
+// @param plaintext 明文
+// @param padding 填充方式
+// @param encoding 编码方式
+func AesCbcEncryptToString(key, iv, plaintext []byte, padding, encoding string) (string, error) {
+	if !isEncodingSupported(encoding) {
+		return "", fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+	ciphertext, err := AesCbcEncrypt(key, iv, plaintext, padding)
+	if err != nil {
+		return "", err
+	}
+	return Encode(encoding, ciphertext)
+}
+
+// AesCbcDecryptFromString 解码后进行cbc模式的aes解密，返回原始字节，字符串明文使用AesCbcDecryptString
+// @param key 密钥
+// @param iv 初始偏移向量
+// @param ciphertext 编码后的密文
+// @param padding 填充方式
+// @param encoding 编码方式
+func AesCbcDecryptFromString(key, iv []byte, ciphertext, padding, encoding string) ([]byte, error) {
+	raw, err := Decode(encoding, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return AesCbcDecrypt(key, iv, raw, padding)
+}
+
+// AesCbcEncryptString cbc模式的aes加密字符串并编码
+// @param key 密钥
+// @param iv 初始偏移向量
+// @param plaintext 明文
+// @param padding 填充方式
+// @param encoding 编码方式
+func AesCbcEncryptString(key, iv []byte, plaintext, padding, encoding string) (string, error) {
+	return AesCbcEncryptToString(key, iv, []byte(plaintext), padding, encoding)
+}
+
+// AesCbcDecryptString 解码后进行cbc模式的aes解密，返回字符串
+// @param key 密钥
+// @param iv 初始偏移向量
+// @param ciphertext 编码后的密文
+// @param padding 填充方式
+// @param encoding 编码方式
+func AesCbcDecryptString(key, iv []byte, ciphertext, padding, encoding string) (string, error) {
+	plaintext, err := AesCbcDecryptFromString(key, iv, ciphertext, padding, encoding)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Sm4CbcEncryptToString cbc模式的sm4加密并编码，密文与Sm4CbcEncrypt一致
+// @param key 密钥
+// @param iv 初始向量
+// @param plaintext 明文
+// @param padding 填充方式
+// @param encoding 编码方式
+func Sm4CbcEncryptToString(key, iv, plaintext []byte, padding, encoding string) (string, error) {
+	if !isEncodingSupported(encoding) {
+		return "", fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+	ciphertext, err := Sm4CbcEncrypt(key, iv, plaintext, padding)
+	if err != nil {
+		return "", err
+	}
+	return Encode(encoding, ciphertext)
+}
+
+// Sm4CbcDecryptFromString 解码后进行cbc模式的sm4解密，返回原始字节，字符串明文使用Sm4CbcDecryptString
+// @param key 密钥
+// @param iv 初始向量
+// @param ciphertext 编码后的密文
+// @param padding 填充方式
+// @param encoding 编码方式
+func Sm4CbcDecryptFromString(key, iv []byte, ciphertext, padding, encoding string) ([]byte, error) {
+	raw, err := Decode(encoding, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return Sm4CbcDecrypt(key, iv, raw, padding)
+}
+
+// Sm4CbcEncryptString cbc模式的sm4加密字符串并编码
+// @param key 密钥
+// @param iv 初始向量
+// @param plaintext 明文
+// @param padding 填充方式
+// @param encoding 编码方式
+func Sm4CbcEncryptString(key, iv []byte, plaintext, padding, encoding string) (string, error) {
+	return Sm4CbcEncryptToString(key, iv, []byte(plaintext), padding, encoding)
+}
+
+// Sm4CbcDecryptString 解码后进行cbc模式的sm4解密，返回字符串
+// @param key 密钥
+// @param iv 初始向量
+// @param ciphertext 编码后的密文
+// @param padding 填充方式
+// @param encoding 编码方式
+func Sm4CbcDecryptString(key, iv []byte, ciphertext, padding, encoding string) (string, error) {
+	plaintext, err := Sm4CbcDecryptFromString(key, iv, ciphertext, padding, encoding)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// TripleDesEcbEncryptToString ecb模式的3des加密并编码，密文与TripleDesEcbEncrypt一致
+// @param key 加密key
+// @param plaintext 明文
+// @param padding 填充模式
+// @param encoding 编码方式
+func TripleDesEcbEncryptToString(key, plaintext []byte, padding, encoding string) (string, error) {
+	if !isEncodingSupported(encoding) {
+		return "", fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+	ciphertext, err := TripleDesEcbEncrypt(key, plaintext, padding)
+	if err != nil {
+		return "", err
+	}
+	return Encode(encoding, ciphertext)
+}
+
+// TripleDesEcbDecryptFromString 解码后进行ecb模式的3des解密，返回原始字节，字符串明文使用TripleDesEcbDecryptString
+// @param key 加密key
+// @param ciphertext 编码后的密文
+// @param padding 填充模式
+// @param encoding 编码方式
+func TripleDesEcbDecryptFromString(key []byte, ciphertext, padding, encoding string) ([]byte, error) {
+	raw, err := Decode(encoding, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return TripleDesEcbDecrypt(key, raw, padding)
+}
+
+// TripleDesEcbEncryptString ecb模式的3des加密字符串并编码
+// @param key 加密key
+// @param plaintext 明文
+// @param padding 填充模式
+// @param encoding 编码方式
+func TripleDesEcbEncryptString(key []byte, plaintext, padding, encoding string) (string, error) {
+	return TripleDesEcbEncryptToString(key, []byte(plaintext), padding, encoding)
+}
+
+// TripleDesEcbDecryptString 解码后进行ecb模式的3des解密，返回字符串
+// @param key 加密key
+// @param ciphertext 编码后的密文
+// @param padding 填充模式
+// @param encoding 编码方式
+func TripleDesEcbDecryptString(key []byte, ciphertext, padding, encoding string) (string, error) {
+	plaintext, err := TripleDesEcbDecryptFromString(key, ciphertext, padding, encoding)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// AesGcmEncryptToString gcm模式的aes加密并编码，密文与AesGcmEncrypt一致
+// @param key 密钥
+// @param nonce 12字节随机数
+// @param plaintext 明文
+// @param additionalData 附加认证数据，可以为空
+// @param encoding 编码方式
+func AesGcmEncryptToString(key, nonce, plaintext, additionalData []byte, encoding string) (string, error) {
+	if !isEncodingSupported(encoding) {
+		return "", fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+	ciphertext, err := AesGcmEncrypt(key, nonce, plaintext, additionalData)
+	if err != nil {
+		return "", err
+	}
+	return Encode(encoding, ciphertext)
+}
+
+// AesGcmDecryptFromString 解码后进行gcm模式的aes解密，返回原始字节，字符串明文使用AesGcmDecryptString
+// @param key 密钥
+// @param nonce 12字节随机数
+// @param ciphertext 编码后的密文
+// @param additionalData 附加认证数据，需要与加密时一致
+// @param encoding 编码方式
+func AesGcmDecryptFromString(key, nonce []byte, ciphertext string, additionalData []byte,
+	encoding string) ([]byte, error) {
+	raw, err := Decode(encoding, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return AesGcmDecrypt(key, nonce, raw, additionalData)
+}
+
+// AesGcmEncryptString gcm模式的aes加密字符串并编码
+// @param key 密钥
+// @param nonce 12字节随机数
+// @param plaintext 明文
+// @param additionalData 附加认证数据，可以为空
+// @param encoding 编码方式
+func AesGcmEncryptString(key, nonce []byte, plaintext string, additionalData []byte, encoding string) (string, error) {
+	return AesGcmEncryptToString(key, nonce, []byte(plaintext), additionalData, encoding)
+}
+
+// AesGcmDecryptString 解码后进行gcm模式的aes解密，返回字符串
+// @param key 密钥
+// @param nonce 12字节随机数
+// @param ciphertext 编码后的密文
+// @param additionalData 附加认证数据，需要与加密时一致
+// @param encoding 编码方式
+func AesGcmDecryptString(key, nonce []byte, ciphertext string, additionalData []byte, encoding string) (string, error) {
+	plaintext, err := AesGcmDecryptFromString(key, nonce, ciphertext, additionalData, encoding)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Sm4GcmEncryptToString gcm模式的sm4加密并编码，密文与Sm4GcmEncrypt一致
+// @param key 密钥
+// @param nonce 12字节随机数
+// @param plaintext 明文
+// @param additionalData 附加认证数据，可以为空
+// @param encoding 编码方式
+func Sm4GcmEncryptToString(key, nonce, plaintext, additionalData []byte, encoding string) (string, error) {
+	if !isEncodingSupported(encoding) {
+		return "", fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+	ciphertext, err := Sm4GcmEncrypt(key, nonce, plaintext, additionalData)
+	if err != nil {
+		return "", err
+	}
+	return Encode(encoding, ciphertext)
+}
+
+// Sm4GcmDecryptFromString 解码后进行gcm模式的sm4解密，返回原始字节，字符串明文使用Sm4GcmDecryptString
+// @param key 密钥
+// @param nonce 12字节随机数
+// @param ciphertext 编码后的密文
+// @param additionalData 附加认证数据，需要与加密时一致
+// @param encoding 编码方式
+func Sm4GcmDecryptFromString(key, nonce []byte, ciphertext string, additionalData []byte,
+	encoding string) ([]byte, error) {
+	raw, err := Decode(encoding, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return Sm4GcmDecrypt(key, nonce, raw, additionalData)
+}
+
+// Sm4GcmEncryptString gcm模式的sm4加密字符串并编码
+// @param key 密钥
+// @param nonce 12字节随机数
+// @param plaintext 明文
+// @param additionalData 附加认证数据，可以为空
+// @param encoding 编码方式
+func Sm4GcmEncryptString(key, nonce []byte, plaintext string, additionalData []byte, encoding string) (string, error) {
+	return Sm4GcmEncryptToString(key, nonce, []byte(plaintext), additionalData, encoding)
+}
+
+// Sm4GcmDecryptString 解码后进行gcm模式的sm4解密，返回字符串
+// @param key 密钥
+// @param nonce 12字节随机数
+// @param ciphertext 编码后的密文
+// @param additionalData 附加认证数据，需要与加密时一致
+// @param encoding 编码方式
+func Sm4GcmDecryptString(key, nonce []byte, ciphertext string, additionalData []byte, encoding string) (string, error) {
+	plaintext, err := Sm4GcmDecryptFromString(key, nonce, ciphertext, additionalData, encoding)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// base58Alphabet 比特币使用的base58字母表
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Encode base58编码，每个前导0字节编码为一个'1'
+func base58Encode(src []byte) string {
+	zeros := 0
+	for zeros < len(src) && src[zeros] == 0 {
+		zeros++
+	}
+	num := new(big.Int).SetBytes(src[zeros:])
+	radix, mod := big.NewInt(58), new(big.Int)
+	dst := make([]byte, 0, len(src)*138/100+1)
+	for num.Sign() > 0 {
+		num.DivMod(num, radix, mod)
+		dst = append(dst, base58Alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		dst = append(dst, base58Alphabet[0])
+	}
+	slices.Reverse(dst)
+	return string(dst)
+}
+
+// base58Decode base58解码
+func base58Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	num, radix := new(big.Int), big.NewInt(58)
+	for i := zeros; i < len(s); i++ {
+		index := strings.IndexByte(base58Alphabet, s[i])
+		if index < 0 {
+			return nil, fmt.Errorf("illegal base58 data at input byte %d", i)
+		}
+		num.Mul(num, radix)
+		num.Add(num, big.NewInt(int64(index)))
+	}
+	return append(make([]byte, zeros), num.Bytes()...), nil
+}
@@ -0,0 +1,239 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_EncodeDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+		src      []byte
+		want     string
+	}{
+		{name: "base64", encoding: EncodingBase64, src: []byte{0xfb, 0xff, 0xbf}, want: "+/+/"},
+		{name: "base64-padding", encoding: EncodingBase64, src: []byte("ab"), want: "YWI="},
+		{name: "base64url", encoding: EncodingBase64Url, src: []byte{0xfb, 0xff, 0xbf, 0x61}, want: "-_-_YQ=="},
+		{name: "base64raw", encoding: EncodingBase64Raw, src: []byte("ab"), want: "YWI"},
+		{name: "base64rawurl", encoding: EncodingBase64RawUrl, src: []byte{0xfb, 0xff, 0xbf, 0x61}, want: "-_-_YQ"},
+		{name: "hex", encoding: EncodingHex, src: []byte{0xab, 0xcd}, want: "abcd"},
+		{name: "hexupper", encoding: EncodingHexUpper, src: []byte{0xab, 0xcd}, want: "ABCD"},
+		{name: "base32", encoding: EncodingBase32, src: []byte("foobar"), want: "MZXW6YTBOI======"},
+		{name: "base58", encoding: EncodingBase58, src: []byte("Hello World!"), want: "2NEpo7TZRRrLZSi2U"},
+		{name: "base58-zeros", encoding: EncodingBase58, src: []byte{0, 0, 0x28, 0x7f, 0xb4, 0xcd}, want: "11233QC4"},
+		{name: "base58-empty", encoding: EncodingBase58, src: []byte{}, want: ""},
+		{name: "upper-name", encoding: "HEX", src: []byte{0xab}, want: "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Encode(tt.encoding, tt.src)
+			if err != nil {
+				t.Errorf("Encode() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Encode() got = %v, want %v", got, tt.want)
+			}
+			src, err := Decode(tt.encoding, tt.want)
+			if err != nil {
+				t.Errorf("Decode() error = %v", err)
+				return
+			}
+			if !bytes.Equal(src, tt.src) {
+				t.Errorf("Decode() got = %x, want %x", src, tt.src)
+			}
+		})
+	}
+}
+
+func Test_DecodeInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+		s        string
+	}{
+		{name: "unsupported", encoding: "base85", s: ""},
+		{name: "base64", encoding: EncodingBase64, s: "YWI"},
+		{name: "base64url", encoding: EncodingBase64Url, s: "+/+/"},
+		{name: "hex", encoding: EncodingHex, s: "abc"},
+		{name: "base58", encoding: EncodingBase58, s: "0OIl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.encoding, tt.s); err == nil {
+				t.Errorf("Decode() error = nil, wantErr true")
+			}
+		})
+	}
+	if _, err := Encode("base85", nil); err == nil {
+		t.Errorf("Encode() with unsupported encoding error = nil, wantErr true")
+	}
+}
+
+func Test_EncryptString(t *testing.T) {
+	key := mustHex("0123456789abcdeffedcba9876543210")
+	for encoding := range encodingMap {
+		t.Run(encoding, func(t *testing.T) {
+			ciphertext, err := EncryptString(CipherSm4Gcm, key, "Hello World", encoding)
+			if err != nil {
+				t.Fatalf("EncryptString() error = %v", err)
+			}
+			got, err := DecryptString(CipherSm4Gcm, key, ciphertext, encoding)
+			if err != nil {
+				t.Fatalf("DecryptString() error = %v", err)
+			}
+			if got != "Hello World" {
+				t.Errorf("DecryptString() got = %v, want Hello World", got)
+			}
+		})
+	}
+	ciphertext, err := EncryptToString(CipherAesSiv, bytes.Repeat(key, 2), []byte("Hello World!"), []byte("ad"), EncodingBase64)
+	if err != nil {
+		t.Fatalf("EncryptToString() error = %v", err)
+	}
+	if _, err = DecryptFromString(CipherAesSiv, bytes.Repeat(key, 2), ciphertext, nil, EncodingBase64); err == nil {
+		t.Errorf("DecryptFromString() with wrong additional data error = nil, wantErr true")
+	}
+	if _, err = DecryptFromString(CipherAesSiv, bytes.Repeat(key, 2), ciphertext, []byte("ad"), EncodingBase64RawUrl); err == nil {
+		t.Errorf("DecryptFromString() with wrong encoding error = nil, wantErr true")
+	}
+	if _, err = EncryptString(CipherSm4Gcm, key, "Hello World", "base85"); err == nil {
+		t.Errorf("EncryptString() with unsupported encoding error = nil, wantErr true")
+	}
+}
+
+func Test_CipherHelperToString(t *testing.T) {
+	key := mustHex("0123456789abcdeffedcba9876543210")
+	iv := mustHex("000102030405060708090a0b0c0d0e0f")
+	nonce := iv[:12]
+	desKey := mustHex("0123456789abcdeffedcba98765432100123456789abcdef")
+	plaintext := []byte("Hello World")
+	ad := []byte("ad")
+	tests := []struct {
+		name    string
+		encrypt func(encoding string) (string, error)
+		decrypt func(ciphertext, encoding string) ([]byte, error)
+		raw     func() ([]byte, error)
+		// encryptString与decryptString为对应的字符串输入输出版本
+		encryptString func(encoding string) (string, error)
+		decryptString func(ciphertext, encoding string) (string, error)
+	}{
+		{
+			name: "aes-cbc",
+			encrypt: func(encoding string) (string, error) {
+				return AesCbcEncryptToString(key, iv, plaintext, PaddingPkcs7, encoding)
+			},
+			decrypt: func(ciphertext, encoding string) ([]byte, error) {
+				return AesCbcDecryptFromString(key, iv, ciphertext, PaddingPkcs7, encoding)
+			},
+			raw: func() ([]byte, error) { return AesCbcEncrypt(key, iv, plaintext, PaddingPkcs7) },
+			encryptString: func(encoding string) (string, error) {
+				return AesCbcEncryptString(key, iv, string(plaintext), PaddingPkcs7, encoding)
+			},
+			decryptString: func(ciphertext, encoding string) (string, error) {
+				return AesCbcDecryptString(key, iv, ciphertext, PaddingPkcs7, encoding)
+			},
+		},
+		{
+			name: "sm4-cbc",
+			encrypt: func(encoding string) (string, error) {
+				return Sm4CbcEncryptToString(key, iv, plaintext, PaddingPkcs7, encoding)
+			},
+			decrypt: func(ciphertext, encoding string) ([]byte, error) {
+				return Sm4CbcDecryptFromString(key, iv, ciphertext, PaddingPkcs7, encoding)
+			},
+			raw: func() ([]byte, error) { return Sm4CbcEncrypt(key, iv, plaintext, PaddingPkcs7) },
+			encryptString: func(encoding string) (string, error) {
+				return Sm4CbcEncryptString(key, iv, string(plaintext), PaddingPkcs7, encoding)
+			},
+			decryptString: func(ciphertext, encoding string) (string, error) {
+				return Sm4CbcDecryptString(key, iv, ciphertext, PaddingPkcs7, encoding)
+			},
+		},
+		{
+			name: "3des-ecb",
+			encrypt: func(encoding string) (string, error) {
+				return TripleDesEcbEncryptToString(desKey, plaintext, PaddingPkcs7, encoding)
+			},
+			decrypt: func(ciphertext, encoding string) ([]byte, error) {
+				return TripleDesEcbDecryptFromString(desKey, ciphertext, PaddingPkcs7, encoding)
+			},
+			raw: func() ([]byte, error) { return TripleDesEcbEncrypt(desKey, plaintext, PaddingPkcs7) },
+			encryptString: func(encoding string) (string, error) {
+				return TripleDesEcbEncryptString(desKey, string(plaintext), PaddingPkcs7, encoding)
+			},
+			decryptString: func(ciphertext, encoding string) (string, error) {
+				return TripleDesEcbDecryptString(desKey, ciphertext, PaddingPkcs7, encoding)
+			},
+		},
+		{
+			name: "aes-gcm",
+			encrypt: func(encoding string) (string, error) {
+				return AesGcmEncryptToString(key, nonce, plaintext, ad, encoding)
+			},
+			decrypt: func(ciphertext, encoding string) ([]byte, error) {
+				return AesGcmDecryptFromString(key, nonce, ciphertext, ad, encoding)
+			},
+			raw: func() ([]byte, error) { return AesGcmEncrypt(key, nonce, plaintext, ad) },
+			encryptString: func(encoding string) (string, error) {
+				return AesGcmEncryptString(key, nonce, string(plaintext), ad, encoding)
+			},
+			decryptString: func(ciphertext, encoding string) (string, error) {
+				return AesGcmDecryptString(key, nonce, ciphertext, ad, encoding)
+			},
+		},
+		{
+			name: "sm4-gcm",
+			encrypt: func(encoding string) (string, error) {
+				return Sm4GcmEncryptToString(key, nonce, plaintext, ad, encoding)
+			},
+			decrypt: func(ciphertext, encoding string) ([]byte, error) {
+				return Sm4GcmDecryptFromString(key, nonce, ciphertext, ad, encoding)
+			},
+			raw: func() ([]byte, error) { return Sm4GcmEncrypt(key, nonce, plaintext, ad) },
+			encryptString: func(encoding string) (string, error) {
+				return Sm4GcmEncryptString(key, nonce, string(plaintext), ad, encoding)
+			},
+			decryptString: func(ciphertext, encoding string) (string, error) {
+				return Sm4GcmDecryptString(key, nonce, ciphertext, ad, encoding)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := tt.raw()
+			if err != nil {
+				t.Fatalf("raw encrypt error = %v", err)
+			}
+			for encoding := range encodingMap {
+				ciphertext, err := tt.encrypt(encoding)
+				if err != nil {
+					t.Fatalf("%s EncryptToString() error = %v", encoding, err)
+				}
+				if want, _ := Encode(encoding, raw); ciphertext != want {
+					t.Errorf("%s EncryptToString() got = %v, want %v", encoding, ciphertext, want)
+				}
+				got, err := tt.decrypt(ciphertext, encoding)
+				if err != nil || !bytes.Equal(got, plaintext) {
+					t.Errorf("%s DecryptFromString() got = %s, error = %v, want %s", encoding, got, err, plaintext)
+				}
+				if got, err := tt.encryptString(encoding); err != nil || got != ciphertext {
+					t.Errorf("%s EncryptString() got = %v, error = %v, want %v", encoding, got, err, ciphertext)
+				}
+				if got, err := tt.decryptString(ciphertext, encoding); err != nil || got != string(plaintext) {
+					t.Errorf("%s DecryptString() got = %s, error = %v, want %s", encoding, got, err, plaintext)
+				}
+			}
+			if _, err = tt.encrypt("base85"); err == nil {
+				t.Errorf("EncryptToString() with unsupported encoding error = nil, wantErr true")
+			}
+			if _, err = tt.decrypt("not hex", EncodingHex); err == nil {
+				t.Errorf("DecryptFromString() with invalid encoding error = nil, wantErr true")
+			}
+			if _, err = tt.decryptString("not hex", EncodingHex); err == nil {
+				t.Errorf("DecryptString() with invalid encoding error = nil, wantErr true")
+			}
+		})
+	}
+}
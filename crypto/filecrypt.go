@@ -0,0 +1,418 @@
+// Package crypto 多接收方文件加密工具包
+package crypto
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+/*
+参考age设计的多接收方文件加密格式，用于备份文件等场景，一次加密即可由多个持有sm2或x25519私钥的接收方解密。
+随机生成16字节文件密钥，分别使用每个接收方的公钥加密后写入文件头，正文使用文件密钥派生的密钥分块加密。
+文件格式（整数均为大端）：
+magic              "tutils-file/v1\n"（15字节）
+算法名称长度(1) || 算法名称  正文加密算法，aes-gcm、sm4-gcm、chacha20-poly1305或xchacha20-poly1305
+接收方数量(2)
+接收方 * N         类型(1) || 长度(2) || 加密后的文件密钥
+  类型1 x25519：   EciesEncrypt(接收方公钥, 文件密钥)
+  类型2 sm2：      Sm2EncryptAsn1(接收方公钥, 文件密钥, C1C3C2)
+salt(16)
+文件头mac(32)      HMAC(HKDF(文件密钥, info="tutils-file/header"), 从magic到salt的全部内容)
+正文               分块密文 * M
+其中HKDF与HMAC的哈希算法与正文加密算法匹配（sm4-gcm为sm3，其余为sha256），文件头mac保证接收方列表等内容不可篡改。
+正文密钥为HKDF(文件密钥, salt, info="tutils-file/payload")，明文按64KiB分块，每块独立加密，附加认证数据为空，
+nonce为 块序号（大端，nonce长度-1字节） || 是否最后一块(1)，因此可以发现分块被删除、重排或截断。
+除非明文为空，最后一块不能为空；明文长度为64KiB的整数倍时最后一块为满块。
+解密时依次使用传入的私钥尝试解出文件密钥，全部失败时返回ErrFileNoIdentity；文件头或正文被篡改时返回ErrTampered，
+格式错误时返回ErrMalformed。解密结果在读取到对应分块并校验通过后才会返回，但整体完整性只有读取到EOF时才能确认。
+*/
+
+// ErrFileNoIdentity 没有可以解密文件密钥的私钥
+var ErrFileNoIdentity = errors.New("no identity matched any recipient")
+
+// 文件加密格式相关常量
+const (
+	fileMagic           = "tutils-file/v1\n"
+	fileKeySize         = 16
+	fileSaltSize        = 16
+	fileMacSize         = 32
+	fileChunkSize       = 64 * 1024
+	fileTagSize         = 16
+	fileHeaderInfo      = "tutils-file/header"
+	filePayloadInfo     = "tutils-file/payload"
+	fileRecipientX25519 = 1
+	fileRecipientSm2    = 2
+)
+
+// fileCiphers 支持的正文加密算法，需要是nonce不少于12字节的非确定性aead
+var fileCiphers = []string{CipherAesGcm, CipherSm4Gcm, CipherChaCha20Poly1305, CipherXChaCha20Poly1305}
+
+// FileEncrypt 加密src的全部内容并写入dst
+// @param dst 密文输出
+// @param src 明文输入
+// @param cipherName 正文加密算法
+// @param recipients 接收方公钥，*sm2.PublicKey或x25519的*ecdh.PublicKey
+func FileEncrypt(dst io.Writer, src io.Reader, cipherName string, recipients ...any) error {
+	w, err := NewFileEncrypter(dst, cipherName, recipients...)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(w, src); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// FileDecrypt 解密src的全部内容并写入dst
+// @param dst 明文输出
+// @param src 密文输入
+// @param identities 接收方私钥，*sm2.PrivateKey或x25519的*ecdh.PrivateKey
+func FileDecrypt(dst io.Writer, src io.Reader, identities ...any) error {
+	r, err := NewFileDecrypter(src, identities...)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, r)
+	return err
+}
+
+// NewFileEncrypter 写入文件头并返回加密写入器，写入的明文会被分块加密后写入dst，需要调用Close写入最后一块
+// @param dst 密文输出
+// @param cipherName 正文加密算法
+// @param recipients 接收方公钥，*sm2.PublicKey或x25519的*ecdh.PublicKey，也可以传入对应的私钥
+func NewFileEncrypter(dst io.Writer, cipherName string, recipients ...any) (io.WriteCloser, error) {
+	cipherName = strings.ToLower(cipherName)
+	suite, err := getFileCipherSuite(cipherName)
+	if err != nil {
+		return nil, err
+	}
+	if len(recipients) == 0 || len(recipients) > 0xffff {
+		return nil, errors.New("invalid number of recipients")
+	}
+	fileKey := make([]byte, fileKeySize)
+	salt := make([]byte, fileSaltSize)
+	if _, err = rand.Read(fileKey); err != nil {
+		return nil, fmt.Errorf("generate file key failed: %w", err)
+	}
+	if _, err = rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generate salt failed: %w", err)
+	}
+	header := bytes.NewBufferString(fileMagic)
+	header.WriteByte(byte(len(cipherName)))
+	header.WriteString(cipherName)
+	header.Write(binary.BigEndian.AppendUint16(nil, uint16(len(recipients))))
+	for i, recipient := range recipients {
+		recipientType, wrapped, err := fileWrapKey(recipient, fileKey)
+		if err != nil {
+			return nil, fmt.Errorf("wrap file key for recipient %d failed: %w", i, err)
+		}
+		header.WriteByte(recipientType)
+		header.Write(binary.BigEndian.AppendUint16(nil, uint16(len(wrapped))))
+		header.Write(wrapped)
+	}
+	header.Write(salt)
+	mac, err := fileHeaderMac(suite, fileKey, header.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	header.Write(mac)
+	payloadKey, err := filePayloadKey(suite, fileKey, salt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = dst.Write(header.Bytes()); err != nil {
+		return nil, fmt.Errorf("write header failed: %w", err)
+	}
+	return &fileEncryptWriter{dst: dst, suite: suite, key: payloadKey, buf: make([]byte, 0, fileChunkSize)}, nil
+}
+
+// NewFileDecrypter 读取并校验文件头，返回解密读取器
+// @param src 密文输入
+// @param identities 接收方私钥，*sm2.PrivateKey或x25519的*ecdh.PrivateKey
+func NewFileDecrypter(src io.Reader, identities ...any) (io.Reader, error) {
+	r := bufio.NewReaderSize(src, fileChunkSize+fileTagSize+1)
+	header := &bytes.Buffer{}
+	// read 读取n字节并记录到header，用于计算文件头mac
+	read := func(n int) ([]byte, error) {
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, fmt.Errorf("%w: read header failed: %w", ErrMalformed, err)
+		}
+		header.Write(b)
+		return b, nil
+	}
+	magic, err := read(len(fileMagic))
+	if err != nil {
+		return nil, err
+	}
+	if string(magic) != fileMagic {
+		return nil, fmt.Errorf("%w: invalid file magic", ErrMalformed)
+	}
+	nameLength, err := read(1)
+	if err != nil {
+		return nil, err
+	}
+	cipherName, err := read(int(nameLength[0]))
+	if err != nil {
+		return nil, err
+	}
+	suite, err := getFileCipherSuite(string(cipherName))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+	count, err := read(2)
+	if err != nil {
+		return nil, err
+	}
+	type stanza struct {
+		recipientType byte
+		wrapped       []byte
+	}
+	stanzas := make([]stanza, binary.BigEndian.Uint16(count))
+	for i := range stanzas {
+		prefix, err := read(3)
+		if err != nil {
+			return nil, err
+		}
+		wrapped, err := read(int(binary.BigEndian.Uint16(prefix[1:])))
+		if err != nil {
+			return nil, err
+		}
+		stanzas[i] = stanza{recipientType: prefix[0], wrapped: wrapped}
+	}
+	salt, err := read(fileSaltSize)
+	if err != nil {
+		return nil, err
+	}
+	mac := make([]byte, fileMacSize)
+	if _, err = io.ReadFull(r, mac); err != nil {
+		return nil, fmt.Errorf("%w: read header mac failed: %w", ErrMalformed, err)
+	}
+	var fileKey []byte
+	for _, s := range stanzas {
+		for _, identity := range identities {
+			key, err := fileUnwrapKey(identity, s.recipientType, s.wrapped)
+			if err != nil || len(key) != fileKeySize {
+				continue
+			}
+			fileKey = key
+			break
+		}
+		if fileKey != nil {
+			break
+		}
+	}
+	if fileKey == nil {
+		return nil, ErrFileNoIdentity
+	}
+	expected, err := fileHeaderMac(suite, fileKey, header.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(mac, expected) {
+		return nil, fmt.Errorf("%w: header mac mismatch", ErrTampered)
+	}
+	payloadKey, err := filePayloadKey(suite, fileKey, salt)
+	if err != nil {
+		return nil, err
+	}
+	return &fileDecryptReader{src: r, suite: suite, key: payloadKey,
+		chunk: make([]byte, fileChunkSize+fileTagSize)}, nil
+}
+
+// fileEncryptWriter 分块加密写入器
+type fileEncryptWriter struct {
+	dst     io.Writer   // 密文输出
+	suite   cipherSuite // 正文加密算法
+	key     []byte      // 正文密钥
+	counter uint64      // 块序号
+	buf     []byte      // 未加密的明文，最多一块
+	closed  bool        // 是否已经关闭
+}
+
+// Write 写入明文，满一块且还有后续数据时才加密写入，保证最后一块在Close时写入
+func (w *fileEncryptWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("write to closed file encrypter")
+	}
+	n := len(p)
+	for len(p) > 0 {
+		if len(w.buf) == fileChunkSize {
+			if err := w.flush(false); err != nil {
+				return n - len(p), err
+			}
+		}
+		size := min(fileChunkSize-len(w.buf), len(p))
+		w.buf = append(w.buf, p[:size]...)
+		p = p[size:]
+	}
+	return n, nil
+}
+
+// Close 加密写入最后一块，不会关闭dst
+func (w *fileEncryptWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.flush(true)
+}
+
+// flush 加密写入缓冲区中的一块
+func (w *fileEncryptWriter) flush(last bool) error {
+	ciphertext, err := w.suite.encrypt(w.key, fileChunkNonce(w.suite.nonceSize, w.counter, last), w.buf, nil)
+	if err != nil {
+		return fmt.Errorf("encrypt chunk %d failed: %w", w.counter, err)
+	}
+	if _, err = w.dst.Write(ciphertext); err != nil {
+		return fmt.Errorf("write chunk %d failed: %w", w.counter, err)
+	}
+	w.counter++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// fileDecryptReader 分块解密读取器
+type fileDecryptReader struct {
+	src     *bufio.Reader // 密文输入
+	suite   cipherSuite   // 正文加密算法
+	key     []byte        // 正文密钥
+	counter uint64        // 块序号
+	chunk   []byte        // 密文缓冲区
+	buf     []byte        // 已解密未读取的明文
+	done    bool          // 是否已经读取最后一块
+	err     error         // 解密错误，出错后不再继续读取
+}
+
+// Read 读取明文
+func (r *fileDecryptReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+		r.buf, r.err = r.readChunk()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// readChunk 读取并解密一块，通过预读1字节判断是否为最后一块
+func (r *fileDecryptReader) readChunk() ([]byte, error) {
+	n, err := io.ReadFull(r.src, r.chunk)
+	switch {
+	case err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF):
+		r.done = true
+	case err != nil:
+		return nil, fmt.Errorf("read chunk %d failed: %w", r.counter, err)
+	default:
+		if _, err = r.src.Peek(1); err == io.EOF {
+			r.done = true
+		} else if err != nil {
+			return nil, fmt.Errorf("read chunk %d failed: %w", r.counter, err)
+		}
+	}
+	if n < fileTagSize {
+		return nil, fmt.Errorf("%w: truncated chunk %d", ErrMalformed, r.counter)
+	}
+	plaintext, err := r.suite.decrypt(r.key, fileChunkNonce(r.suite.nonceSize, r.counter, r.done), r.chunk[:n], nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: decrypt chunk %d failed: %w", ErrTampered, r.counter, err)
+	}
+	if r.done && len(plaintext) == 0 && r.counter > 0 {
+		return nil, fmt.Errorf("%w: empty last chunk", ErrMalformed)
+	}
+	r.counter++
+	return plaintext, nil
+}
+
+// getFileCipherSuite 获取正文加密算法
+func getFileCipherSuite(cipherName string) (cipherSuite, error) {
+	for _, name := range fileCiphers {
+		if name == cipherName {
+			return cipherMap[name], nil
+		}
+	}
+	return cipherSuite{}, fmt.Errorf("unsupported file cipher: %s", cipherName)
+}
+
+// fileChunkNonce 分块的nonce：块序号 || 是否最后一块
+func fileChunkNonce(size int, counter uint64, last bool) []byte {
+	nonce := make([]byte, size)
+	binary.BigEndian.PutUint64(nonce[size-9:], counter)
+	if last {
+		nonce[size-1] = 1
+	}
+	return nonce
+}
+
+// fileHeaderMac 计算文件头mac
+func fileHeaderMac(suite cipherSuite, fileKey, header []byte) ([]byte, error) {
+	key, err := DeriveKey(suite.hash, fileKey, fileHeaderInfo, fileMacSize)
+	if err != nil {
+		return nil, err
+	}
+	return HmacSum(suite.hash, key, header)
+}
+
+// filePayloadKey 派生正文密钥
+func filePayloadKey(suite cipherSuite, fileKey, salt []byte) ([]byte, error) {
+	h, err := newHash(suite.hash)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hkdf.Key(h, fileKey, salt, filePayloadInfo, suite.keySize)
+	if err != nil {
+		return nil, fmt.Errorf("derive key failed: %w", err)
+	}
+	return key, nil
+}
+
+// fileWrapKey 使用接收方公钥加密文件密钥
+func fileWrapKey(recipient any, fileKey []byte) (byte, []byte, error) {
+	switch k := jwsPublicKey(recipient).(type) {
+	case *ecdh.PublicKey:
+		if k.Curve() != ecdh.X25519() {
+			return 0, nil, errors.New("only x25519 ecdh keys are supported")
+		}
+		wrapped, err := EciesEncrypt(k, fileKey)
+		return fileRecipientX25519, wrapped, err
+	case *sm2.PublicKey:
+		wrapped, err := Sm2EncryptAsn1(k, fileKey, sm2.C1C3C2)
+		return fileRecipientSm2, wrapped, err
+	default:
+		return 0, nil, fmt.Errorf("unsupported recipient type: %T", recipient)
+	}
+}
+
+// fileUnwrapKey 使用接收方私钥解密文件密钥，私钥类型与接收方类型不匹配时返回错误
+func fileUnwrapKey(identity any, recipientType byte, wrapped []byte) ([]byte, error) {
+	switch k := identity.(type) {
+	case *ecdh.PrivateKey:
+		if recipientType != fileRecipientX25519 || k.Curve() != ecdh.X25519() {
+			return nil, errors.New("recipient type mismatch")
+		}
+		return EciesDecrypt(k, wrapped)
+	case *sm2.PrivateKey:
+		if recipientType != fileRecipientSm2 {
+			return nil, errors.New("recipient type mismatch")
+		}
+		return Sm2DecryptAsn1(k, wrapped, sm2.C1C3C2)
+	default:
+		return nil, fmt.Errorf("unsupported identity type: %T", identity)
+	}
+}
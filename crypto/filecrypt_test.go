@@ -0,0 +1,95 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func Test_FileEncryptDecrypt(t *testing.T) {
+	x25519Key, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	sizes := []int{0, 1, fileChunkSize - 1, fileChunkSize, fileChunkSize + 1, 2*fileChunkSize + 5}
+	for _, cipherName := range fileCiphers {
+		for _, size := range sizes {
+			plaintext := make([]byte, size)
+			_, _ = rand.Read(plaintext)
+			ciphertext := &bytes.Buffer{}
+			// 逐字节写入，覆盖分块边界的处理
+			err := FileEncrypt(ciphertext, iotest.OneByteReader(bytes.NewReader(plaintext)), cipherName,
+				publicKey, x25519Key.PublicKey())
+			if err != nil {
+				t.Fatalf("FileEncrypt() %s %d error = %v", cipherName, size, err)
+			}
+			for _, identity := range []any{privateKey, x25519Key} {
+				got := &bytes.Buffer{}
+				if err = FileDecrypt(got, bytes.NewReader(ciphertext.Bytes()), identity); err != nil {
+					t.Fatalf("FileDecrypt() %s %d %T error = %v", cipherName, size, identity, err)
+				}
+				if !bytes.Equal(got.Bytes(), plaintext) {
+					t.Errorf("FileDecrypt() %s %d %T plaintext mismatch", cipherName, size, identity)
+				}
+			}
+		}
+	}
+}
+
+func Test_FileDecryptError(t *testing.T) {
+	x25519Key, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	otherKey, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	plaintext := make([]byte, 2*fileChunkSize+5)
+	ciphertext := &bytes.Buffer{}
+	if err := FileEncrypt(ciphertext, bytes.NewReader(plaintext), CipherSm4Gcm, x25519Key.PublicKey()); err != nil {
+		t.Fatalf("FileEncrypt() error = %v", err)
+	}
+	data := ciphertext.Bytes()
+	headerLength := len(data) - (2*(fileChunkSize+fileTagSize) + 5 + fileTagSize)
+	tamper := func(index int) []byte {
+		b := bytes.Clone(data)
+		b[index] ^= 1
+		return b
+	}
+	tests := []struct {
+		name     string
+		data     []byte
+		identity any
+		want     error
+	}{
+		{name: "identity", data: data, identity: otherKey, want: ErrFileNoIdentity},
+		{name: "identity-type", data: data, identity: privateKey, want: ErrFileNoIdentity},
+		{name: "magic", data: tamper(0), identity: x25519Key, want: ErrMalformed},
+		{name: "header-truncated", data: data[:headerLength-1], identity: x25519Key, want: ErrMalformed},
+		{name: "salt", data: tamper(headerLength - fileMacSize - 1), identity: x25519Key, want: ErrTampered},
+		{name: "mac", data: tamper(headerLength - 1), identity: x25519Key, want: ErrTampered},
+		{name: "payload", data: tamper(headerLength + 1), identity: x25519Key, want: ErrTampered},
+		{name: "last-chunk-removed", data: data[:len(data)-5-fileTagSize], identity: x25519Key, want: ErrTampered},
+		{name: "chunk-truncated", data: data[:len(data)-1], identity: x25519Key, want: ErrTampered},
+		{name: "chunk-too-short", data: data[:len(data)-5-fileTagSize+1], identity: x25519Key, want: ErrMalformed},
+		{name: "empty-payload", data: data[:headerLength], identity: x25519Key, want: ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := FileDecrypt(io.Discard, bytes.NewReader(tt.data), tt.identity); !errors.Is(err, tt.want) {
+				t.Errorf("FileDecrypt() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if _, err := NewFileEncrypter(io.Discard, CipherAesSiv, x25519Key.PublicKey()); err == nil {
+		t.Errorf("NewFileEncrypter() with unsupported cipher error = nil, wantErr true")
+	}
+	if _, err := NewFileEncrypter(io.Discard, CipherAesGcm); err == nil {
+		t.Errorf("NewFileEncrypter() without recipient error = nil, wantErr true")
+	}
+	p256Key, _ := ecdh.P256().GenerateKey(rand.Reader)
+	if _, err := NewFileEncrypter(io.Discard, CipherAesGcm, p256Key.PublicKey()); err == nil {
+		t.Errorf("NewFileEncrypter() with p256 recipient error = nil, wantErr true")
+	}
+	w, _ := NewFileEncrypter(io.Discard, CipherAesGcm, x25519Key)
+	_ = w.Close()
+	if _, err := w.Write([]byte{1}); err == nil {
+		t.Errorf("Write() after Close error = nil, wantErr true")
+	}
+}
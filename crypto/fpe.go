@@ -0,0 +1,320 @@
+// Package crypto 保留格式加密（FF1/FF3-1）工具包
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+)
+
+/*
+保留格式加密（format-preserving encryption，NIST SP 800-38G Rev.1）：
+密文与明文长度相同且字符均来自同一个字符集，因此银行卡号、身份证号等加密后仍然可以通过下游的长度与字符校验。
+FF1与FF3-1均需要调用方提供字符集（alphabet），字符集中的每个字符对应一个数字，字符集长度即基数（radix）。
+tweak类似于iv，用于区分不同的上下文（例如不同的字段），加解密需要使用相同的tweak：
+FF1的tweak长度不限（可以为空），FF3-1的tweak固定为7字节。
+明文长度需要满足 radix^len >= 1000000，例如纯数字至少6位。
+
+注意：保留格式加密不会保留校验位的有效性，例如身份证号的最后一位或银行卡号的Luhn校验位，
+如果下游需要校验，可以只加密校验位之前的部分并在加密后重新计算校验位。
+*/
+
+// 常用字符集
+const (
+	// FpeAlphabetDigits 数字
+	FpeAlphabetDigits = "0123456789"
+	// FpeAlphabetLowerAlphanumeric 数字与小写字母
+	FpeAlphabetLowerAlphanumeric = "0123456789abcdefghijklmnopqrstuvwxyz"
+	// FpeAlphabetAlphanumeric 数字与大小写字母
+	FpeAlphabetAlphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+// fpeMinDomain 明文空间的最小值，radix^len需要不小于该值
+const fpeMinDomain = 1000000
+
+// AesFf1Encrypt 使用aes的FF1加密
+// @param key 16、24或32字节密钥
+// @param tweak 调整值，可以为空
+// @param plaintext 明文，所有字符需要位于字符集中
+// @param alphabet 字符集
+func AesFf1Encrypt(key, tweak []byte, plaintext, alphabet string) (string, error) {
+	return fpeCrypt(aes.NewCipher, key, tweak, plaintext, alphabet, ff1Crypt, true)
+}
+
+// AesFf1Decrypt 使用aes的FF1解密
+// @param key 16、24或32字节密钥
+// @param tweak 调整值，需要与加密时一致
+// @param ciphertext 密文
+// @param alphabet 字符集
+func AesFf1Decrypt(key, tweak []byte, ciphertext, alphabet string) (string, error) {
+	return fpeCrypt(aes.NewCipher, key, tweak, ciphertext, alphabet, ff1Crypt, false)
+}
+
+// Sm4Ff1Encrypt 使用sm4的FF1加密
+// @param key 16字节密钥
+// @param tweak 调整值，可以为空
+// @param plaintext 明文，所有字符需要位于字符集中
+// @param alphabet 字符集
+func Sm4Ff1Encrypt(key, tweak []byte, plaintext, alphabet string) (string, error) {
+	return fpeCrypt(NewSm4Cipher, key, tweak, plaintext, alphabet, ff1Crypt, true)
+}
+
+// Sm4Ff1Decrypt 使用sm4的FF1解密
+// @param key 16字节密钥
+// @param tweak 调整值，需要与加密时一致
+// @param ciphertext 密文
+// @param alphabet 字符集
+func Sm4Ff1Decrypt(key, tweak []byte, ciphertext, alphabet string) (string, error) {
+	return fpeCrypt(NewSm4Cipher, key, tweak, ciphertext, alphabet, ff1Crypt, false)
+}
+
+// AesFf3Encrypt 使用aes的FF3-1加密
+// @param key 16、24或32字节密钥
+// @param tweak 7字节调整值
+// @param plaintext 明文，所有字符需要位于字符集中
+// @param alphabet 字符集
+func AesFf3Encrypt(key, tweak []byte, plaintext, alphabet string) (string, error) {
+	return fpeCrypt(aes.NewCipher, key, tweak, plaintext, alphabet, ff3Crypt, true)
+}
+
+// AesFf3Decrypt 使用aes的FF3-1解密
+// @param key 16、24或32字节密钥
+// @param tweak 7字节调整值，需要与加密时一致
+// @param ciphertext 密文
+// @param alphabet 字符集
+func AesFf3Decrypt(key, tweak []byte, ciphertext, alphabet string) (string, error) {
+	return fpeCrypt(aes.NewCipher, key, tweak, ciphertext, alphabet, ff3Crypt, false)
+}
+
+// Sm4Ff3Encrypt 使用sm4的FF3-1加密
+// @param key 16字节密钥
+// @param tweak 7字节调整值
+// @param plaintext 明文，所有字符需要位于字符集中
+// @param alphabet 字符集
+func Sm4Ff3Encrypt(key, tweak []byte, plaintext, alphabet string) (string, error) {
+	return fpeCrypt(NewSm4Cipher, key, tweak, plaintext, alphabet, ff3Crypt, true)
+}
+
+// Sm4Ff3Decrypt 使用sm4的FF3-1解密
+// @param key 16字节密钥
+// @param tweak 7字节调整值，需要与加密时一致
+// @param ciphertext 密文
+// @param alphabet 字符集
+func Sm4Ff3Decrypt(key, tweak []byte, ciphertext, alphabet string) (string, error) {
+	return fpeCrypt(NewSm4Cipher, key, tweak, ciphertext, alphabet, ff3Crypt, false)
+}
+
+// fpeCryptFunc FF1/FF3-1算法实现
+type fpeCryptFunc func(newBlock func(key []byte) (cipher.Block, error), key, tweak []byte, radix int,
+	x []int, encrypt bool) ([]int, error)
+
+// fpeCrypt 字符与数字的相互转换，并调用具体算法
+func fpeCrypt(newBlock func(key []byte) (cipher.Block, error), key, tweak []byte, input, alphabet string,
+	crypt fpeCryptFunc, encrypt bool) (string, error) {
+	symbols := []rune(alphabet)
+	radix := len(symbols)
+	if radix < 2 || radix > 1<<16 {
+		return "", errors.New("alphabet length must be in [2, 65536]")
+	}
+	index := make(map[rune]int, radix)
+	for i, r := range symbols {
+		if _, ok := index[r]; ok {
+			return "", fmt.Errorf("duplicate character in alphabet: %q", r)
+		}
+		index[r] = i
+	}
+	var x []int
+	for _, r := range input {
+		i, ok := index[r]
+		if !ok {
+			return "", fmt.Errorf("character %q not in alphabet", r)
+		}
+		x = append(x, i)
+	}
+	if float64(len(x))*math.Log2(float64(radix)) < math.Log2(fpeMinDomain) {
+		return "", errors.New("input too short for alphabet")
+	}
+	y, err := crypt(newBlock, key, tweak, radix, x, encrypt)
+	if err != nil {
+		return "", err
+	}
+	output := make([]rune, len(y))
+	for i, v := range y {
+		output[i] = symbols[v]
+	}
+	return string(output), nil
+}
+
+// ff1Crypt NIST SP 800-38G 算法7/8（FF1）
+func ff1Crypt(newBlock func(key []byte) (cipher.Block, error), key, tweak []byte, radix int,
+	x []int, encrypt bool) ([]int, error) {
+	block, err := newBlock(key)
+	if err != nil {
+		return nil, fmt.Errorf("create block failed: %w", err)
+	}
+	if block.BlockSize() != 16 {
+		return nil, errors.New("block size must be 16")
+	}
+	n, t := len(x), len(tweak)
+	u, v := n/2, n-n/2
+	a, b := slices.Clone(x[:u]), slices.Clone(x[u:])
+	bytesLen := int(math.Ceil(math.Ceil(float64(v)*math.Log2(float64(radix))) / 8))
+	d := 4*((bytesLen+3)/4) + 4
+
+	p := []byte{1, 2, 1, byte(radix >> 16), byte(radix >> 8), byte(radix), 10, byte(u),
+		byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n),
+		byte(t >> 24), byte(t >> 16), byte(t >> 8), byte(t)}
+	// Q = T || 0^((-t-b-1) mod 16) || [i] || [NUM(B)]^b
+	qLen := t + ((-t-bytesLen-1)%16+16)%16 + 1 + bytesLen
+	q := make([]byte, qLen)
+	copy(q, tweak)
+	bigRadix := big.NewInt(int64(radix))
+	modU := new(big.Int).Exp(bigRadix, big.NewInt(int64(u)), nil)
+	modV := new(big.Int).Exp(bigRadix, big.NewInt(int64(v)), nil)
+
+	for j := range 10 {
+		i := j
+		if !encrypt {
+			i = 9 - j
+		}
+		// 加密时对B计算轮函数，解密时对A计算
+		src, dst := b, a
+		if !encrypt {
+			src, dst = a, b
+		}
+		q[qLen-bytesLen-1] = byte(i)
+		numRadix(src, bigRadix).FillBytes(q[qLen-bytesLen:])
+		r := cbcMac(block, append(slices.Clone(p), q...))
+		s := make([]byte, 0, (d+15)/16*16)
+		s = append(s, r...)
+		for k := 1; len(s) < d; k++ {
+			blk := make([]byte, 16)
+			copy(blk[12:], []byte{byte(k >> 24), byte(k >> 16), byte(k >> 8), byte(k)})
+			for l := range blk {
+				blk[l] ^= r[l]
+			}
+			block.Encrypt(blk, blk)
+			s = append(s, blk...)
+		}
+		y := new(big.Int).SetBytes(s[:d])
+		m, mod := u, modU
+		if i%2 == 1 {
+			m, mod = v, modV
+		}
+		c := numRadix(dst, bigRadix)
+		if encrypt {
+			c.Add(c, y)
+		} else {
+			c.Sub(c, y)
+		}
+		c.Mod(c, mod)
+		if encrypt {
+			a, b = b, strRadix(c, bigRadix, m)
+		} else {
+			b, a = a, strRadix(c, bigRadix, m)
+		}
+	}
+	return append(a, b...), nil
+}
+
+// ff3Crypt NIST SP 800-38G Rev.1 算法9/10（FF3-1）
+func ff3Crypt(newBlock func(key []byte) (cipher.Block, error), key, tweak []byte, radix int,
+	x []int, encrypt bool) ([]int, error) {
+	if len(tweak) != 7 {
+		return nil, errors.New("tweak length must be 7")
+	}
+	// 将56位tweak扩展为64位：T_L = T[0..27] || 0^4，T_R = T[32..55] || T[28..31] || 0^4
+	return ff3CryptWithTweak64(newBlock, key, []byte{tweak[0], tweak[1], tweak[2], tweak[3] & 0xf0,
+		tweak[4], tweak[5], tweak[6], tweak[3] << 4}, radix, x, encrypt)
+}
+
+// ff3CryptWithTweak64 使用64位tweak的FF3算法
+func ff3CryptWithTweak64(newBlock func(key []byte) (cipher.Block, error), key, tweak []byte, radix int,
+	x []int, encrypt bool) ([]int, error) {
+	block, err := newBlock(reversed(key))
+	if err != nil {
+		return nil, fmt.Errorf("create block failed: %w", err)
+	}
+	if block.BlockSize() != 16 {
+		return nil, errors.New("block size must be 16")
+	}
+	n := len(x)
+	maxLen := 2 * int(math.Floor(96/math.Log2(float64(radix))))
+	if n > maxLen {
+		return nil, fmt.Errorf("input length must not exceed %d", maxLen)
+	}
+	u, v := (n+1)/2, n-(n+1)/2
+	a, b := slices.Clone(x[:u]), slices.Clone(x[u:])
+	bigRadix := big.NewInt(int64(radix))
+	modU := new(big.Int).Exp(bigRadix, big.NewInt(int64(u)), nil)
+	modV := new(big.Int).Exp(bigRadix, big.NewInt(int64(v)), nil)
+
+	for j := range 8 {
+		i := j
+		if !encrypt {
+			i = 7 - j
+		}
+		src, dst := b, a
+		if !encrypt {
+			src, dst = a, b
+		}
+		m, mod, w := u, modU, tweak[4:]
+		if i%2 == 1 {
+			m, mod, w = v, modV, tweak[:4]
+		}
+		// P = W xor [i]^4 || [NUM(REV(B))]^12
+		p := make([]byte, 16)
+		copy(p, w)
+		p[3] ^= byte(i)
+		numRadix(reversed(src), bigRadix).FillBytes(p[4:])
+		p = reversed(p)
+		block.Encrypt(p, p)
+		y := new(big.Int).SetBytes(reversed(p))
+		c := numRadix(reversed(dst), bigRadix)
+		if encrypt {
+			c.Add(c, y)
+		} else {
+			c.Sub(c, y)
+		}
+		c.Mod(c, mod)
+		if encrypt {
+			a, b = b, reversed(strRadix(c, bigRadix, m))
+		} else {
+			b, a = a, reversed(strRadix(c, bigRadix, m))
+		}
+	}
+	return append(a, b...), nil
+}
+
+// numRadix 将radix进制的数字串（高位在前）转换为整数
+func numRadix(x []int, radix *big.Int) *big.Int {
+	num := new(big.Int)
+	for _, v := range x {
+		num.Mul(num, radix)
+		num.Add(num, big.NewInt(int64(v)))
+	}
+	return num
+}
+
+// strRadix 将整数转换为m位radix进制的数字串（高位在前）
+func strRadix(num, radix *big.Int, m int) []int {
+	x := make([]int, m)
+	num = new(big.Int).Set(num)
+	r := new(big.Int)
+	for i := m - 1; i >= 0; i-- {
+		num.QuoRem(num, radix, r)
+		x[i] = int(r.Int64())
+	}
+	return x
+}
+
+// reversed 返回逆序后的副本
+func reversed[T any](s []T) []T {
+	r := slices.Clone(s)
+	slices.Reverse(r)
+	return r
+}
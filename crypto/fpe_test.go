@@ -0,0 +1,278 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+)
+
+func Test_AesFf1EncryptDecrypt(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		tweak      string
+		plaintext  string
+		alphabet   string
+		ciphertext string
+	}{
+		// NIST SP 800-38G FF1示例
+		{
+			name:       "sample1",
+			key:        "2b7e151628aed2a6abf7158809cf4f3c",
+			plaintext:  "0123456789",
+			alphabet:   FpeAlphabetDigits,
+			ciphertext: "2433477484",
+		},
+		{
+			name:       "sample2",
+			key:        "2b7e151628aed2a6abf7158809cf4f3c",
+			tweak:      "39383736353433323130",
+			plaintext:  "0123456789",
+			alphabet:   FpeAlphabetDigits,
+			ciphertext: "6124200773",
+		},
+		{
+			name:       "sample3",
+			key:        "2b7e151628aed2a6abf7158809cf4f3c",
+			tweak:      "3737373770717273373737",
+			plaintext:  "0123456789abcdefghi",
+			alphabet:   FpeAlphabetLowerAlphanumeric,
+			ciphertext: "a9tv40mll9kdu509eum",
+		},
+		{
+			name:       "sample4",
+			key:        "2b7e151628aed2a6abf7158809cf4f3cef4359d8d580aa4f",
+			plaintext:  "0123456789",
+			alphabet:   FpeAlphabetDigits,
+			ciphertext: "2830668132",
+		},
+		{
+			name:       "sample7",
+			key:        "2b7e151628aed2a6abf7158809cf4f3cef4359d8d580aa4f7f036d6f04fc6a94",
+			plaintext:  "0123456789",
+			alphabet:   FpeAlphabetDigits,
+			ciphertext: "6657667009",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, tweak := mustHex(tt.key), mustHex(tt.tweak)
+			got, err := AesFf1Encrypt(key, tweak, tt.plaintext, tt.alphabet)
+			if err != nil {
+				t.Errorf("AesFf1Encrypt() error = %v", err)
+				return
+			}
+			if got != tt.ciphertext {
+				t.Errorf("AesFf1Encrypt() got = %v, want %v", got, tt.ciphertext)
+			}
+			got, err = AesFf1Decrypt(key, tweak, tt.ciphertext, tt.alphabet)
+			if err != nil {
+				t.Errorf("AesFf1Decrypt() error = %v", err)
+				return
+			}
+			if got != tt.plaintext {
+				t.Errorf("AesFf1Decrypt() got = %v, want %v", got, tt.plaintext)
+			}
+		})
+	}
+}
+
+func Test_AesFf3EncryptDecrypt(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		tweak      string
+		plaintext  string
+		alphabet   string
+		ciphertext string
+	}{
+		// NIST ACVP FF3-1测试向量（56位tweak）
+		{
+			name:       "aes-128-radix10",
+			key:        "2de79d232df5585d68ce47882ae256d6",
+			tweak:      "cbd09280979564",
+			plaintext:  "3992520240",
+			alphabet:   FpeAlphabetDigits,
+			ciphertext: "8901801106",
+		},
+		{
+			name:       "aes-128-radix10-long",
+			key:        "01c63017111438f7fc8e24eb16c71ab5",
+			tweak:      "c4e822dcd09f27",
+			plaintext:  "60761757463116869318437658042297305934914824457484538562",
+			alphabet:   FpeAlphabetDigits,
+			ciphertext: "35637144092473838892796702739628394376915177448290847293",
+		},
+		{
+			name:       "aes-128-radix26",
+			key:        "718385e6542534604419e83ce387a437",
+			tweak:      "b6f35084fa90e1",
+			plaintext:  "wfmwlrorcd",
+			alphabet:   "abcdefghijklmnopqrstuvwxyz",
+			ciphertext: "ywowehycyd",
+		},
+		{
+			name:       "aes-192-radix10",
+			key:        "f62edb777a671075d47563f3a1e9ac797aa706a2d8e02fc8",
+			tweak:      "493b8451bf6716",
+			plaintext:  "4406616808",
+			alphabet:   FpeAlphabetDigits,
+			ciphertext: "1807744762",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, tweak := mustHex(tt.key), mustHex(tt.tweak)
+			got, err := AesFf3Encrypt(key, tweak, tt.plaintext, tt.alphabet)
+			if err != nil {
+				t.Errorf("AesFf3Encrypt() error = %v", err)
+				return
+			}
+			if got != tt.ciphertext {
+				t.Errorf("AesFf3Encrypt() got = %v, want %v", got, tt.ciphertext)
+			}
+			got, err = AesFf3Decrypt(key, tweak, tt.ciphertext, tt.alphabet)
+			if err != nil {
+				t.Errorf("AesFf3Decrypt() error = %v", err)
+				return
+			}
+			if got != tt.plaintext {
+				t.Errorf("AesFf3Decrypt() got = %v, want %v", got, tt.plaintext)
+			}
+		})
+	}
+}
+
+func Test_Ff3CryptWithTweak64(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		tweak      string
+		plaintext  string
+		alphabet   string
+		ciphertext string
+	}{
+		// NIST SP 800-38G FF3示例（64位tweak）
+		{
+			name:       "sample1",
+			key:        "ef4359d8d580aa4f7f036d6f04fc6a94",
+			tweak:      "d8e7920afa330a73",
+			plaintext:  "890121234567890000",
+			alphabet:   FpeAlphabetDigits,
+			ciphertext: "750918814058654607",
+		},
+		{
+			name:       "sample2",
+			key:        "ef4359d8d580aa4f7f036d6f04fc6a94",
+			tweak:      "9a768a92f60e12d8",
+			plaintext:  "890121234567890000",
+			alphabet:   FpeAlphabetDigits,
+			ciphertext: "018989839189395384",
+		},
+		{
+			name:       "sample5",
+			key:        "ef4359d8d580aa4f7f036d6f04fc6a94",
+			tweak:      "9a768a92f60e12d8",
+			plaintext:  "0123456789abcdefghi",
+			alphabet:   "0123456789abcdefghijklmnop",
+			ciphertext: "g2pk40i992fn20cjakb",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, tweak := mustHex(tt.key), mustHex(tt.tweak)
+			crypt := func(key, _ []byte, radix int, x []int, encrypt bool) ([]int, error) {
+				return ff3CryptWithTweak64(aes.NewCipher, key, tweak, radix, x, encrypt)
+			}
+			got, err := fpeCrypt(aes.NewCipher, key, nil, tt.plaintext, tt.alphabet, fpeCryptFuncOf(crypt), true)
+			if err != nil {
+				t.Errorf("ff3 encrypt error = %v", err)
+				return
+			}
+			if got != tt.ciphertext {
+				t.Errorf("ff3 encrypt got = %v, want %v", got, tt.ciphertext)
+			}
+			got, err = fpeCrypt(aes.NewCipher, key, nil, tt.ciphertext, tt.alphabet, fpeCryptFuncOf(crypt), false)
+			if err != nil {
+				t.Errorf("ff3 decrypt error = %v", err)
+				return
+			}
+			if got != tt.plaintext {
+				t.Errorf("ff3 decrypt got = %v, want %v", got, tt.plaintext)
+			}
+		})
+	}
+}
+
+func Test_FpeRoundTrip(t *testing.T) {
+	key := mustHex("0123456789abcdeffedcba9876543210")
+	tweak := mustHex("00112233445566")
+	tests := []struct {
+		name      string
+		encrypt   func(key, tweak []byte, plaintext, alphabet string) (string, error)
+		decrypt   func(key, tweak []byte, ciphertext, alphabet string) (string, error)
+		plaintext string
+		alphabet  string
+	}{
+		{name: "sm4-ff1-card", encrypt: Sm4Ff1Encrypt, decrypt: Sm4Ff1Decrypt,
+			plaintext: "6222021234567890123", alphabet: FpeAlphabetDigits},
+		{name: "sm4-ff1-idcard", encrypt: Sm4Ff1Encrypt, decrypt: Sm4Ff1Decrypt,
+			plaintext: "11010519491231002X", alphabet: "0123456789X"},
+		{name: "aes-ff3-card", encrypt: AesFf3Encrypt, decrypt: AesFf3Decrypt,
+			plaintext: "6222021234567890123", alphabet: FpeAlphabetDigits},
+		{name: "sm4-ff3-alphanumeric", encrypt: Sm4Ff3Encrypt, decrypt: Sm4Ff3Decrypt,
+			plaintext: "HelloWorld2024", alphabet: FpeAlphabetAlphanumeric},
+		{name: "aes-ff1-unicode", encrypt: AesFf1Encrypt, decrypt: AesFf1Decrypt,
+			plaintext: "甲乙丙丁戊己庚辛壬癸甲乙", alphabet: "甲乙丙丁戊己庚辛壬癸"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ciphertext, err := tt.encrypt(key, tweak, tt.plaintext, tt.alphabet)
+			if err != nil {
+				t.Fatalf("encrypt error = %v", err)
+			}
+			if len([]rune(ciphertext)) != len([]rune(tt.plaintext)) || ciphertext == tt.plaintext {
+				t.Errorf("encrypt got = %v", ciphertext)
+			}
+			got, err := tt.decrypt(key, tweak, ciphertext, tt.alphabet)
+			if err != nil {
+				t.Fatalf("decrypt error = %v", err)
+			}
+			if got != tt.plaintext {
+				t.Errorf("decrypt got = %v, want %v", got, tt.plaintext)
+			}
+		})
+	}
+}
+
+func Test_FpeInvalidInput(t *testing.T) {
+	key := mustHex("0123456789abcdeffedcba9876543210")
+	tests := []struct {
+		name    string
+		encrypt func() (string, error)
+	}{
+		{name: "short", encrypt: func() (string, error) { return AesFf1Encrypt(key, nil, "12345", FpeAlphabetDigits) }},
+		{name: "alphabet", encrypt: func() (string, error) { return AesFf1Encrypt(key, nil, "12345a", "0") }},
+		{name: "duplicate", encrypt: func() (string, error) { return AesFf1Encrypt(key, nil, "1234567", "00123456789") }},
+		{name: "character", encrypt: func() (string, error) { return AesFf1Encrypt(key, nil, "123456a", FpeAlphabetDigits) }},
+		{name: "tweak", encrypt: func() (string, error) { return AesFf3Encrypt(key, nil, "1234567", FpeAlphabetDigits) }},
+		{name: "key", encrypt: func() (string, error) { return Sm4Ff1Encrypt(key[:8], nil, "1234567", FpeAlphabetDigits) }},
+		{name: "long", encrypt: func() (string, error) {
+			return AesFf3Encrypt(key, make([]byte, 7), "1234567890123456789012345678901234567890123456789012345678", FpeAlphabetDigits)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.encrypt(); err == nil {
+				t.Errorf("encrypt error = nil, wantErr true")
+			}
+		})
+	}
+}
+
+// fpeCryptFuncOf 将函数转换为fpeCryptFunc，忽略分组密码构造函数
+func fpeCryptFuncOf(f func(key, tweak []byte, radix int, x []int, encrypt bool) ([]int, error)) fpeCryptFunc {
+	return func(_ func(key []byte) (cipher.Block, error), key, tweak []byte, radix int, x []int, encrypt bool) ([]int, error) {
+		return f(key, tweak, radix, x, encrypt)
+	}
+}
@@ -0,0 +1,898 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/sm4"
+	"golang.org/x/crypto/xts"
+)
+
+/*
+模糊测试与差分测试：
+Fuzz_开头的函数为go原生的模糊测试，go test时只执行种子语料，需要持续模糊测试时单独执行，例如：
+go test -run '^$' -fuzz '^Fuzz_UnPadding$' -fuzztime 1m
+模糊测试校验两类性质：任意输入都不会panic（解密、去填充等处理外部数据的函数只能返回错误），
+以及加密后解密、填充后去填充能够得到原文。
+差分测试将本仓库的模式实现与标准库、gmsm等参考实现逐字节比对，参考实现只组合分组密码与标准模式，不经过本仓库的代码。
+*/
+
+// fuzzPaddings 参与模糊测试的填充方式，空字符串表示不填充
+var fuzzPaddings = []string{PaddingPkcs5, PaddingPkcs7, PaddingZero, PaddingAnsix923, PaddingIso10126, ""}
+
+// fuzzKey 生成长度为n的固定密钥，模糊测试关注数据处理，密钥不参与变异
+func fuzzKey(n int) []byte {
+	key := make([]byte, n)
+	for i := range key {
+		key[i] = byte(i*7 + 1)
+	}
+	return key
+}
+
+// fuzzPadding 按照kind选择填充方式
+func fuzzPadding(kind uint8) string {
+	return fuzzPaddings[int(kind)%len(fuzzPaddings)]
+}
+
+// fuzzUnPadded 填充后去填充期望得到的结果，0填充无法区分原文末尾的0与填充
+func fuzzUnPadded(padding string, data []byte) []byte {
+	if padding == PaddingZero {
+		return bytes.TrimRight(data, "\x00")
+	}
+	return data
+}
+
+// fuzzBlockCipher 参与差分测试的分组密码，cbc与gcm的封装函数为空时跳过对应的差分测试
+type fuzzBlockCipher struct {
+	name       string
+	key        []byte
+	reference  func(key []byte) (cipher.Block, error) // 参考实现
+	newCipher  func(key []byte) (cipher.Block, error) // 本仓库的实现
+	cbcEncrypt func(key, iv, plaintext []byte, padding string) ([]byte, error)
+	cbcDecrypt func(key, iv, ciphertext []byte, padding string) ([]byte, error)
+	gcmEncrypt func(key, nonce, plaintext, additionalData []byte) ([]byte, error)
+	gcmDecrypt func(key, nonce, ciphertext, additionalData []byte) ([]byte, error)
+}
+
+// fuzzBlockCiphers aes与sm4，sm4的参考实现为gmsm；
+// Sm4CbcEncrypt、Sm4GcmEncrypt等函数固定使用查表实现，位切片实现只参与ecb、ctr、并行gcm等接收newCipher的测试
+var fuzzBlockCiphers = []fuzzBlockCipher{
+	{
+		name: "aes-128", key: fuzzKey(16), reference: aes.NewCipher, newCipher: aes.NewCipher,
+		cbcEncrypt: AesCbcEncrypt, cbcDecrypt: AesCbcDecrypt, gcmEncrypt: AesGcmEncrypt, gcmDecrypt: AesGcmDecrypt,
+	},
+	{
+		name: "aes-256", key: fuzzKey(32), reference: aes.NewCipher, newCipher: aes.NewCipher,
+		cbcEncrypt: AesCbcEncrypt, cbcDecrypt: AesCbcDecrypt, gcmEncrypt: AesGcmEncrypt, gcmDecrypt: AesGcmDecrypt,
+	},
+	{
+		name: "sm4", key: fuzzKey(16), reference: sm4.NewCipher, newCipher: NewSm4Cipher,
+		cbcEncrypt: Sm4CbcEncrypt, cbcDecrypt: Sm4CbcDecrypt, gcmEncrypt: Sm4GcmEncrypt, gcmDecrypt: Sm4GcmDecrypt,
+	},
+	{
+		name: "sm4-bitsliced", key: fuzzKey(16), reference: sm4.NewCipher, newCipher: NewSm4BitslicedCipher,
+	},
+}
+
+func Fuzz_Padding(f *testing.F) {
+	f.Add([]byte(""), uint8(16), uint8(0))
+	f.Add([]byte("abc"), uint8(8), uint8(1))
+	f.Add([]byte("abc\x00\x00"), uint8(8), uint8(2))
+	f.Add(bytes.Repeat([]byte{0x10}, 16), uint8(16), uint8(3))
+	f.Add([]byte{0xff}, uint8(255), uint8(4))
+	f.Fuzz(func(t *testing.T, data []byte, blockSize, kind uint8) {
+		if blockSize == 0 {
+			return
+		}
+		padding := fuzzPadding(kind)
+		// 填充不能修改调用方的数据
+		src := append(bytes.Clone(data), 0xaa)[:len(data)]
+		padded := Padding(padding, src, int(blockSize))
+		if src[:len(data)+1][len(data)] != 0xaa {
+			t.Fatalf("Padding(%q) modified bytes beyond the input", padding)
+		}
+		if !bytes.HasPrefix(padded, data) {
+			t.Fatalf("Padding(%q) = %x, want prefix %x", padding, padded, data)
+		}
+		if padding != "" && (len(padded) <= len(data) || len(padded)%int(blockSize) != 0) {
+			t.Fatalf("Padding(%q, %d) length = %d, input %d", padding, blockSize, len(padded), len(data))
+		}
+		if got, want := UnPadding(padding, padded), fuzzUnPadded(padding, data); !bytes.Equal(got, want) {
+			t.Fatalf("UnPadding(%q) = %x, want %x", padding, got, want)
+		}
+	})
+}
+
+func Fuzz_UnPadding(f *testing.F) {
+	f.Add([]byte{}, uint8(0))
+	f.Add([]byte{0x00}, uint8(1))
+	f.Add([]byte{0x01, 0x02, 0xff}, uint8(1))
+	f.Add([]byte{0x00, 0x00}, uint8(2))
+	f.Fuzz(func(t *testing.T, data []byte, kind uint8) {
+		padding := fuzzPadding(kind)
+		if got := UnPadding(padding, data); !bytes.HasPrefix(data, got) {
+			t.Fatalf("UnPadding(%q, %x) = %x, want a prefix of the input", padding, data, got)
+		}
+	})
+}
+
+func Fuzz_CbcEncrypt(f *testing.F) {
+	f.Add([]byte(""), uint8(0))
+	f.Add([]byte("cbc fuzz plaintext"), uint8(1))
+	f.Add(make([]byte, 32), uint8(5))
+	f.Fuzz(func(t *testing.T, plaintext []byte, kind uint8) {
+		padding := fuzzPadding(kind)
+		iv := fuzzKey(16)
+		for _, c := range fuzzBlockCiphers {
+			if c.cbcEncrypt == nil {
+				continue
+			}
+			ciphertext, err := c.cbcEncrypt(c.key, iv, plaintext, padding)
+			padded := Padding(padding, bytes.Clone(plaintext), 16)
+			if len(padded)%16 != 0 {
+				// 不填充且数据不是整数个分组时只能返回错误
+				if err == nil {
+					t.Fatalf("%s CbcEncrypt() of %d bytes without padding error = nil", c.name, len(plaintext))
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s CbcEncrypt() error = %v", c.name, err)
+			}
+			// iso10126的填充内容在本仓库中与pkcs7相同，因此所有填充方式都可以与参考实现逐字节比对
+			block, _ := c.reference(c.key)
+			want := make([]byte, len(padded))
+			cipher.NewCBCEncrypter(block, iv).CryptBlocks(want, padded)
+			if !bytes.Equal(ciphertext, want) {
+				t.Fatalf("%s CbcEncrypt() = %x, reference %x", c.name, ciphertext, want)
+			}
+			got, err := c.cbcDecrypt(c.key, iv, ciphertext, padding)
+			if err != nil || !bytes.Equal(got, fuzzUnPadded(padding, plaintext)) {
+				t.Fatalf("%s CbcDecrypt() = %x, error = %v, want %x", c.name, got, err, plaintext)
+			}
+		}
+	})
+}
+
+func Fuzz_CbcDecrypt(f *testing.F) {
+	f.Add([]byte(""), uint8(0))
+	f.Add(make([]byte, 15), uint8(1))
+	f.Add(make([]byte, 32), uint8(2))
+	f.Fuzz(func(t *testing.T, ciphertext []byte, kind uint8) {
+		padding := fuzzPadding(kind)
+		iv := fuzzKey(16)
+		for _, c := range fuzzBlockCiphers {
+			if c.cbcDecrypt == nil {
+				continue
+			}
+			got, err := c.cbcDecrypt(c.key, iv, ciphertext, padding)
+			if len(ciphertext)%16 != 0 {
+				if err == nil {
+					t.Fatalf("%s CbcDecrypt() of %d bytes error = nil", c.name, len(ciphertext))
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s CbcDecrypt() error = %v", c.name, err)
+			}
+			block, _ := c.reference(c.key)
+			want := make([]byte, len(ciphertext))
+			cipher.NewCBCDecrypter(block, iv).CryptBlocks(want, ciphertext)
+			if want = UnPadding(padding, want); !bytes.Equal(got, want) {
+				t.Fatalf("%s CbcDecrypt() = %x, reference %x", c.name, got, want)
+			}
+		}
+	})
+}
+
+func Fuzz_Ecb(f *testing.F) {
+	f.Add([]byte(""))
+	f.Add(make([]byte, 16))
+	f.Add(bytes.Repeat([]byte("0123456789abcdef"), 70))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, c := range fuzzBlockCiphers {
+			block, _ := c.newCipher(c.key)
+			ciphertext, err := EcbEncrypt(block, data)
+			plaintext, decryptErr := EcbDecrypt(block, data)
+			if len(data)%16 != 0 {
+				if err == nil || decryptErr == nil {
+					t.Fatalf("%s ecb of %d bytes error = nil", c.name, len(data))
+				}
+				continue
+			}
+			if err != nil || decryptErr != nil {
+				t.Fatalf("%s ecb error = %v, %v", c.name, err, decryptErr)
+			}
+			// 参考实现逐个分组处理，覆盖批量接口与单分组接口的一致性
+			reference, _ := c.reference(c.key)
+			for i := 0; i < len(data); i += 16 {
+				want := make([]byte, 16)
+				reference.Encrypt(want, data[i:i+16])
+				if !bytes.Equal(ciphertext[i:i+16], want) {
+					t.Fatalf("%s EcbEncrypt() block %d = %x, reference %x", c.name, i/16, ciphertext[i:i+16], want)
+				}
+				reference.Decrypt(want, data[i:i+16])
+				if !bytes.Equal(plaintext[i:i+16], want) {
+					t.Fatalf("%s EcbDecrypt() block %d = %x, reference %x", c.name, i/16, plaintext[i:i+16], want)
+				}
+			}
+		}
+	})
+}
+
+func Fuzz_TripleDesEcb(f *testing.F) {
+	f.Add([]byte(""), uint8(0))
+	f.Add([]byte("The qufck brown fox jump"), uint8(5))
+	f.Add([]byte("3des"), uint8(2))
+	f.Fuzz(func(t *testing.T, data []byte, kind uint8) {
+		padding := fuzzPadding(kind)
+		key := fuzzKey(24)
+		reference, _ := des.NewTripleDESCipher(key)
+		// 任意密文解密不能panic，数据不是整数个分组时返回错误
+		if _, err := TripleDesEcbDecrypt(key, data, padding); (err == nil) != (len(data)%8 == 0) {
+			t.Fatalf("TripleDesEcbDecrypt() of %d bytes error = %v", len(data), err)
+		}
+		ciphertext, err := TripleDesEcbEncrypt(key, data, padding)
+		padded := Padding(padding, bytes.Clone(data), 8)
+		if len(padded)%8 != 0 {
+			if err == nil {
+				t.Fatalf("TripleDesEcbEncrypt() of %d bytes without padding error = nil", len(data))
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("TripleDesEcbEncrypt() error = %v", err)
+		}
+		want := make([]byte, len(padded))
+		for i := 0; i < len(padded); i += 8 {
+			reference.Encrypt(want[i:], padded[i:i+8])
+		}
+		if !bytes.Equal(ciphertext, want) {
+			t.Fatalf("TripleDesEcbEncrypt() = %x, reference %x", ciphertext, want)
+		}
+		got, err := TripleDesEcbDecrypt(key, ciphertext, padding)
+		if err != nil || !bytes.Equal(got, fuzzUnPadded(padding, data)) {
+			t.Fatalf("TripleDesEcbDecrypt() = %x, error = %v, want %x", got, err, data)
+		}
+	})
+}
+
+func Fuzz_Gcm(f *testing.F) {
+	f.Add([]byte(""), []byte(""))
+	f.Add([]byte("gcm fuzz plaintext"), []byte("ad"))
+	f.Add(make([]byte, 100), make([]byte, 17))
+	f.Fuzz(func(t *testing.T, plaintext, additionalData []byte) {
+		nonce := fuzzKey(12)
+		options := ParallelOptions{Threshold: 1, ChunkSize: 32, Workers: 3}
+		for _, c := range fuzzBlockCiphers {
+			block, _ := c.reference(c.key)
+			aead, _ := cipher.NewGCM(block)
+			ciphertext := aead.Seal(nil, nonce, plaintext, additionalData)
+			if c.gcmEncrypt != nil {
+				got, err := c.gcmEncrypt(c.key, nonce, plaintext, additionalData)
+				if err != nil || !bytes.Equal(got, ciphertext) {
+					t.Fatalf("%s GcmEncrypt() = %x, error = %v, reference %x", c.name, got, err, ciphertext)
+				}
+				got, err = c.gcmDecrypt(c.key, nonce, ciphertext, additionalData)
+				if err != nil || !bytes.Equal(got, plaintext) {
+					t.Fatalf("%s GcmDecrypt() = %x, error = %v, want %x", c.name, got, err, plaintext)
+				}
+				// 将明文作为伪造的密文解密，必须认证失败
+				if _, err = c.gcmDecrypt(c.key, nonce, plaintext, additionalData); err == nil {
+					t.Fatalf("%s GcmDecrypt() of forged ciphertext error = nil", c.name)
+				}
+			}
+			parallel, err := GcmEncryptParallel(c.newCipher, c.key, nonce, plaintext, additionalData, options)
+			if err != nil || !bytes.Equal(parallel, ciphertext) {
+				t.Fatalf("%s GcmEncryptParallel() = %x, error = %v, reference %x", c.name, parallel, err, ciphertext)
+			}
+			got, err := GcmDecryptParallel(c.newCipher, c.key, nonce, ciphertext, additionalData, options)
+			if err != nil || !bytes.Equal(got, plaintext) {
+				t.Fatalf("%s GcmDecryptParallel() = %x, error = %v, want %x", c.name, got, err, plaintext)
+			}
+			if _, err = GcmDecryptParallel(c.newCipher, c.key, nonce, plaintext, additionalData, options); err == nil {
+				t.Fatalf("%s GcmDecryptParallel() of forged ciphertext error = nil", c.name)
+			}
+		}
+	})
+}
+
+func Fuzz_Ctr(f *testing.F) {
+	f.Add([]byte(""), []byte{0xff})
+	f.Add(make([]byte, 100), bytes.Repeat([]byte{0xff}, 16))
+	f.Fuzz(func(t *testing.T, data, iv []byte) {
+		// 计数器溢出时的进位由iv决定，iv不足16字节时补0
+		iv = append(bytes.Clone(iv), make([]byte, 16)...)[:16]
+		options := ParallelOptions{Threshold: 1, ChunkSize: 32, Workers: 3}
+		for _, c := range fuzzBlockCiphers {
+			block, _ := c.reference(c.key)
+			want := make([]byte, len(data))
+			cipher.NewCTR(block, iv).XORKeyStream(want, data)
+			got, err := CtrXorParallel(c.newCipher, c.key, iv, data, options)
+			if err != nil || !bytes.Equal(got, want) {
+				t.Fatalf("%s CtrXorParallel() = %x, error = %v, reference %x", c.name, got, err, want)
+			}
+		}
+	})
+}
+
+func Fuzz_Xts(f *testing.F) {
+	f.Add(make([]byte, 16), uint64(0), uint8(0))
+	f.Add(make([]byte, 25), uint64(1), uint8(1))
+	f.Add(make([]byte, 15), uint64(0x3333333333), uint8(0))
+	f.Add(bytes.Repeat([]byte{0x44}, 100), uint64(1<<63), uint8(1))
+	f.Fuzz(func(t *testing.T, data []byte, sector uint64, kind uint8) {
+		standard := []string{XtsStandardIeee, XtsStandardGb}[kind%2]
+		options := ParallelOptions{Threshold: 1, ChunkSize: 32, Workers: 3}
+		for _, c := range []struct {
+			name      string
+			key       []byte
+			newCipher func(key []byte) (cipher.Block, error)
+			encrypt   func(key []byte, sector uint64, plaintext []byte, standard string) ([]byte, error)
+			decrypt   func(key []byte, sector uint64, ciphertext []byte, standard string) ([]byte, error)
+		}{
+			{"aes", fuzzKey(32), aes.NewCipher, AesXtsEncrypt, AesXtsDecrypt},
+			{"sm4", fuzzKey(32), NewSm4Cipher, Sm4XtsEncrypt, Sm4XtsDecrypt},
+		} {
+			ciphertext, err := c.encrypt(c.key, sector, data, standard)
+			if _, decryptErr := c.decrypt(c.key, sector, data, standard); (err == nil) != (decryptErr == nil) {
+				t.Fatalf("%s xts encrypt error = %v, decrypt error = %v", c.name, err, decryptErr)
+			}
+			if len(data) < xtsBlockSize {
+				if err == nil {
+					t.Fatalf("%s XtsEncrypt() of %d bytes error = nil", c.name, len(data))
+				}
+				continue
+			}
+			if err != nil || len(ciphertext) != len(data) {
+				t.Fatalf("%s XtsEncrypt() length = %d, error = %v", c.name, len(ciphertext), err)
+			}
+			parallel, err := XtsEncryptParallel(c.newCipher, c.key, sector, data, standard, options)
+			if err != nil || !bytes.Equal(parallel, ciphertext) {
+				t.Fatalf("%s XtsEncryptParallel() = %x, error = %v, want %x", c.name, parallel, err, ciphertext)
+			}
+			// x/crypto/xts只实现了IEEE 1619且不支持密文挪用
+			if c.name == "aes" && standard == XtsStandardIeee && len(data)%xtsBlockSize == 0 {
+				reference, _ := xts.NewCipher(aes.NewCipher, c.key)
+				want := make([]byte, len(data))
+				reference.Encrypt(want, data, sector)
+				if !bytes.Equal(ciphertext, want) {
+					t.Fatalf("AesXtsEncrypt() = %x, reference %x", ciphertext, want)
+				}
+			}
+			got, err := c.decrypt(c.key, sector, ciphertext, standard)
+			if err != nil || !bytes.Equal(got, data) {
+				t.Fatalf("%s XtsDecrypt() = %x, error = %v, want %x", c.name, got, err, data)
+			}
+			got, err = XtsDecryptParallel(c.newCipher, c.key, sector, ciphertext, standard, options)
+			if err != nil || !bytes.Equal(got, data) {
+				t.Fatalf("%s XtsDecryptParallel() = %x, error = %v, want %x", c.name, got, err, data)
+			}
+		}
+	})
+}
+
+func Fuzz_Aead(f *testing.F) {
+	f.Add([]byte(""), []byte(""), uint8(0))
+	f.Add([]byte("aead fuzz plaintext"), []byte("ad"), uint8(3))
+	f.Add(make([]byte, 64), []byte{0}, uint8(7))
+	f.Fuzz(func(t *testing.T, plaintext, additionalData []byte, kind uint8) {
+		names := []string{CipherAesGcm, CipherSm4Gcm, CipherAesGcmSiv, CipherSm4GcmSiv, CipherChaCha20Poly1305,
+			CipherXChaCha20Poly1305, CipherAesSiv, CipherSm4Siv}
+		name := names[int(kind)%len(names)]
+		key := fuzzKey(cipherMap[name].keySize)
+		// 任意密文解密不能panic，也不能通过认证
+		if got, err := Decrypt(name, key, plaintext, additionalData); err == nil {
+			t.Fatalf("%s Decrypt() of forged ciphertext = %x, error = nil", name, got)
+		}
+		ciphertext, err := Encrypt(name, key, plaintext, additionalData)
+		if err != nil {
+			t.Fatalf("%s Encrypt() error = %v", name, err)
+		}
+		got, err := Decrypt(name, key, ciphertext, additionalData)
+		if err != nil || !bytes.Equal(got, plaintext) {
+			t.Fatalf("%s Decrypt() = %x, error = %v, want %x", name, got, err, plaintext)
+		}
+		for _, i := range []int{0, len(ciphertext) / 2, len(ciphertext) - 1} {
+			tampered := bytes.Clone(ciphertext)
+			tampered[i] ^= 0x80
+			if _, err = Decrypt(name, key, tampered, additionalData); err == nil {
+				t.Fatalf("%s Decrypt() with byte %d tampered error = nil", name, i)
+			}
+		}
+		if _, err = Decrypt(name, key, ciphertext, append(bytes.Clone(additionalData), 0)); err == nil {
+			t.Fatalf("%s Decrypt() with other additional data error = nil", name)
+		}
+	})
+}
+
+func Fuzz_KeyWrap(f *testing.F) {
+	f.Add([]byte(""))
+	f.Add(make([]byte, 7))
+	f.Add(make([]byte, 16))
+	f.Add(make([]byte, 40))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		kek := fuzzKey(16)
+		for _, c := range []struct {
+			name   string
+			wrap   func(kek, key []byte) ([]byte, error)
+			unwrap func(kek, wrapped []byte) ([]byte, error)
+		}{
+			{"aes", AesKeyWrap, AesKeyUnwrap},
+			{"aes-pad", AesKeyWrapPad, AesKeyUnwrapPad},
+			{"sm4", Sm4KeyWrap, Sm4KeyUnwrap},
+			{"sm4-pad", Sm4KeyWrapPad, Sm4KeyUnwrapPad},
+		} {
+			if _, err := c.unwrap(kek, data); err == nil {
+				t.Fatalf("%s KeyUnwrap() of forged data error = nil", c.name)
+			}
+			wrapped, err := c.wrap(kek, data)
+			if err != nil {
+				continue
+			}
+			got, err := c.unwrap(kek, wrapped)
+			if err != nil || !bytes.Equal(got, data) {
+				t.Fatalf("%s KeyUnwrap() = %x, error = %v, want %x", c.name, got, err, data)
+			}
+		}
+	})
+}
+
+func Fuzz_Fpe(f *testing.F) {
+	f.Add("0123456789", []byte{}, uint8(0))
+	f.Add("4111111111111111", []byte("tweak12"), uint8(1))
+	f.Add("abc-123", []byte{1, 2, 3, 4, 5, 6, 7}, uint8(2))
+	f.Fuzz(func(t *testing.T, plaintext string, tweak []byte, kind uint8) {
+		alphabet := []string{FpeAlphabetDigits, FpeAlphabetLowerAlphanumeric, FpeAlphabetAlphanumeric}[kind%3]
+		key := fuzzKey(16)
+		for _, c := range []struct {
+			name    string
+			encrypt func(key, tweak []byte, plaintext, alphabet string) (string, error)
+			decrypt func(key, tweak []byte, ciphertext, alphabet string) (string, error)
+		}{
+			{"aes-ff1", AesFf1Encrypt, AesFf1Decrypt},
+			{"sm4-ff1", Sm4Ff1Encrypt, Sm4Ff1Decrypt},
+			{"aes-ff3", AesFf3Encrypt, AesFf3Decrypt},
+			{"sm4-ff3", Sm4Ff3Encrypt, Sm4Ff3Decrypt},
+		} {
+			ciphertext, err := c.encrypt(key, tweak, plaintext, alphabet)
+			if _, decryptErr := c.decrypt(key, tweak, plaintext, alphabet); (err == nil) != (decryptErr == nil) {
+				t.Fatalf("%s encrypt error = %v, decrypt error = %v", c.name, err, decryptErr)
+			}
+			if err != nil {
+				continue
+			}
+			if len(ciphertext) != len(plaintext) || strings.Trim(ciphertext, alphabet) != "" {
+				t.Fatalf("%s FpeEncrypt(%q) = %q, not in the same domain", c.name, plaintext, ciphertext)
+			}
+			got, err := c.decrypt(key, tweak, ciphertext, alphabet)
+			if err != nil || got != plaintext {
+				t.Fatalf("%s FpeDecrypt() = %q, error = %v, want %q", c.name, got, err, plaintext)
+			}
+		}
+	})
+}
+
+func Fuzz_Zuc(f *testing.F) {
+	f.Add([]byte(""), uint32(0), uint8(0), 0)
+	f.Add(make([]byte, 24), uint32(0x66035492), uint8(0xf), 190)
+	f.Add([]byte{0xff}, uint32(1), uint8(0x1f), 9)
+	f.Fuzz(func(t *testing.T, data []byte, count uint32, bearer uint8, bitLength int) {
+		key, iv := fuzzKey(16), fuzzKey(16)
+		ciphertext, err := ZucEncrypt(key, iv, data)
+		if err != nil {
+			t.Fatalf("ZucEncrypt() error = %v", err)
+		}
+		if got, err := ZucDecrypt(key, iv, ciphertext); err != nil || !bytes.Equal(got, data) {
+			t.Fatalf("ZucDecrypt() = %x, error = %v, want %x", got, err, data)
+		}
+		bearer &= 0x1f
+		ciphertext, err = Zuc128Eea3Encrypt(key, count, bearer, 1, data, len(data)*8)
+		if err != nil {
+			t.Fatalf("Zuc128Eea3Encrypt() error = %v", err)
+		}
+		got, err := Zuc128Eea3Decrypt(key, count, bearer, 1, ciphertext, len(data)*8)
+		if err != nil || !bytes.Equal(got, data) {
+			t.Fatalf("Zuc128Eea3Decrypt() = %x, error = %v, want %x", got, err, data)
+		}
+		// 任意位数不能panic，输出ceil(bitLength/8)字节且超出位数的位为0
+		ciphertext, err = Zuc128Eea3Encrypt(key, count, bearer, 1, data, bitLength)
+		if (err == nil) != (bitLength >= 0 && bitLength <= len(data)*8) {
+			t.Fatalf("Zuc128Eea3Encrypt() with bit length %d of %d bytes error = %v", bitLength, len(data), err)
+		}
+		if err == nil {
+			if len(ciphertext) != (bitLength+7)/8 {
+				t.Fatalf("Zuc128Eea3Encrypt() with bit length %d got %d bytes", bitLength, len(ciphertext))
+			}
+			if remain := bitLength % 8; remain != 0 && ciphertext[len(ciphertext)-1]&(0xff>>remain) != 0 {
+				t.Fatalf("Zuc128Eea3Encrypt() with bit length %d trailing bits = %08b", bitLength, ciphertext[len(ciphertext)-1])
+			}
+		}
+		// 任意位数不能panic，超出消息长度时返回错误
+		mac, err := Zuc128Eia3(key, count, bearer, 1, data, bitLength)
+		if (err == nil) != (bitLength >= 0 && bitLength <= len(data)*8) {
+			t.Fatalf("Zuc128Eia3() with bit length %d of %d bytes error = %v", bitLength, len(data), err)
+		}
+		if err == nil {
+			if err = Zuc128Eia3Verify(key, count, bearer, 1, data, bitLength, mac); err != nil {
+				t.Fatalf("Zuc128Eia3Verify() error = %v", err)
+			}
+		}
+	})
+}
+
+func Fuzz_Sm2(f *testing.F) {
+	f.Add([]byte(""))
+	f.Add([]byte("encryption standard"))
+	f.Add([]byte{0x30, 0x03, 0x02, 0x01, 0x00})
+	// 使用sm2_test.go中的测试密钥
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// 任意密文解密不能panic
+		for _, mode := range []int{sm2.C1C3C2, sm2.C1C2C3} {
+			if got, err := Sm2DecryptAsn1(privateKey, data, mode); err == nil {
+				t.Fatalf("Sm2DecryptAsn1() of forged ciphertext = %x, error = nil", got)
+			}
+		}
+		if len(data) == 0 {
+			return
+		}
+		ciphertext, err := Sm2EncryptAsn1(&privateKey.PublicKey, data, sm2.C1C3C2)
+		if err != nil {
+			t.Fatalf("Sm2EncryptAsn1() error = %v", err)
+		}
+		got, err := Sm2DecryptAsn1(privateKey, ciphertext, sm2.C1C3C2)
+		if err != nil || !bytes.Equal(got, data) {
+			t.Fatalf("Sm2DecryptAsn1() = %x, error = %v, want %x", got, err, data)
+		}
+	})
+}
+
+func Fuzz_Ecies(f *testing.F) {
+	f.Add([]byte(""))
+	f.Add([]byte("ecies fuzz plaintext"))
+	f.Add(make([]byte, 80))
+	privateKey, err := ecdh.X25519().NewPrivateKey(fuzzKey(32))
+	if err != nil {
+		f.Fatalf("create x25519 private key error = %v", err)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if got, err := EciesDecrypt(privateKey, data); err == nil {
+			t.Fatalf("EciesDecrypt() of forged ciphertext = %x, error = nil", got)
+		}
+		ciphertext, err := EciesEncrypt(privateKey.PublicKey(), data)
+		if err != nil {
+			t.Fatalf("EciesEncrypt() error = %v", err)
+		}
+		got, err := EciesDecrypt(privateKey, ciphertext)
+		if err != nil || !bytes.Equal(got, data) {
+			t.Fatalf("EciesDecrypt() = %x, error = %v, want %x", got, err, data)
+		}
+	})
+}
+
+func Fuzz_FileDecrypt(f *testing.F) {
+	privateKey, err := ecdh.X25519().NewPrivateKey(fuzzKey(32))
+	if err != nil {
+		f.Fatalf("create x25519 private key error = %v", err)
+	}
+	f.Add([]byte(""))
+	f.Add([]byte("tutils"))
+	const plaintext = "file fuzz plaintext"
+	var encrypted bytes.Buffer
+	if err = FileEncrypt(&encrypted, strings.NewReader(plaintext), CipherAesGcm, privateKey.PublicKey()); err != nil {
+		f.Fatalf("FileEncrypt() error = %v", err)
+	}
+	f.Add(encrypted.Bytes())
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// 任意输入不能panic，解密成功时只能是某次FileEncrypt的完整输出
+		// 模糊测试的每个进程各自生成种子文件，因此比对明文而不是比对文件内容
+		var got bytes.Buffer
+		if err := FileDecrypt(&got, bytes.NewReader(data), privateKey); err == nil && got.String() != plaintext {
+			t.Fatalf("FileDecrypt() of forged file = %q, error = nil", got.String())
+		}
+	})
+}
+
+func Fuzz_SivDecrypt(f *testing.F) {
+	f.Add([]byte(""), []byte(""))
+	f.Add(make([]byte, 16), []byte("ad"))
+	f.Add(make([]byte, 40), make([]byte, 17))
+	f.Fuzz(func(t *testing.T, data, additionalData []byte) {
+		nonce := fuzzKey(12)
+		for _, c := range []struct {
+			name    string
+			encrypt func(plaintext []byte) ([]byte, error)
+			decrypt func(ciphertext []byte) ([]byte, error)
+		}{
+			{
+				name:    "aes-siv",
+				encrypt: func(plaintext []byte) ([]byte, error) { return AesSivEncrypt(fuzzKey(32), plaintext, additionalData) },
+				decrypt: func(ciphertext []byte) ([]byte, error) { return AesSivDecrypt(fuzzKey(32), ciphertext, additionalData) },
+			},
+			{
+				// 多个附加数据的情况
+				name: "aes-siv-multi",
+				encrypt: func(plaintext []byte) ([]byte, error) {
+					return AesSivEncrypt(fuzzKey(64), plaintext, additionalData, nonce, nil)
+				},
+				decrypt: func(ciphertext []byte) ([]byte, error) {
+					return AesSivDecrypt(fuzzKey(64), ciphertext, additionalData, nonce, nil)
+				},
+			},
+			{
+				name:    "sm4-siv",
+				encrypt: func(plaintext []byte) ([]byte, error) { return Sm4SivEncrypt(fuzzKey(32), plaintext, additionalData) },
+				decrypt: func(ciphertext []byte) ([]byte, error) { return Sm4SivDecrypt(fuzzKey(32), ciphertext, additionalData) },
+			},
+			{
+				name: "aes-gcm-siv",
+				encrypt: func(plaintext []byte) ([]byte, error) {
+					return AesGcmSivEncrypt(fuzzKey(32), nonce, plaintext, additionalData)
+				},
+				decrypt: func(ciphertext []byte) ([]byte, error) {
+					return AesGcmSivDecrypt(fuzzKey(32), nonce, ciphertext, additionalData)
+				},
+			},
+			{
+				name: "sm4-gcm-siv",
+				encrypt: func(plaintext []byte) ([]byte, error) {
+					return Sm4GcmSivEncrypt(fuzzKey(16), nonce, plaintext, additionalData)
+				},
+				decrypt: func(ciphertext []byte) ([]byte, error) {
+					return Sm4GcmSivDecrypt(fuzzKey(16), nonce, ciphertext, additionalData)
+				},
+			},
+		} {
+			// 任意密文解密不能panic，也不能通过认证
+			if got, err := c.decrypt(data); err == nil {
+				t.Fatalf("%s decrypt of forged ciphertext = %x, error = nil", c.name, got)
+			}
+			ciphertext, err := c.encrypt(data)
+			if err != nil {
+				t.Fatalf("%s encrypt error = %v", c.name, err)
+			}
+			got, err := c.decrypt(ciphertext)
+			if err != nil || !bytes.Equal(got, data) {
+				t.Fatalf("%s decrypt = %x, error = %v, want %x", c.name, got, err, data)
+			}
+		}
+	})
+}
+
+func Fuzz_JweDecrypt(f *testing.F) {
+	const plaintext = "jwe fuzz plaintext"
+	kek128, kek256 := fuzzKey(16), fuzzKey(32)
+	keys := []struct {
+		header     JoseHeader
+		encryptKey any
+		decryptKey any
+	}{
+		{JoseHeader{Algorithm: JweRsaOaep256, Encryption: JweA256Gcm}, &rsaPrivateKey.PublicKey, rsaPrivateKey},
+		{JoseHeader{Algorithm: JweA128Kw, Encryption: JweSm4Gcm}, kek128, kek128},
+		{JoseHeader{Algorithm: JweA256Kw, Encryption: JweA256Gcm}, kek256, kek256},
+		{JoseHeader{Algorithm: JweDir, Encryption: JweA256Gcm}, kek256, kek256},
+		{JoseHeader{Algorithm: JweSm2, Encryption: JweSm4Gcm}, &privateKey.PublicKey, privateKey},
+	}
+	f.Add("")
+	f.Add("....")
+	f.Add("eyJhbGciOiJkaXIiLCJlbmMiOiJBMjU2R0NNIn0....")
+	for _, key := range keys {
+		token, err := JweEncrypt(key.header, []byte(plaintext), key.encryptKey)
+		if err != nil {
+			f.Fatalf("JweEncrypt(%s) error = %v", key.header.Algorithm, err)
+		}
+		f.Add(token)
+	}
+	f.Fuzz(func(t *testing.T, token string) {
+		// 任意输入不能panic，解密成功时只能是种子中的某个令牌
+		for _, key := range keys {
+			if got, _, err := JweDecrypt(token, key.decryptKey); err == nil && string(got) != plaintext {
+				t.Fatalf("JweDecrypt() of forged token with %s key = %q, error = nil", key.header.Algorithm, got)
+			}
+		}
+	})
+}
+
+func Fuzz_JwsVerify(f *testing.F) {
+	const payload = `{"sub":"tyanxie"}`
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		f.Fatalf("ecdsa.GenerateKey() error = %v", err)
+	}
+	ed25519Key := ed25519.NewKeyFromSeed(fuzzKey(32))
+	keys := []struct {
+		algorithm string
+		signKey   any
+		verifyKey any
+	}{
+		{JwsHs256, fuzzKey(32), fuzzKey(32)},
+		{JwsRs256, rsaPrivateKey, &rsaPrivateKey.PublicKey},
+		{JwsPs256, rsaPrivateKey, &rsaPrivateKey.PublicKey},
+		{JwsEs256, ecdsaKey, &ecdsaKey.PublicKey},
+		{JwsEdDsa, ed25519Key, ed25519Key.Public()},
+		{JwsSm2Sm3, privateKey, &privateKey.PublicKey},
+	}
+	f.Add("")
+	f.Add("..")
+	f.Add("eyJhbGciOiJub25lIn0.e30.")
+	for _, key := range keys {
+		token, err := JwsSign(JoseHeader{Algorithm: key.algorithm}, []byte(payload), key.signKey)
+		if err != nil {
+			f.Fatalf("JwsSign(%s) error = %v", key.algorithm, err)
+		}
+		f.Add(token)
+	}
+	f.Fuzz(func(t *testing.T, token string) {
+		// 任意输入不能panic，校验通过时只能是种子中的某个令牌
+		for _, key := range keys {
+			if got, _, err := JwsVerify(token, key.verifyKey); err == nil && string(got) != payload {
+				t.Fatalf("JwsVerify() of forged token with %s key = %q, error = nil", key.algorithm, got)
+			}
+		}
+	})
+}
+
+func Fuzz_RsaDecrypt(f *testing.F) {
+	plaintext := []byte("rsa fuzz plaintext")
+	f.Add([]byte(""))
+	f.Add(make([]byte, 256))
+	oaep, err := RsaEncryptOaep(&rsaPrivateKey.PublicKey, plaintext, nil, crypto.SHA256)
+	if err != nil {
+		f.Fatalf("RsaEncryptOaep() error = %v", err)
+	}
+	f.Add(oaep)
+	pkcs1v15, err := RsaEncryptPkcs1v15(&rsaPrivateKey.PublicKey, plaintext)
+	if err != nil {
+		f.Fatalf("RsaEncryptPkcs1v15() error = %v", err)
+	}
+	f.Add(pkcs1v15)
+	f.Fuzz(func(t *testing.T, ciphertext []byte) {
+		// 任意密文解密不能panic，oaep解密成功时只能是种子密文
+		got, err := RsaDecryptOaep(rsaPrivateKey, ciphertext, nil, crypto.SHA256)
+		if err == nil && !bytes.Equal(got, plaintext) {
+			t.Fatalf("RsaDecryptOaep() of forged ciphertext = %x, error = nil", got)
+		}
+		// pkcs1 v1.5没有完整性保护，随机密文也可能恰好解密成功，只校验不panic
+		_, _ = RsaDecryptPkcs1v15(rsaPrivateKey, ciphertext)
+	})
+}
+
+// fuzzPasswordCheap 判断哈希字符串中的计算参数是否足够小，
+// PasswordVerify按照哈希字符串中的参数计算，模糊测试生成的大参数会耗尽时间与内存，这里只执行低成本的输入
+func fuzzPasswordCheap(encoded string) bool {
+	if isBcryptHash(encoded) {
+		cost, err := strconv.Atoi(encoded[4:min(len(encoded), 6)])
+		return err != nil || cost <= 5
+	}
+	phc, err := parsePhcHash(encoded)
+	if err != nil {
+		return true
+	}
+	if len(phc.hash) > 64 {
+		return false
+	}
+	switch phc.id {
+	case PasswordArgon2id:
+		return phc.param("m") <= 64 && phc.param("t") <= 2 && phc.param("p") <= 4
+	case PasswordScrypt:
+		return phc.param("ln") <= 8 && phc.param("r") <= 8 && phc.param("p") <= 2
+	case PasswordPbkdf2Sm3:
+		return phc.param("i") <= 100
+	default:
+		return true
+	}
+}
+
+func Fuzz_PasswordVerify(f *testing.F) {
+	const password = "Hello World"
+	f.Add("")
+	f.Add("$")
+	f.Add("$argon2id$v=19$m=64,t=1,p=1$$")
+	f.Add("$2a$04$")
+	for _, params := range testPasswordParams {
+		encoded, err := PasswordHash(password, params)
+		if err != nil {
+			f.Fatalf("PasswordHash() error = %v", err)
+		}
+		f.Add(encoded)
+	}
+	f.Fuzz(func(t *testing.T, encoded string) {
+		// 解析成功的哈希重新编码后可以再次解析为相同结果
+		if phc, err := parsePhcHash(encoded); err == nil {
+			again, err := parsePhcHash(phc.String())
+			if err != nil || again.String() != phc.String() {
+				t.Fatalf("parsePhcHash(%q) = %q, reparse error = %v", encoded, phc.String(), err)
+			}
+		}
+		if !fuzzPasswordCheap(encoded) {
+			return
+		}
+		// 任意输入不能panic，匹配成功时哈希只能由该密码计算得到，因此其它密码不能匹配
+		ok, err := PasswordVerify(password, encoded)
+		if err != nil || !ok {
+			return
+		}
+		if other, err := PasswordVerify(password+"!", encoded); err != nil || other {
+			t.Fatalf("PasswordVerify(%q) matched another password, error = %v", encoded, err)
+		}
+	})
+}
+
+func Fuzz_MacVerify(f *testing.F) {
+	f.Add([]byte(""), []byte(""), uint8(0))
+	f.Add([]byte("Now is the time for all "), []byte{0xa1, 0xc7, 0x2e, 0x74}, uint8(1))
+	f.Add(make([]byte, 33), make([]byte, 16), uint8(2))
+	aesBlock, _ := aes.NewCipher(fuzzKey(16))
+	desBlock, _ := des.NewTripleDESCipher(fuzzKey(24))
+	nonce := fuzzKey(12)
+	paddings := []string{MacPaddingIso9797M1, MacPaddingIso9797M2}
+	f.Fuzz(func(t *testing.T, data, mac []byte, kind uint8) {
+		padding := paddings[int(kind)%len(paddings)]
+		for _, c := range []struct {
+			name   string
+			sum    func() ([]byte, error)
+			verify func(mac []byte) error
+		}{
+			{
+				name:   "aes-cmac",
+				sum:    func() ([]byte, error) { return AesCmac(fuzzKey(16), data) },
+				verify: func(mac []byte) error { return AesCmacVerify(fuzzKey(16), data, mac) },
+			},
+			{
+				name:   "sm4-cmac",
+				sum:    func() ([]byte, error) { return Sm4Cmac(fuzzKey(16), data) },
+				verify: func(mac []byte) error { return Sm4CmacVerify(fuzzKey(16), data, mac) },
+			},
+			{
+				name:   "3des-cmac",
+				sum:    func() ([]byte, error) { return Cmac(desBlock, data) },
+				verify: func(mac []byte) error { return CmacVerify(desBlock, data, mac) },
+			},
+			{
+				name:   "aes-gmac",
+				sum:    func() ([]byte, error) { return AesGmac(fuzzKey(16), nonce, data) },
+				verify: func(mac []byte) error { return AesGmacVerify(fuzzKey(16), nonce, data, mac) },
+			},
+			{
+				name:   "sm4-gmac",
+				sum:    func() ([]byte, error) { return Sm4Gmac(fuzzKey(16), nonce, data) },
+				verify: func(mac []byte) error { return Sm4GmacVerify(fuzzKey(16), nonce, data, mac) },
+			},
+			{
+				name:   "cbc-mac",
+				sum:    func() ([]byte, error) { return CbcMac(aesBlock, data, padding) },
+				verify: func(mac []byte) error { return CbcMacVerify(aesBlock, data, padding, mac) },
+			},
+			{
+				name:   "retail-mac",
+				sum:    func() ([]byte, error) { return TripleDesRetailMac(fuzzKey(16), data, padding) },
+				verify: func(mac []byte) error { return TripleDesRetailMacVerify(fuzzKey(16), data, padding, mac) },
+			},
+		} {
+			expected, err := c.sum()
+			if err != nil {
+				t.Fatalf("%s sum error = %v", c.name, err)
+			}
+			// 任意mac校验不能panic，只有不短于4字节的正确mac前缀可以通过
+			want := len(mac) >= macMinLength && len(mac) <= len(expected) && bytes.Equal(mac, expected[:len(mac)])
+			if err = c.verify(mac); (err == nil) != want {
+				t.Fatalf("%s verify(%x) error = %v, expected mac %x", c.name, mac, err, expected)
+			}
+			if err = c.verify(expected); err != nil {
+				t.Fatalf("%s verify(expected) error = %v", c.name, err)
+			}
+		}
+	})
+}
@@ -2,9 +2,9 @@ module github.com/tyanxie/tutils/crypto
 
 go 1.24.1
 
-require github.com/tjfoc/gmsm v1.4.1
-
 require (
-	golang.org/x/crypto v0.0.0-20201012173705-84dcc777aaee // indirect
-	golang.org/x/sys v0.0.0-20200930185726-fdedc70b468f // indirect
+	github.com/tjfoc/gmsm v1.4.1
+	golang.org/x/crypto v0.0.0-20201012173705-84dcc777aaee
 )
+
+require golang.org/x/sys v0.0.0-20200930185726-fdedc70b468f // indirect
@@ -0,0 +1,65 @@
+// Package crypto 哈希与hmac工具包
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/tjfoc/gmsm/sm3"
+)
+
+// 哈希算法枚举
+const (
+	// HashSha256 sha256
+	HashSha256 = "sha256"
+	// HashSm3 sm3
+	HashSm3 = "sm3"
+)
+
+// hashMap 哈希算法映射
+var hashMap = map[string]func() hash.Hash{
+	HashSha256: sha256.New,
+	HashSm3:    newSm3,
+}
+
+// HmacSum 计算hmac
+// @param hashName 哈希算法
+// @param key 密钥
+// @param data 数据
+func HmacSum(hashName string, key, data []byte) ([]byte, error) {
+	h, err := newHash(hashName)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(h, key)
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+// newHash 获取哈希算法构造函数
+func newHash(hashName string) (func() hash.Hash, error) {
+	if h, ok := hashMap[strings.ToLower(hashName)]; ok && h != nil {
+		return h, nil
+	}
+	return nil, fmt.Errorf("unsupported hash: %s", hashName)
+}
+
+// sm3Hash 包装github.com/tjfoc/gmsm/sm3，
+// 该库的Sum(b)会将b写入哈希状态且只返回摘要本身，与hash.Hash的约定不符，
+// 在hmac、pbkdf2、hkdf等传入非空b的场景下会得到错误结果甚至panic
+type sm3Hash struct {
+	hash.Hash
+}
+
+// newSm3 创建sm3哈希
+func newSm3() hash.Hash {
+	return sm3Hash{Hash: sm3.New()}
+}
+
+// Sum 将摘要追加到b之后返回，不改变哈希状态
+func (h sm3Hash) Sum(b []byte) []byte {
+	return append(b, h.Hash.Sum(nil)...)
+}
@@ -0,0 +1,72 @@
+package crypto
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_HmacSum(t *testing.T) {
+	tests := []struct {
+		name     string
+		hashName string
+		key      []byte
+		data     []byte
+		want     []byte
+		wantErr  bool
+	}{
+		{
+			// RFC 4231 测试用例2
+			name:     "sha256",
+			hashName: HashSha256,
+			key:      []byte("Jefe"),
+			data:     []byte("what do ya want for nothing?"),
+			want:     mustHex("5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"),
+		},
+		{
+			// sha1与sha512只用于一次性密码
+			name:     "sha1",
+			hashName: OtpHashSha1,
+			wantErr:  true,
+		},
+		{
+			name:     "sha512",
+			hashName: OtpHashSha512,
+			wantErr:  true,
+		},
+		{
+			name:     "unsupported",
+			hashName: "md5",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := HmacSum(tt.hashName, tt.key, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HmacSum() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HmacSum() got = %x, want %x", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Sm3HashSum(t *testing.T) {
+	// GB/T 32905 附录A.1示例
+	want := mustHex("66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0")
+	h := newSm3()
+	h.Write([]byte("abc"))
+	if got := h.Sum(nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("Sum() got = %x, want %x", got, want)
+	}
+	// Sum(b)需要将摘要追加到b之后，且不改变哈希状态
+	prefix := []byte{1, 2, 3}
+	if got := h.Sum(prefix); !reflect.DeepEqual(got, append(prefix, want...)) {
+		t.Errorf("Sum(prefix) got = %x", got)
+	}
+	if got := h.Sum(nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("Sum() after Sum(prefix) got = %x, want %x", got, want)
+	}
+}
@@ -0,0 +1,159 @@
+// Package crypto json加密字段类型
+package crypto
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+/*
+JsonEncryptedString与JsonEncryptedBytes实现了json.Marshaler与json.Unmarshaler接口，
+用于接口响应中单独加密部分字段（例如使用sm4加密手机号）：序列化时加密并编码为字符串，反序列化时解码并解密，例如：
+type UserResponse struct {
+	Name  string                     `json:"name"`
+	Phone crypto.JsonEncryptedString `json:"phone"` // 序列化结果为"<base64密文>"
+}
+JsonEncryptedString与JsonEncryptedBytes使用服务启动时通过SetJsonFieldConfig设置的全局加密配置，编码方式由配置中的Encoding决定。
+不同字段需要使用不同的算法或密钥时，使用以CipherConfigProvider为类型参数的JsonEncryptedStringOf与JsonEncryptedBytesOf，
+每种类型参数对应一份独立的加密配置，例如Phone crypto.JsonEncryptedStringOf[PhoneConfig]。
+空值序列化为空字符串，反序列化时null与空字符串均视为空值。
+*/
+
+// jsonFieldConfig json加密字段使用的全局加密配置
+var jsonFieldConfig atomic.Pointer[CipherConfig]
+
+// SetJsonFieldConfig 设置json加密字段使用的全局加密配置
+// @param config 加密配置
+func SetJsonFieldConfig(config CipherConfig) error {
+	if err := config.validate(); err != nil {
+		return err
+	}
+	jsonFieldConfig.Store(&config)
+	return nil
+}
+
+// jsonFieldGlobalConfig 使用全局加密配置的CipherConfigProvider
+type jsonFieldGlobalConfig struct{}
+
+// CipherConfig 获取json加密字段使用的全局加密配置
+func (jsonFieldGlobalConfig) CipherConfig() (*CipherConfig, error) {
+	config := jsonFieldConfig.Load()
+	if config == nil {
+		return nil, errors.New("json field config is not set")
+	}
+	return config, nil
+}
+
+// JsonEncryptedString json中以编码后密文表示的字符串，使用全局加密配置
+type JsonEncryptedString string
+
+// MarshalJSON 实现json.Marshaler
+func (s JsonEncryptedString) MarshalJSON() ([]byte, error) {
+	return marshalJsonField(jsonFieldGlobalConfig{}, []byte(s))
+}
+
+// UnmarshalJSON 实现json.Unmarshaler
+func (s *JsonEncryptedString) UnmarshalJSON(data []byte) error {
+	plaintext, err := unmarshalJsonField(jsonFieldGlobalConfig{}, data)
+	if err != nil {
+		return err
+	}
+	*s = JsonEncryptedString(plaintext)
+	return nil
+}
+
+// JsonEncryptedBytes json中以编码后密文表示的字节数组，使用全局加密配置
+type JsonEncryptedBytes []byte
+
+// MarshalJSON 实现json.Marshaler
+func (b JsonEncryptedBytes) MarshalJSON() ([]byte, error) {
+	return marshalJsonField(jsonFieldGlobalConfig{}, b)
+}
+
+// UnmarshalJSON 实现json.Unmarshaler
+func (b *JsonEncryptedBytes) UnmarshalJSON(data []byte) error {
+	plaintext, err := unmarshalJsonField(jsonFieldGlobalConfig{}, data)
+	if err != nil {
+		return err
+	}
+	*b = plaintext
+	return nil
+}
+
+// JsonEncryptedStringOf json中以编码后密文表示的字符串，使用类型参数P提供的加密配置
+type JsonEncryptedStringOf[P CipherConfigProvider] string
+
+// MarshalJSON 实现json.Marshaler
+func (s JsonEncryptedStringOf[P]) MarshalJSON() ([]byte, error) {
+	var provider P
+	return marshalJsonField(provider, []byte(s))
+}
+
+// UnmarshalJSON 实现json.Unmarshaler
+func (s *JsonEncryptedStringOf[P]) UnmarshalJSON(data []byte) error {
+	var provider P
+	plaintext, err := unmarshalJsonField(provider, data)
+	if err != nil {
+		return err
+	}
+	*s = JsonEncryptedStringOf[P](plaintext)
+	return nil
+}
+
+// JsonEncryptedBytesOf json中以编码后密文表示的字节数组，使用类型参数P提供的加密配置
+type JsonEncryptedBytesOf[P CipherConfigProvider] []byte
+
+// MarshalJSON 实现json.Marshaler
+func (b JsonEncryptedBytesOf[P]) MarshalJSON() ([]byte, error) {
+	var provider P
+	return marshalJsonField(provider, b)
+}
+
+// UnmarshalJSON 实现json.Unmarshaler
+func (b *JsonEncryptedBytesOf[P]) UnmarshalJSON(data []byte) error {
+	var provider P
+	plaintext, err := unmarshalJsonField(provider, data)
+	if err != nil {
+		return err
+	}
+	*b = plaintext
+	return nil
+}
+
+// marshalJsonField 加密并序列化为json字符串
+func marshalJsonField(provider CipherConfigProvider, plaintext []byte) ([]byte, error) {
+	if len(plaintext) == 0 {
+		return []byte(`""`), nil
+	}
+	config, err := resolveCipherConfig(provider)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := config.encryptToString(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt json field failed: %w", err)
+	}
+	return json.Marshal(ciphertext)
+}
+
+// unmarshalJsonField 反序列化json字符串并解密
+func unmarshalJsonField(provider CipherConfigProvider, data []byte) ([]byte, error) {
+	var ciphertext *string
+	if err := json.Unmarshal(data, &ciphertext); err != nil {
+		return nil, fmt.Errorf("unmarshal json field failed: %w", err)
+	}
+	if ciphertext == nil || *ciphertext == "" {
+		return nil, nil
+	}
+	config, err := resolveCipherConfig(provider)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := config.decryptString(*ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt json field failed: %w", err)
+	}
+	return plaintext, nil
+}
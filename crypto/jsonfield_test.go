@@ -0,0 +1,123 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+)
+
+type testJsonUser struct {
+	Name   string              `json:"name"`
+	Phone  JsonEncryptedString `json:"phone"`
+	IdCard JsonEncryptedBytes  `json:"id_card"`
+	Email  JsonEncryptedString `json:"email"`
+}
+
+func Test_JsonEncryptedField(t *testing.T) {
+	provider := StaticKeyProvider{"api": []byte("0123456789abcdef")}
+	if err := SetJsonFieldConfig(CipherConfig{Cipher: CipherSm4Gcm, Encoding: "hex", Provider: provider, KeyID: "api"}); err != nil {
+		t.Fatalf("SetJsonFieldConfig() error = %v", err)
+	}
+	user := testJsonUser{Name: "tyanxie", Phone: "13800138000", IdCard: JsonEncryptedBytes("110105194912310021")}
+	data, err := json.Marshal(user)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var raw map[string]string
+	if err = json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if raw["name"] != "tyanxie" || raw["email"] != "" {
+		t.Errorf("json.Marshal() got = %s", data)
+	}
+	if _, err = hex.DecodeString(raw["phone"]); err != nil || raw["phone"] == string(user.Phone) {
+		t.Errorf("json.Marshal() got phone = %s, want hex ciphertext", raw["phone"])
+	}
+	// 客户端使用相同配置解密
+	ciphertext, _ := hex.DecodeString(raw["phone"])
+	key, _ := deriveCipherKey(provider, "api", CipherSm4Gcm)
+	if phone, err := Decrypt(CipherSm4Gcm, key, ciphertext, nil); err != nil || string(phone) != "13800138000" {
+		t.Errorf("Decrypt() got = %s, error = %v", phone, err)
+	}
+
+	var got testJsonUser
+	if err = json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if got.Name != user.Name || got.Phone != user.Phone || !bytes.Equal(got.IdCard, user.IdCard) || got.Email != "" {
+		t.Errorf("json.Unmarshal() got = %+v, want %+v", got, user)
+	}
+	if err = json.Unmarshal([]byte(`{"phone":null}`), &got); err != nil || got.Phone != "" {
+		t.Errorf("json.Unmarshal() null got = %s, error = %v", got.Phone, err)
+	}
+}
+
+func Test_JsonEncryptedFieldError(t *testing.T) {
+	provider := StaticKeyProvider{"api": []byte("0123456789abcdef")}
+	if err := SetJsonFieldConfig(CipherConfig{Cipher: CipherAesGcm, Provider: provider}); err != nil {
+		t.Fatalf("SetJsonFieldConfig() error = %v", err)
+	}
+	if _, err := json.Marshal(JsonEncryptedString("13800138000")); err == nil {
+		t.Errorf("json.Marshal() with missing key error = nil, wantErr true")
+	}
+	if err := SetJsonFieldConfig(CipherConfig{Cipher: CipherAesGcm, Provider: provider, KeyID: "api"}); err != nil {
+		t.Fatalf("SetJsonFieldConfig() error = %v", err)
+	}
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "type", data: `123`},
+		{name: "encoding", data: `"!!"`},
+		{name: "ciphertext", data: `"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s JsonEncryptedString
+			if err := json.Unmarshal([]byte(tt.data), &s); err == nil {
+				t.Errorf("json.Unmarshal() error = nil, wantErr true")
+			}
+		})
+	}
+	if err := SetJsonFieldConfig(CipherConfig{Cipher: "des-ecb", Provider: provider}); err == nil {
+		t.Errorf("SetJsonFieldConfig() with unsupported cipher error = nil, wantErr true")
+	}
+}
+
+type testJsonUserOf struct {
+	Phone  JsonEncryptedStringOf[testPhoneColumnConfig]  `json:"phone"`
+	IdCard JsonEncryptedBytesOf[testIdCardColumnConfig]  `json:"id_card"`
+	Email  JsonEncryptedStringOf[testIdCardColumnConfig] `json:"email"`
+}
+
+func Test_JsonEncryptedFieldOf(t *testing.T) {
+	user := testJsonUserOf{Phone: "13800138000", IdCard: JsonEncryptedBytesOf[testIdCardColumnConfig]("110105194912310021")}
+	data, err := json.Marshal(user)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var raw map[string]string
+	if err = json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	// 身份证字段使用hex编码，手机号字段使用默认的base64编码
+	if _, err = hex.DecodeString(raw["id_card"]); err != nil {
+		t.Errorf("id_card = %s, want hex ciphertext", raw["id_card"])
+	}
+	if raw["phone"] == "" || raw["phone"] == string(user.Phone) || raw["email"] != "" {
+		t.Errorf("raw = %v, want encrypted phone and empty email", raw)
+	}
+	var got testJsonUserOf
+	if err = json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if got.Phone != user.Phone || !bytes.Equal(got.IdCard, user.IdCard) || got.Email != "" {
+		t.Errorf("json.Unmarshal() got = %+v, want %+v", got, user)
+	}
+	// 使用其它字段的配置无法解密
+	var phone JsonEncryptedStringOf[testIdCardColumnConfig]
+	if err = json.Unmarshal([]byte(`"`+raw["phone"]+`"`), &phone); err == nil {
+		t.Errorf("json.Unmarshal() with other config error = nil, wantErr true")
+	}
+}
@@ -0,0 +1,296 @@
+// Package crypto jwe加密工具包
+package crypto
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha1" // RSA-OAEP使用SHA-1
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+/*
+JWE（RFC 7516）紧凑序列化：
+BASE64URL(header).BASE64URL(加密后的内容密钥).BASE64URL(iv).BASE64URL(密文).BASE64URL(认证标签)，
+内容加密时使用"BASE64URL(header)"的ascii字节作为附加认证数据。
+支持的密钥管理算法（alg）与密钥类型：
+RSA-OAEP:     RSAES-OAEP（SHA-1），加密时为*rsa.PublicKey，解密时为*rsa.PrivateKey
+RSA-OAEP-256: RSAES-OAEP（SHA-256），密钥类型同上
+A128KW:       AES-KW包装内容密钥，16字节[]byte密钥
+A256KW:       AES-KW包装内容密钥，32字节[]byte密钥
+dir:          直接使用[]byte密钥作为内容密钥，长度需要与内容加密算法一致
+SM2:          私有算法名称，sm2加密内容密钥（C1C3C2，asn.1编码），加密时为*sm2.PublicKey，解密时为*sm2.PrivateKey
+支持的内容加密算法（enc）：
+A128GCM: aes-gcm，16字节内容密钥
+A256GCM: aes-gcm，32字节内容密钥
+SM4GCM:  私有算法名称，sm4-gcm，16字节内容密钥
+以上内容加密算法均使用12字节iv与16字节认证标签。加密时也可以传入私钥，会使用其对应的公钥。
+*/
+
+// JWE密钥管理算法枚举
+const (
+	// JweRsaOaep RSAES-OAEP（SHA-1）
+	JweRsaOaep = "RSA-OAEP"
+	// JweRsaOaep256 RSAES-OAEP（SHA-256）
+	JweRsaOaep256 = "RSA-OAEP-256"
+	// JweA128Kw AES-128密钥包装
+	JweA128Kw = "A128KW"
+	// JweA256Kw AES-256密钥包装
+	JweA256Kw = "A256KW"
+	// JweDir 直接使用共享密钥作为内容密钥
+	JweDir = "dir"
+	// JweSm2 sm2加密内容密钥，私有算法名称
+	JweSm2 = "SM2"
+)
+
+// JWE内容加密算法枚举
+const (
+	// JweA128Gcm aes-128-gcm
+	JweA128Gcm = "A128GCM"
+	// JweA256Gcm aes-256-gcm
+	JweA256Gcm = "A256GCM"
+	// JweSm4Gcm sm4-gcm，私有算法名称
+	JweSm4Gcm = "SM4GCM"
+)
+
+// jweTagSize jwe内容加密算法的认证标签长度
+const jweTagSize = 16
+
+// jweEncryption jwe内容加密算法描述
+type jweEncryption struct {
+	keySize int // 内容密钥长度
+	ivSize  int // iv长度
+	encrypt func(key, nonce, plaintext, additionalData []byte) ([]byte, error)
+	decrypt func(key, nonce, ciphertext, additionalData []byte) ([]byte, error)
+}
+
+// jweEncryptionMap jwe内容加密算法映射
+var jweEncryptionMap = map[string]jweEncryption{
+	JweA128Gcm: {16, 12, AesGcmEncrypt, AesGcmDecrypt},
+	JweA256Gcm: {32, 12, AesGcmEncrypt, AesGcmDecrypt},
+	JweSm4Gcm:  {16, 12, Sm4GcmEncrypt, Sm4GcmDecrypt},
+}
+
+// jweKeyManagement jwe密钥管理算法描述
+type jweKeyManagement struct {
+	// wrap 生成或包装内容密钥，返回内容密钥与加密后的内容密钥
+	wrap func(key any, keySize int) (cek, encryptedKey []byte, err error)
+	// unwrap 解出内容密钥
+	unwrap func(key any, encryptedKey []byte, keySize int) ([]byte, error)
+}
+
+// jweKeyManagementMap jwe密钥管理算法映射
+var jweKeyManagementMap = map[string]jweKeyManagement{
+	JweRsaOaep:    jweRsaOaep(JweRsaOaep, crypto.SHA1),
+	JweRsaOaep256: jweRsaOaep(JweRsaOaep256, crypto.SHA256),
+	JweA128Kw:     jweAesKw(JweA128Kw, 16),
+	JweA256Kw:     jweAesKw(JweA256Kw, 32),
+	JweDir: {
+		wrap: func(key any, keySize int) ([]byte, []byte, error) {
+			k, ok := key.([]byte)
+			if !ok {
+				return nil, nil, jwsKeyTypeError(JweDir, key)
+			}
+			if len(k) != keySize {
+				return nil, nil, fmt.Errorf("key length must be %d", keySize)
+			}
+			return k, nil, nil
+		},
+		unwrap: func(key any, encryptedKey []byte, keySize int) ([]byte, error) {
+			k, ok := key.([]byte)
+			if !ok {
+				return nil, jwsKeyTypeError(JweDir, key)
+			}
+			if len(k) != keySize || len(encryptedKey) != 0 {
+				return nil, errors.New("invalid direct key")
+			}
+			return k, nil
+		},
+	},
+	JweSm2: {
+		wrap: func(key any, keySize int) ([]byte, []byte, error) {
+			k, ok := jwsPublicKey(key).(*sm2.PublicKey)
+			if !ok {
+				return nil, nil, jwsKeyTypeError(JweSm2, key)
+			}
+			cek, err := jweRandomKey(keySize)
+			if err != nil {
+				return nil, nil, err
+			}
+			encryptedKey, err := Sm2EncryptAsn1(k, cek, sm2.C1C3C2)
+			return cek, encryptedKey, err
+		},
+		unwrap: func(key any, encryptedKey []byte, keySize int) ([]byte, error) {
+			k, ok := key.(*sm2.PrivateKey)
+			if !ok {
+				return nil, jwsKeyTypeError(JweSm2, key)
+			}
+			return Sm2DecryptAsn1(k, encryptedKey, sm2.C1C3C2)
+		},
+	},
+}
+
+// JweEncrypt 生成jwe紧凑序列化字符串
+// @param header jwe header，Algorithm与Encryption必填
+// @param plaintext 明文
+// @param key 密钥，类型需要与密钥管理算法匹配
+func JweEncrypt(header JoseHeader, plaintext []byte, key any) (string, error) {
+	keyManagement, encryption, err := getJweAlgorithms(&header)
+	if err != nil {
+		return "", err
+	}
+	cek, encryptedKey, err := keyManagement.wrap(key, encryption.keySize)
+	if err != nil {
+		return "", fmt.Errorf("jwe wrap key failed: %w", err)
+	}
+	encodedHeader, err := encodeJoseHeader(&header)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, encryption.ivSize)
+	if _, err = rand.Read(iv); err != nil {
+		return "", fmt.Errorf("generate iv failed: %w", err)
+	}
+	sealed, err := encryption.encrypt(cek, iv, plaintext, []byte(encodedHeader))
+	if err != nil {
+		return "", fmt.Errorf("jwe encrypt failed: %w", err)
+	}
+	ciphertext, tag := sealed[:len(sealed)-jweTagSize], sealed[len(sealed)-jweTagSize:]
+	return strings.Join([]string{
+		encodedHeader,
+		base64.RawURLEncoding.EncodeToString(encryptedKey),
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(ciphertext),
+		base64.RawURLEncoding.EncodeToString(tag),
+	}, "."), nil
+}
+
+// JweDecrypt 解密jwe紧凑序列化字符串并返回明文与header
+// @param token jwe紧凑序列化字符串
+// @param key 密钥，类型需要与header中的密钥管理算法匹配
+func JweDecrypt(token string, key any) ([]byte, *JoseHeader, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return nil, nil, errors.New("invalid jwe format")
+	}
+	header, err := decodeJoseHeader(parts[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	keyManagement, encryption, err := getJweAlgorithms(header)
+	if err != nil {
+		return nil, nil, err
+	}
+	decoded := make([][]byte, 4)
+	for i, part := range parts[1:] {
+		if decoded[i], err = base64.RawURLEncoding.DecodeString(part); err != nil {
+			return nil, nil, fmt.Errorf("decode jwe part %d failed: %w", i+1, err)
+		}
+	}
+	encryptedKey, iv, ciphertext, tag := decoded[0], decoded[1], decoded[2], decoded[3]
+	if len(tag) != jweTagSize {
+		return nil, nil, errors.New("invalid jwe tag length")
+	}
+	cek, err := keyManagement.unwrap(key, encryptedKey, encryption.keySize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("jwe unwrap key failed: %w", err)
+	}
+	if len(cek) != encryption.keySize {
+		return nil, nil, errors.New("invalid content encryption key length")
+	}
+	plaintext, err := encryption.decrypt(cek, iv, append(ciphertext, tag...), []byte(parts[0]))
+	if err != nil {
+		return nil, nil, fmt.Errorf("jwe decrypt failed: %w", err)
+	}
+	return plaintext, header, nil
+}
+
+// getJweAlgorithms 根据header获取密钥管理算法与内容加密算法
+func getJweAlgorithms(header *JoseHeader) (jweKeyManagement, jweEncryption, error) {
+	keyManagement, ok := jweKeyManagementMap[header.Algorithm]
+	if !ok {
+		return jweKeyManagement{}, jweEncryption{}, fmt.Errorf("unsupported jwe algorithm: %s", header.Algorithm)
+	}
+	encryption, ok := jweEncryptionMap[header.Encryption]
+	if !ok {
+		return jweKeyManagement{}, jweEncryption{}, fmt.Errorf("unsupported jwe encryption: %s", header.Encryption)
+	}
+	return keyManagement, encryption, nil
+}
+
+// jweRsaOaep RSA-OAEP密钥管理算法
+func jweRsaOaep(algorithm string, hash crypto.Hash) jweKeyManagement {
+	return jweKeyManagement{
+		wrap: func(key any, keySize int) ([]byte, []byte, error) {
+			k, ok := jwsPublicKey(key).(*rsa.PublicKey)
+			if !ok {
+				return nil, nil, jwsKeyTypeError(algorithm, key)
+			}
+			cek, err := jweRandomKey(keySize)
+			if err != nil {
+				return nil, nil, err
+			}
+			encryptedKey, err := RsaEncryptOaep(k, cek, nil, hash)
+			return cek, encryptedKey, err
+		},
+		unwrap: func(key any, encryptedKey []byte, _ int) ([]byte, error) {
+			k, ok := key.(*rsa.PrivateKey)
+			if !ok {
+				return nil, jwsKeyTypeError(algorithm, key)
+			}
+			if len(encryptedKey) != k.Size() {
+				return nil, errors.New("invalid encrypted key length")
+			}
+			return RsaDecryptOaep(k, encryptedKey, nil, hash)
+		},
+	}
+}
+
+// jweAesKw AES-KW密钥管理算法
+func jweAesKw(algorithm string, kekSize int) jweKeyManagement {
+	kek := func(key any) ([]byte, error) {
+		k, ok := key.([]byte)
+		if !ok {
+			return nil, jwsKeyTypeError(algorithm, key)
+		}
+		if len(k) != kekSize {
+			return nil, fmt.Errorf("key length must be %d", kekSize)
+		}
+		return k, nil
+	}
+	return jweKeyManagement{
+		wrap: func(key any, keySize int) ([]byte, []byte, error) {
+			k, err := kek(key)
+			if err != nil {
+				return nil, nil, err
+			}
+			cek, err := jweRandomKey(keySize)
+			if err != nil {
+				return nil, nil, err
+			}
+			encryptedKey, err := AesKeyWrap(k, cek)
+			return cek, encryptedKey, err
+		},
+		unwrap: func(key any, encryptedKey []byte, _ int) ([]byte, error) {
+			k, err := kek(key)
+			if err != nil {
+				return nil, err
+			}
+			return AesKeyUnwrap(k, encryptedKey)
+		},
+	}
+}
+
+// jweRandomKey 生成随机内容密钥
+func jweRandomKey(size int) ([]byte, error) {
+	key := make([]byte, size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generate key failed: %w", err)
+	}
+	return key, nil
+}
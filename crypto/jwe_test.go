@@ -0,0 +1,117 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+func Test_JweEncryptDecrypt(t *testing.T) {
+	sm2Key, _ := sm2.GenerateKey(rand.Reader)
+	kek128, kek256 := bytes.Repeat([]byte{1}, 16), bytes.Repeat([]byte{2}, 32)
+	tests := []struct {
+		name       string
+		algorithm  string
+		encryption string
+		encryptKey any
+		decryptKey any
+	}{
+		{name: "rsa-oaep", algorithm: JweRsaOaep, encryption: JweA256Gcm,
+			encryptKey: &rsaPrivateKey.PublicKey, decryptKey: rsaPrivateKey},
+		{name: "rsa-oaep-256", algorithm: JweRsaOaep256, encryption: JweA128Gcm,
+			encryptKey: rsaPrivateKey, decryptKey: rsaPrivateKey},
+		{name: "a128kw", algorithm: JweA128Kw, encryption: JweSm4Gcm, encryptKey: kek128, decryptKey: kek128},
+		{name: "a256kw", algorithm: JweA256Kw, encryption: JweA256Gcm, encryptKey: kek256, decryptKey: kek256},
+		{name: "dir", algorithm: JweDir, encryption: JweA256Gcm, encryptKey: kek256, decryptKey: kek256},
+		{name: "dir-sm4", algorithm: JweDir, encryption: JweSm4Gcm, encryptKey: kek128, decryptKey: kek128},
+		{name: "sm2", algorithm: JweSm2, encryption: JweSm4Gcm, encryptKey: &sm2Key.PublicKey, decryptKey: sm2Key},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := JoseHeader{Algorithm: tt.algorithm, Encryption: tt.encryption, ContentType: "JWT"}
+			token, err := JweEncrypt(header, []byte("Hello World"), tt.encryptKey)
+			if err != nil {
+				t.Fatalf("JweEncrypt() error = %v", err)
+			}
+			parts := strings.Split(token, ".")
+			if len(parts) != 5 || (tt.algorithm == JweDir) != (parts[1] == "") {
+				t.Errorf("JweEncrypt() got = %s", token)
+			}
+			plaintext, got, err := JweDecrypt(token, tt.decryptKey)
+			if err != nil {
+				t.Fatalf("JweDecrypt() error = %v", err)
+			}
+			if string(plaintext) != "Hello World" || !reflect.DeepEqual(*got, header) {
+				t.Errorf("JweDecrypt() got = %s, header = %+v", plaintext, got)
+			}
+			// 修改header后附加认证数据不一致
+			tamperedHeader, _ := encodeJoseHeader(&JoseHeader{Algorithm: tt.algorithm, Encryption: tt.encryption})
+			parts[0] = tamperedHeader
+			if _, _, err = JweDecrypt(strings.Join(parts, "."), tt.decryptKey); err == nil {
+				t.Errorf("JweDecrypt() with tampered header error = nil, wantErr true")
+			}
+		})
+	}
+}
+
+func Test_JweA256KwContentKey(t *testing.T) {
+	kek := bytes.Repeat([]byte{2}, 32)
+	token, err := JweEncrypt(JoseHeader{Algorithm: JweA256Kw, Encryption: JweA256Gcm}, []byte("Hello World"), kek)
+	if err != nil {
+		t.Fatalf("JweEncrypt() error = %v", err)
+	}
+	// 内容密钥可以使用标准AES-KW解出，并直接用于解密内容
+	parts := strings.Split(token, ".")
+	decode := func(s string) []byte { b, _ := base64.RawURLEncoding.DecodeString(s); return b }
+	cek, err := AesKeyUnwrap(kek, decode(parts[1]))
+	if err != nil || len(cek) != 32 {
+		t.Fatalf("AesKeyUnwrap() got = %x, error = %v", cek, err)
+	}
+	plaintext, err := AesGcmDecrypt(cek, decode(parts[2]), append(decode(parts[3]), decode(parts[4])...), []byte(parts[0]))
+	if err != nil || string(plaintext) != "Hello World" {
+		t.Errorf("AesGcmDecrypt() got = %s, error = %v", plaintext, err)
+	}
+}
+
+func Test_JweError(t *testing.T) {
+	kek := bytes.Repeat([]byte{2}, 32)
+	token, _ := JweEncrypt(JoseHeader{Algorithm: JweA256Kw, Encryption: JweA256Gcm}, []byte("Hello World"), kek)
+	parts := strings.Split(token, ".")
+	tag := []byte(parts[4])
+	tag[0] ^= 1
+	tamperedTag := strings.Join(append(parts[:4:4], string(tag)), ".")
+	tests := []struct {
+		name  string
+		token string
+		key   any
+	}{
+		{name: "format", token: "a.b.c", key: kek},
+		{name: "kek", token: token, key: bytes.Repeat([]byte{3}, 32)},
+		{name: "kek-length", token: token, key: kek[:16]},
+		{name: "key-type", token: token, key: rsaPrivateKey},
+		{name: "tag", token: tamperedTag, key: kek},
+		{name: "base64", token: strings.Replace(token, ".", ".!", 1), key: kek},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := JweDecrypt(tt.token, tt.key); err == nil {
+				t.Errorf("JweDecrypt() error = nil, wantErr true")
+			}
+		})
+	}
+	invalidHeaders := []JoseHeader{
+		{Algorithm: "ECDH-ES", Encryption: JweA256Gcm},
+		{Algorithm: JweDir, Encryption: "A128CBC-HS256"},
+		{Algorithm: JweDir, Encryption: JweA128Gcm},
+	}
+	for _, header := range invalidHeaders {
+		if _, err := JweEncrypt(header, []byte("Hello World"), kek); err == nil {
+			t.Errorf("JweEncrypt(%+v) error = nil, wantErr true", header)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+// Package crypto jws签名工具包
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+/*
+JWS（RFC 7515）紧凑序列化：BASE64URL(header).BASE64URL(payload).BASE64URL(signature)，
+其中base64url不带填充，签名内容为"BASE64URL(header).BASE64URL(payload)"的ascii字节。
+支持的签名算法与密钥类型：
+HS256:  HMAC-SHA256，[]byte密钥，长度至少32字节
+RS256:  RSASSA-PKCS1-v1_5 + SHA-256，*rsa.PrivateKey/*rsa.PublicKey
+PS256:  RSASSA-PSS + SHA-256，*rsa.PrivateKey/*rsa.PublicKey
+ES256:  ECDSA P-256 + SHA-256，签名为32字节R||32字节S，*ecdsa.PrivateKey/*ecdsa.PublicKey
+EdDSA:  Ed25519，ed25519.PrivateKey/ed25519.PublicKey
+SM2SM3: 私有算法名称，sm2签名（sm3摘要、默认用户id），签名格式与ES256一致为R||S，*sm2.PrivateKey/*sm2.PublicKey
+验签时也可以直接传入私钥，会使用其对应的公钥。验签时签名算法以header中的alg为准，
+但算法与密钥类型必须匹配，因此不会出现使用rsa公钥作为HMAC密钥的算法混淆问题，"none"算法不受支持。
+*/
+
+// JWS签名算法枚举
+const (
+	// JwsHs256 HMAC-SHA256
+	JwsHs256 = "HS256"
+	// JwsRs256 RSASSA-PKCS1-v1_5 + SHA-256
+	JwsRs256 = "RS256"
+	// JwsPs256 RSASSA-PSS + SHA-256
+	JwsPs256 = "PS256"
+	// JwsEs256 ECDSA P-256 + SHA-256
+	JwsEs256 = "ES256"
+	// JwsEdDsa Ed25519
+	JwsEdDsa = "EdDSA"
+	// JwsSm2Sm3 sm2签名（sm3摘要），私有算法名称
+	JwsSm2Sm3 = "SM2SM3"
+)
+
+// jwsHmacMinKeyLength HS256要求的最短密钥长度
+const jwsHmacMinKeyLength = 32
+
+// JoseHeader jws与jwe的header
+type JoseHeader struct {
+	Algorithm   string   `json:"alg"`            // 签名算法或密钥管理算法
+	Encryption  string   `json:"enc,omitempty"`  // 内容加密算法，仅jwe使用
+	Type        string   `json:"typ,omitempty"`  // 类型，例如JWT
+	ContentType string   `json:"cty,omitempty"`  // 内容类型
+	KeyID       string   `json:"kid,omitempty"`  // 密钥id
+	Critical    []string `json:"crit,omitempty"` // 必须理解的扩展header，不支持任何扩展，非空时解析失败
+}
+
+// jwsAlgorithm jws签名算法描述
+type jwsAlgorithm struct {
+	sign   func(key any, input []byte) ([]byte, error)
+	verify func(key any, input, signature []byte) error
+}
+
+// jwsAlgorithmMap jws签名算法映射
+var jwsAlgorithmMap = map[string]jwsAlgorithm{
+	JwsHs256: {
+		sign: func(key any, input []byte) ([]byte, error) {
+			k, err := jwsHmacKey(key)
+			if err != nil {
+				return nil, err
+			}
+			return HmacSum(HashSha256, k, input)
+		},
+		verify: func(key any, input, signature []byte) error {
+			k, err := jwsHmacKey(key)
+			if err != nil {
+				return err
+			}
+			expected, err := HmacSum(HashSha256, k, input)
+			if err != nil {
+				return err
+			}
+			if !hmac.Equal(expected, signature) {
+				return errors.New("verify failed")
+			}
+			return nil
+		},
+	},
+	JwsRs256: {
+		sign: func(key any, input []byte) ([]byte, error) {
+			k, ok := key.(*rsa.PrivateKey)
+			if !ok {
+				return nil, jwsKeyTypeError(JwsRs256, key)
+			}
+			return RsaSignPkcs1v15(k, input, crypto.SHA256)
+		},
+		verify: func(key any, input, signature []byte) error {
+			k, ok := jwsPublicKey(key).(*rsa.PublicKey)
+			if !ok {
+				return jwsKeyTypeError(JwsRs256, key)
+			}
+			return RsaVerifyPkcs1v15(k, input, signature, crypto.SHA256)
+		},
+	},
+	JwsPs256: {
+		sign: func(key any, input []byte) ([]byte, error) {
+			k, ok := key.(*rsa.PrivateKey)
+			if !ok {
+				return nil, jwsKeyTypeError(JwsPs256, key)
+			}
+			return RsaSignPss(k, input, crypto.SHA256)
+		},
+		verify: func(key any, input, signature []byte) error {
+			k, ok := jwsPublicKey(key).(*rsa.PublicKey)
+			if !ok {
+				return jwsKeyTypeError(JwsPs256, key)
+			}
+			return RsaVerifyPss(k, input, signature, crypto.SHA256)
+		},
+	},
+	JwsEs256: {
+		sign: func(key any, input []byte) ([]byte, error) {
+			k, ok := key.(*ecdsa.PrivateKey)
+			if !ok || k.Curve != elliptic.P256() {
+				return nil, jwsKeyTypeError(JwsEs256, key)
+			}
+			signature, err := EcdsaSign(k, input)
+			if err != nil {
+				return nil, err
+			}
+			return asn1SignatureToRaw(signature, 32)
+		},
+		verify: func(key any, input, signature []byte) error {
+			k, ok := jwsPublicKey(key).(*ecdsa.PublicKey)
+			if !ok || k.Curve != elliptic.P256() {
+				return jwsKeyTypeError(JwsEs256, key)
+			}
+			der, err := rawSignatureToAsn1(signature, 32)
+			if err != nil {
+				return err
+			}
+			return EcdsaVerify(k, input, der)
+		},
+	},
+	JwsEdDsa: {
+		sign: func(key any, input []byte) ([]byte, error) {
+			k, ok := key.(ed25519.PrivateKey)
+			if !ok {
+				return nil, jwsKeyTypeError(JwsEdDsa, key)
+			}
+			return Ed25519Sign(k, input)
+		},
+		verify: func(key any, input, signature []byte) error {
+			k, ok := jwsPublicKey(key).(ed25519.PublicKey)
+			if !ok {
+				return jwsKeyTypeError(JwsEdDsa, key)
+			}
+			return Ed25519Verify(k, input, signature)
+		},
+	},
+	JwsSm2Sm3: {
+		sign: func(key any, input []byte) ([]byte, error) {
+			k, ok := key.(*sm2.PrivateKey)
+			if !ok {
+				return nil, jwsKeyTypeError(JwsSm2Sm3, key)
+			}
+			signature, err := Sm2Sign(k, input)
+			if err != nil {
+				return nil, err
+			}
+			return asn1SignatureToRaw(signature, 32)
+		},
+		verify: func(key any, input, signature []byte) error {
+			k, ok := jwsPublicKey(key).(*sm2.PublicKey)
+			if !ok {
+				return jwsKeyTypeError(JwsSm2Sm3, key)
+			}
+			der, err := rawSignatureToAsn1(signature, 32)
+			if err != nil {
+				return err
+			}
+			return Sm2Verify(k, input, der)
+		},
+	},
+}
+
+// JwsSign 生成jws紧凑序列化字符串
+// @param header jws header，Algorithm必填
+// @param payload 载荷
+// @param key 签名密钥，类型需要与签名算法匹配
+func JwsSign(header JoseHeader, payload []byte, key any) (string, error) {
+	algorithm, ok := jwsAlgorithmMap[header.Algorithm]
+	if !ok {
+		return "", fmt.Errorf("unsupported jws algorithm: %s", header.Algorithm)
+	}
+	encodedHeader, err := encodeJoseHeader(&header)
+	if err != nil {
+		return "", err
+	}
+	input := encodedHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	signature, err := algorithm.sign(key, []byte(input))
+	if err != nil {
+		return "", fmt.Errorf("jws sign failed: %w", err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// JwsVerify 验证jws紧凑序列化字符串并返回载荷与header
+// @param token jws紧凑序列化字符串
+// @param key 验签密钥，类型需要与header中的签名算法匹配
+func JwsVerify(token string, key any) ([]byte, *JoseHeader, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, errors.New("invalid jws format")
+	}
+	header, err := decodeJoseHeader(parts[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	algorithm, ok := jwsAlgorithmMap[header.Algorithm]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported jws algorithm: %s", header.Algorithm)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, fmt.Errorf("decode signature failed: %w", err)
+	}
+	if err = algorithm.verify(key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, nil, fmt.Errorf("jws verify failed: %w", err)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("decode payload failed: %w", err)
+	}
+	return payload, header, nil
+}
+
+// JoseHeaderOf 解析jws或jwe的header但不做任何校验，用于在验签或解密前根据kid选择密钥
+// @param token jws或jwe紧凑序列化字符串
+func JoseHeaderOf(token string) (*JoseHeader, error) {
+	encodedHeader, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, errors.New("invalid jose format")
+	}
+	return decodeJoseHeader(encodedHeader)
+}
+
+// encodeJoseHeader 序列化并编码header
+func encodeJoseHeader(header *JoseHeader) (string, error) {
+	data, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("marshal header failed: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeJoseHeader 解码并反序列化header
+func decodeJoseHeader(encodedHeader string) (*JoseHeader, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encodedHeader)
+	if err != nil {
+		return nil, fmt.Errorf("decode header failed: %w", err)
+	}
+	header := &JoseHeader{}
+	if err = json.Unmarshal(data, header); err != nil {
+		return nil, fmt.Errorf("unmarshal header failed: %w", err)
+	}
+	if len(header.Critical) > 0 {
+		return nil, fmt.Errorf("unsupported critical header: %v", header.Critical)
+	}
+	return header, nil
+}
+
+// jwsHmacKey 获取HMAC密钥
+func jwsHmacKey(key any) ([]byte, error) {
+	k, ok := key.([]byte)
+	if !ok {
+		return nil, jwsKeyTypeError(JwsHs256, key)
+	}
+	if len(k) < jwsHmacMinKeyLength {
+		return nil, fmt.Errorf("hmac key length must be at least %d", jwsHmacMinKeyLength)
+	}
+	return k, nil
+}
+
+// jwsPublicKey 传入私钥时返回对应的公钥，否则原样返回
+func jwsPublicKey(key any) any {
+	if k, ok := key.(interface{ Public() crypto.PublicKey }); ok {
+		return k.Public()
+	}
+	return key
+}
+
+// jwsKeyTypeError 密钥类型与算法不匹配的错误
+func jwsKeyTypeError(algorithm string, key any) error {
+	return fmt.Errorf("invalid key type %T for %s", key, algorithm)
+}
+
+// ecSignature asn.1编码的椭圆曲线签名
+type ecSignature struct {
+	R, S *big.Int
+}
+
+// asn1SignatureToRaw 将asn.1编码的签名转换为定长的R||S
+func asn1SignatureToRaw(signature []byte, size int) ([]byte, error) {
+	var sig ecSignature
+	if rest, err := asn1.Unmarshal(signature, &sig); err != nil || len(rest) > 0 {
+		return nil, errors.New("invalid asn.1 signature")
+	}
+	if sig.R.Sign() <= 0 || sig.S.Sign() <= 0 || sig.R.BitLen() > size*8 || sig.S.BitLen() > size*8 {
+		return nil, errors.New("invalid asn.1 signature")
+	}
+	raw := make([]byte, 2*size)
+	sig.R.FillBytes(raw[:size])
+	sig.S.FillBytes(raw[size:])
+	return raw, nil
+}
+
+// rawSignatureToAsn1 将定长的R||S转换为asn.1编码的签名
+func rawSignatureToAsn1(signature []byte, size int) ([]byte, error) {
+	if len(signature) != 2*size {
+		return nil, fmt.Errorf("signature length must be %d", 2*size)
+	}
+	der, err := asn1.Marshal(ecSignature{
+		R: new(big.Int).SetBytes(signature[:size]),
+		S: new(big.Int).SetBytes(signature[size:]),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal signature failed: %w", err)
+	}
+	return der, nil
+}
@@ -0,0 +1,136 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+func Test_JwsVerifyRfc7515(t *testing.T) {
+	// RFC 7515 附录A.1测试向量
+	key, _ := base64.RawURLEncoding.DecodeString("AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow")
+	token := "eyJ0eXAiOiJKV1QiLA0KICJhbGciOiJIUzI1NiJ9" +
+		".eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ" +
+		".dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	payload, header, err := JwsVerify(token, key)
+	if err != nil {
+		t.Fatalf("JwsVerify() error = %v", err)
+	}
+	if header.Algorithm != JwsHs256 || header.Type != "JWT" {
+		t.Errorf("JwsVerify() got header = %+v", header)
+	}
+	if want := "{\"iss\":\"joe\",\r\n \"exp\":1300819380,\r\n \"http://example.com/is_root\":true}"; string(payload) != want {
+		t.Errorf("JwsVerify() got payload = %q, want %q", payload, want)
+	}
+}
+
+func Test_JwsSignVerify(t *testing.T) {
+	ecdsaKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, ed25519Key, _ := ed25519.GenerateKey(rand.Reader)
+	sm2Key, _ := sm2.GenerateKey(rand.Reader)
+	hmacKey := []byte("0123456789abcdef0123456789abcdef")
+	tests := []struct {
+		name       string
+		algorithm  string
+		signKey    any
+		verifyKey  any
+		signLength int
+	}{
+		{name: "hs256", algorithm: JwsHs256, signKey: hmacKey, verifyKey: hmacKey, signLength: 32},
+		{name: "rs256", algorithm: JwsRs256, signKey: rsaPrivateKey, verifyKey: &rsaPrivateKey.PublicKey, signLength: 256},
+		{name: "ps256", algorithm: JwsPs256, signKey: rsaPrivateKey, verifyKey: rsaPrivateKey, signLength: 256},
+		{name: "es256", algorithm: JwsEs256, signKey: ecdsaKey, verifyKey: &ecdsaKey.PublicKey, signLength: 64},
+		{name: "eddsa", algorithm: JwsEdDsa, signKey: ed25519Key, verifyKey: ed25519Key.Public(), signLength: 64},
+		{name: "sm2sm3", algorithm: JwsSm2Sm3, signKey: sm2Key, verifyKey: &sm2Key.PublicKey, signLength: 64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := JoseHeader{Algorithm: tt.algorithm, Type: "JWT", KeyID: "key-1"}
+			token, err := JwsSign(header, []byte(`{"sub":"tyanxie"}`), tt.signKey)
+			if err != nil {
+				t.Fatalf("JwsSign() error = %v", err)
+			}
+			parts := strings.Split(token, ".")
+			if signature, _ := base64.RawURLEncoding.DecodeString(parts[2]); len(signature) != tt.signLength {
+				t.Errorf("JwsSign() got signature length = %d, want %d", len(signature), tt.signLength)
+			}
+			payload, got, err := JwsVerify(token, tt.verifyKey)
+			if err != nil {
+				t.Fatalf("JwsVerify() error = %v", err)
+			}
+			if string(payload) != `{"sub":"tyanxie"}` || !reflect.DeepEqual(*got, header) {
+				t.Errorf("JwsVerify() got payload = %s, header = %+v", payload, got)
+			}
+			if kidHeader, err := JoseHeaderOf(token); err != nil || kidHeader.KeyID != "key-1" {
+				t.Errorf("JoseHeaderOf() got = %+v, error = %v", kidHeader, err)
+			}
+			tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin"}`)) + "." + parts[2]
+			if _, _, err = JwsVerify(tampered, tt.verifyKey); err == nil {
+				t.Errorf("JwsVerify() with tampered payload error = nil, wantErr true")
+			}
+		})
+	}
+}
+
+func Test_JwsEs256RawSignature(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	token, err := JwsSign(JoseHeader{Algorithm: JwsEs256}, []byte("payload"), key)
+	if err != nil {
+		t.Fatalf("JwsSign() error = %v", err)
+	}
+	// 签名为R||S，可以直接使用标准库校验
+	parts := strings.Split(token, ".")
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+	if !ecdsa.Verify(&key.PublicKey, digest[:], r, s) {
+		t.Errorf("ecdsa.Verify() got = false, want true")
+	}
+}
+
+func Test_JwsVerifyError(t *testing.T) {
+	hmacKey := []byte("0123456789abcdef0123456789abcdef")
+	token, _ := JwsSign(JoseHeader{Algorithm: JwsHs256}, []byte("payload"), hmacKey)
+	rsaToken, _ := JwsSign(JoseHeader{Algorithm: JwsRs256}, []byte("payload"), rsaPrivateKey)
+	p384Key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	tests := []struct {
+		name  string
+		token string
+		key   any
+	}{
+		{name: "format", token: "a.b", key: hmacKey},
+		{name: "none", token: encode(`{"alg":"none"}`) + "." + encode("payload") + ".", key: hmacKey},
+		{name: "crit", token: encode(`{"alg":"HS256","crit":["exp"]}`) + "." + encode("payload") + ".", key: hmacKey},
+		{name: "header", token: "!!." + encode("payload") + ".", key: hmacKey},
+		{name: "key", token: token, key: []byte("0123456789abcdef0123456789abcdeF")},
+		{name: "short-key", token: token, key: []byte("short")},
+		// 使用rsa公钥作为HMAC密钥的算法混淆
+		{name: "confusion", token: token, key: &rsaPrivateKey.PublicKey},
+		{name: "rsa-hmac-key", token: rsaToken, key: hmacKey},
+		{name: "signature", token: token[:len(token)-2] + "!!", key: hmacKey},
+		{name: "curve", token: token, key: p384Key},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := JwsVerify(tt.token, tt.key); err == nil {
+				t.Errorf("JwsVerify() error = nil, wantErr true")
+			}
+		})
+	}
+	if _, err := JwsSign(JoseHeader{Algorithm: JwsEs256}, nil, p384Key); err == nil {
+		t.Errorf("JwsSign() with P-384 key error = nil, wantErr true")
+	}
+	if _, err := JwsSign(JoseHeader{Algorithm: "none"}, nil, nil); err == nil {
+		t.Errorf("JwsSign() with none algorithm error = nil, wantErr true")
+	}
+}
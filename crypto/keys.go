@@ -0,0 +1,51 @@
+// Package crypto 密钥管理工具包
+package crypto
+
+import (
+	"crypto/hkdf"
+	"errors"
+	"fmt"
+)
+
+/*
+KeyProvider统一了加密、盲索引等功能获取密钥的方式，业务方可以基于配置中心、kms等实现该接口。
+同一个主密钥用于不同用途时，应当通过DeriveKey使用不同的info派生出互不相关的子密钥，
+避免例如加密密钥与盲索引密钥相同而带来的风险。
+*/
+
+// ErrKeyNotFound 密钥不存在
+var ErrKeyNotFound = errors.New("key not found")
+
+// KeyProvider 密钥提供者
+type KeyProvider interface {
+	// Key 根据密钥id获取密钥，密钥不存在时返回ErrKeyNotFound
+	Key(keyID string) ([]byte, error)
+}
+
+// StaticKeyProvider 基于内存map的密钥提供者，key为密钥id
+type StaticKeyProvider map[string][]byte
+
+// Key 根据密钥id获取密钥
+func (p StaticKeyProvider) Key(keyID string) ([]byte, error) {
+	if key, ok := p[keyID]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+}
+
+// DeriveKey 使用HKDF从主密钥派生子密钥
+// @param hashName 哈希算法，HashSha256或HashSm3
+// @param masterKey 主密钥
+// @param info 用途标识，不同用途需要使用不同的info
+// @param length 子密钥长度
+func DeriveKey(hashName string, masterKey []byte, info string, length int) ([]byte, error) {
+	h, err := newHash(hashName)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hkdf.Key(h, masterKey, nil, info, length)
+	if err != nil {
+		return nil, fmt.Errorf("derive key failed: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,48 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func Test_StaticKeyProvider(t *testing.T) {
+	provider := StaticKeyProvider{"default": []byte("1234567890123456")}
+	key, err := provider.Key("default")
+	if err != nil || !bytes.Equal(key, []byte("1234567890123456")) {
+		t.Errorf("Key() got = %v, error = %v", key, err)
+	}
+	if _, err := provider.Key("unknown"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Key() error = %v, want %v", err, ErrKeyNotFound)
+	}
+}
+
+func Test_DeriveKey(t *testing.T) {
+	// RFC 5869 测试用例3（salt与info为空）
+	ikm := mustHex("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b")
+	want := mustHex("8da4e775a563c18f715f802a063c5a31b8a11f5c5ee1879ec3454e5f3c738d2d9d201395faa4b61a96c8")
+	got, err := DeriveKey(HashSha256, ikm, "", 42)
+	if err != nil {
+		t.Fatalf("DeriveKey() error = %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("DeriveKey() got = %x, want %x", got, want)
+	}
+	k1, err := DeriveKey(HashSm3, ikm, "encrypt", 16)
+	if err != nil {
+		t.Fatalf("DeriveKey() error = %v", err)
+	}
+	k2, err := DeriveKey(HashSm3, ikm, "index", 16)
+	if err != nil {
+		t.Fatalf("DeriveKey() error = %v", err)
+	}
+	if bytes.Equal(k1, k2) {
+		t.Errorf("DeriveKey() with different info got same key")
+	}
+	if _, err := DeriveKey("md5", ikm, "", 16); err == nil {
+		t.Errorf("DeriveKey() with unsupported hash error = nil, wantErr true")
+	}
+	if _, err := DeriveKey(OtpHashSha1, ikm, "", 16); err == nil {
+		t.Errorf("DeriveKey() with unsupported hash error = nil, wantErr true")
+	}
+}
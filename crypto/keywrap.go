@@ -0,0 +1,219 @@
+// Package crypto 密钥包装工具包
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+/*
+密钥包装用于使用主密钥（KEK）加密存储数据密钥，包装结果自带完整性校验，可以与HSM及云厂商KMS导出的密钥互通。
+AES-KW（RFC 3394 / NIST SP 800-38F KW）：被包装的密钥长度必须为8的倍数且至少16字节，包装结果比原密钥长8字节。
+AES-KWP（RFC 5649 / NIST SP 800-38F KWP）：被包装的密钥可以为任意非空长度，包装结果为8的倍数且至少16字节。
+SM4-KW与SM4-KWP：分别将上述两种结构中的aes替换为sm4，用于国密场景，KEK长度为16字节。
+*/
+
+// keyWrapDefaultIV RFC 3394默认初始值
+var keyWrapDefaultIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// keyWrapPadIV RFC 5649替代初始值的前4字节，后4字节为被包装密钥的长度
+var keyWrapPadIV = []byte{0xa6, 0x59, 0x59, 0xa6}
+
+// ErrKeyWrapIntegrity 密钥解包完整性校验失败，包装结果被篡改或KEK不匹配
+var ErrKeyWrapIntegrity = errors.New("key wrap integrity check failed")
+
+// AesKeyWrap AES-KW密钥包装
+// @param kek 16、24或32字节密钥加密密钥
+// @param key 被包装的密钥，长度为8的倍数且至少16字节
+func AesKeyWrap(kek, key []byte) ([]byte, error) {
+	return keyWrap(aes.NewCipher, kek, key)
+}
+
+// AesKeyUnwrap AES-KW密钥解包
+// @param kek 16、24或32字节密钥加密密钥
+// @param wrapped 包装结果
+func AesKeyUnwrap(kek, wrapped []byte) ([]byte, error) {
+	return keyUnwrap(aes.NewCipher, kek, wrapped)
+}
+
+// AesKeyWrapPad AES-KWP带填充的密钥包装
+// @param kek 16、24或32字节密钥加密密钥
+// @param key 被包装的密钥，任意非空长度
+func AesKeyWrapPad(kek, key []byte) ([]byte, error) {
+	return keyWrapPad(aes.NewCipher, kek, key)
+}
+
+// AesKeyUnwrapPad AES-KWP带填充的密钥解包
+// @param kek 16、24或32字节密钥加密密钥
+// @param wrapped 包装结果
+func AesKeyUnwrapPad(kek, wrapped []byte) ([]byte, error) {
+	return keyUnwrapPad(aes.NewCipher, kek, wrapped)
+}
+
+// Sm4KeyWrap SM4-KW密钥包装
+// @param kek 16字节密钥加密密钥
+// @param key 被包装的密钥，长度为8的倍数且至少16字节
+func Sm4KeyWrap(kek, key []byte) ([]byte, error) {
+	return keyWrap(NewSm4Cipher, kek, key)
+}
+
+// Sm4KeyUnwrap SM4-KW密钥解包
+// @param kek 16字节密钥加密密钥
+// @param wrapped 包装结果
+func Sm4KeyUnwrap(kek, wrapped []byte) ([]byte, error) {
+	return keyUnwrap(NewSm4Cipher, kek, wrapped)
+}
+
+// Sm4KeyWrapPad SM4-KWP带填充的密钥包装
+// @param kek 16字节密钥加密密钥
+// @param key 被包装的密钥，任意非空长度
+func Sm4KeyWrapPad(kek, key []byte) ([]byte, error) {
+	return keyWrapPad(NewSm4Cipher, kek, key)
+}
+
+// Sm4KeyUnwrapPad SM4-KWP带填充的密钥解包
+// @param kek 16字节密钥加密密钥
+// @param wrapped 包装结果
+func Sm4KeyUnwrapPad(kek, wrapped []byte) ([]byte, error) {
+	return keyUnwrapPad(NewSm4Cipher, kek, wrapped)
+}
+
+// keyWrap KW包装
+func keyWrap(newCipher func(key []byte) (cipher.Block, error), kek, key []byte) ([]byte, error) {
+	if len(key) < 16 || len(key)%8 != 0 {
+		return nil, errors.New("key length must be a multiple of 8 and at least 16")
+	}
+	block, err := newKeyWrapBlock(newCipher, kek)
+	if err != nil {
+		return nil, err
+	}
+	return keyWrapW(block, keyWrapDefaultIV, key), nil
+}
+
+// keyUnwrap KW解包
+func keyUnwrap(newCipher func(key []byte) (cipher.Block, error), kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 24 || len(wrapped)%8 != 0 {
+		return nil, errors.New("wrapped key length must be a multiple of 8 and at least 24")
+	}
+	block, err := newKeyWrapBlock(newCipher, kek)
+	if err != nil {
+		return nil, err
+	}
+	iv, key := keyUnwrapW(block, wrapped)
+	if subtle.ConstantTimeCompare(iv, keyWrapDefaultIV) != 1 {
+		return nil, ErrKeyWrapIntegrity
+	}
+	return key, nil
+}
+
+// keyWrapPad KWP包装
+func keyWrapPad(newCipher func(key []byte) (cipher.Block, error), kek, key []byte) ([]byte, error) {
+	if len(key) == 0 || uint64(len(key)) > 1<<32-1 {
+		return nil, errors.New("key length must be between 1 and 2^32-1")
+	}
+	block, err := newKeyWrapBlock(newCipher, kek)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, 8)
+	copy(iv, keyWrapPadIV)
+	binary.BigEndian.PutUint32(iv[4:], uint32(len(key)))
+	padded := make([]byte, (len(key)+7)/8*8)
+	copy(padded, key)
+	if len(padded) == 8 {
+		// 只有一个半分组时直接使用一次ecb加密
+		wrapped := append(iv, padded...)
+		block.Encrypt(wrapped, wrapped)
+		return wrapped, nil
+	}
+	return keyWrapW(block, iv, padded), nil
+}
+
+// keyUnwrapPad KWP解包
+func keyUnwrapPad(newCipher func(key []byte) (cipher.Block, error), kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 16 || len(wrapped)%8 != 0 {
+		return nil, errors.New("wrapped key length must be a multiple of 8 and at least 16")
+	}
+	block, err := newKeyWrapBlock(newCipher, kek)
+	if err != nil {
+		return nil, err
+	}
+	var iv, padded []byte
+	if len(wrapped) == 16 {
+		buf := make([]byte, 16)
+		block.Decrypt(buf, wrapped)
+		iv, padded = buf[:8], buf[8:]
+	} else {
+		iv, padded = keyUnwrapW(block, wrapped)
+	}
+	if subtle.ConstantTimeCompare(iv[:4], keyWrapPadIV) != 1 {
+		return nil, ErrKeyWrapIntegrity
+	}
+	length := int(binary.BigEndian.Uint32(iv[4:]))
+	if length <= len(padded)-8 || length > len(padded) {
+		return nil, ErrKeyWrapIntegrity
+	}
+	var nonZero byte
+	for _, b := range padded[length:] {
+		nonZero |= b
+	}
+	if nonZero != 0 {
+		return nil, ErrKeyWrapIntegrity
+	}
+	return padded[:length], nil
+}
+
+// newKeyWrapBlock 创建密钥包装使用的分组密码，分组长度必须为16字节
+func newKeyWrapBlock(newCipher func(key []byte) (cipher.Block, error), kek []byte) (cipher.Block, error) {
+	block, err := newCipher(kek)
+	if err != nil {
+		return nil, fmt.Errorf("create block failed: %w", err)
+	}
+	if block.BlockSize() != 16 {
+		return nil, errors.New("key wrap requires a 128-bit block cipher")
+	}
+	return block, nil
+}
+
+// keyWrapW RFC 3394中的包装函数W，plaintext长度为8的倍数且至少16字节
+func keyWrapW(block cipher.Block, iv, plaintext []byte) []byte {
+	n := len(plaintext) / 8
+	out := make([]byte, 8+len(plaintext))
+	copy(out, iv)
+	copy(out[8:], plaintext)
+	buf := make([]byte, 16)
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(buf, out[:8])
+			copy(buf[8:], out[i*8:i*8+8])
+			block.Encrypt(buf, buf)
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(out[:8], binary.BigEndian.Uint64(buf[:8])^t)
+			copy(out[i*8:], buf[8:])
+		}
+	}
+	return out
+}
+
+// keyUnwrapW RFC 3394中的解包函数W^-1，返回初始值与解包结果，由调用方校验初始值
+func keyUnwrapW(block cipher.Block, ciphertext []byte) (iv, plaintext []byte) {
+	n := len(ciphertext)/8 - 1
+	out := make([]byte, len(ciphertext))
+	copy(out, ciphertext)
+	buf := make([]byte, 16)
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(buf[:8], binary.BigEndian.Uint64(out[:8])^t)
+			copy(buf[8:], out[i*8:i*8+8])
+			block.Decrypt(buf, buf)
+			copy(out[:8], buf[:8])
+			copy(out[i*8:], buf[8:])
+		}
+	}
+	return out[:8], out[8:]
+}
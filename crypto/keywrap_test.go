@@ -0,0 +1,183 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func Test_AesKeyWrap(t *testing.T) {
+	tests := []struct {
+		name    string
+		kek     string
+		key     string
+		wrapped string
+	}{
+		// RFC 3394 第4节测试向量
+		{
+			name:    "128-128",
+			kek:     "000102030405060708090a0b0c0d0e0f",
+			key:     "00112233445566778899aabbccddeeff",
+			wrapped: "1fa68b0a8112b447aef34bd8fb5a7b829d3e862371d2cfe5",
+		},
+		{
+			name:    "192-128",
+			kek:     "000102030405060708090a0b0c0d0e0f1011121314151617",
+			key:     "00112233445566778899aabbccddeeff",
+			wrapped: "96778b25ae6ca435f92b5b97c050aed2468ab8a17ad84e5d",
+		},
+		{
+			name:    "256-128",
+			kek:     "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			key:     "00112233445566778899aabbccddeeff",
+			wrapped: "64e8c3f9ce0f5ba263e9777905818a2a93c8191e7d6e8ae7",
+		},
+		{
+			name:    "256-256",
+			kek:     "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			key:     "00112233445566778899aabbccddeeff000102030405060708090a0b0c0d0e0f",
+			wrapped: "28c9f404c4b810f4cbccb35cfb87f8263f5786e2d80ed326cbc7f0e71a99f43bfb988b9b7a02dd21",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kek, key, wrapped := mustHex(tt.kek), mustHex(tt.key), mustHex(tt.wrapped)
+			got, err := AesKeyWrap(kek, key)
+			if err != nil {
+				t.Errorf("AesKeyWrap() error = %v", err)
+				return
+			}
+			if !bytes.Equal(got, wrapped) {
+				t.Errorf("AesKeyWrap() got = %x, want %x", got, wrapped)
+			}
+			got, err = AesKeyUnwrap(kek, wrapped)
+			if err != nil {
+				t.Errorf("AesKeyUnwrap() error = %v", err)
+				return
+			}
+			if !bytes.Equal(got, key) {
+				t.Errorf("AesKeyUnwrap() got = %x, want %x", got, key)
+			}
+		})
+	}
+}
+
+func Test_AesKeyWrapPad(t *testing.T) {
+	tests := []struct {
+		name    string
+		kek     string
+		key     string
+		wrapped string
+	}{
+		// RFC 5649 第6节测试向量
+		{
+			name:    "20bytes",
+			kek:     "5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8",
+			key:     "c37b7e6492584340bed12207808941155068f738",
+			wrapped: "138bdeaa9b8fa7fc61f97742e72248ee5ae6ae5360d1ae6a5f54f373fa543b6a",
+		},
+		{
+			name:    "7bytes",
+			kek:     "5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8",
+			key:     "466f7250617369",
+			wrapped: "afbeb0f07dfbf5419200f2ccb50bb24f",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kek, key, wrapped := mustHex(tt.kek), mustHex(tt.key), mustHex(tt.wrapped)
+			got, err := AesKeyWrapPad(kek, key)
+			if err != nil {
+				t.Errorf("AesKeyWrapPad() error = %v", err)
+				return
+			}
+			if !bytes.Equal(got, wrapped) {
+				t.Errorf("AesKeyWrapPad() got = %x, want %x", got, wrapped)
+			}
+			got, err = AesKeyUnwrapPad(kek, wrapped)
+			if err != nil {
+				t.Errorf("AesKeyUnwrapPad() error = %v", err)
+				return
+			}
+			if !bytes.Equal(got, key) {
+				t.Errorf("AesKeyUnwrapPad() got = %x, want %x", got, key)
+			}
+		})
+	}
+}
+
+func Test_Sm4KeyWrap(t *testing.T) {
+	kek := mustHex("0123456789abcdeffedcba9876543210")
+	for _, length := range []int{1, 7, 8, 9, 16, 24, 32} {
+		key := bytes.Repeat([]byte{byte(length)}, length)
+		if length%8 == 0 && length >= 16 {
+			wrapped, err := Sm4KeyWrap(kek, key)
+			if err != nil {
+				t.Fatalf("Sm4KeyWrap(%d) error = %v", length, err)
+			}
+			got, err := Sm4KeyUnwrap(kek, wrapped)
+			if err != nil || !bytes.Equal(got, key) {
+				t.Errorf("Sm4KeyUnwrap(%d) got = %x, error = %v, want %x", length, got, err, key)
+			}
+		}
+		wrapped, err := Sm4KeyWrapPad(kek, key)
+		if err != nil {
+			t.Fatalf("Sm4KeyWrapPad(%d) error = %v", length, err)
+		}
+		got, err := Sm4KeyUnwrapPad(kek, wrapped)
+		if err != nil || !bytes.Equal(got, key) {
+			t.Errorf("Sm4KeyUnwrapPad(%d) got = %x, error = %v, want %x", length, got, err, key)
+		}
+	}
+}
+
+func Test_KeyUnwrapIntegrity(t *testing.T) {
+	kek := mustHex("000102030405060708090a0b0c0d0e0f")
+	otherKek := mustHex("0f0e0d0c0b0a09080706050403020100")
+	key := mustHex("00112233445566778899aabbccddeeff")
+	wrapped, _ := AesKeyWrap(kek, key)
+	wrappedPad, _ := AesKeyWrapPad(kek, key[:5])
+	sm4Wrapped, _ := Sm4KeyWrapPad(kek, key)
+	tampered := bytes.Clone(wrapped)
+	tampered[len(tampered)-1] ^= 1
+	tests := []struct {
+		name   string
+		unwrap func() ([]byte, error)
+	}{
+		{name: "kw-tampered", unwrap: func() ([]byte, error) { return AesKeyUnwrap(kek, tampered) }},
+		{name: "kw-kek", unwrap: func() ([]byte, error) { return AesKeyUnwrap(otherKek, wrapped) }},
+		{name: "kwp-kek", unwrap: func() ([]byte, error) { return AesKeyUnwrapPad(otherKek, wrappedPad) }},
+		{name: "kwp-kw", unwrap: func() ([]byte, error) { return AesKeyUnwrapPad(kek, wrapped) }},
+		{name: "kw-kwp", unwrap: func() ([]byte, error) { return AesKeyUnwrap(kek, sm4Wrapped) }},
+		{name: "sm4-aes", unwrap: func() ([]byte, error) { return Sm4KeyUnwrapPad(kek, wrappedPad) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.unwrap(); !errors.Is(err, ErrKeyWrapIntegrity) {
+				t.Errorf("unwrap error = %v, want %v", err, ErrKeyWrapIntegrity)
+			}
+		})
+	}
+}
+
+func Test_KeyWrapInvalidLength(t *testing.T) {
+	kek := mustHex("000102030405060708090a0b0c0d0e0f")
+	tests := []struct {
+		name string
+		call func() ([]byte, error)
+	}{
+		{name: "kw-short", call: func() ([]byte, error) { return AesKeyWrap(kek, make([]byte, 8)) }},
+		{name: "kw-unaligned", call: func() ([]byte, error) { return AesKeyWrap(kek, make([]byte, 17)) }},
+		{name: "kwp-empty", call: func() ([]byte, error) { return AesKeyWrapPad(kek, nil) }},
+		{name: "unwrap-short", call: func() ([]byte, error) { return AesKeyUnwrap(kek, make([]byte, 16)) }},
+		{name: "unwrap-pad-unaligned", call: func() ([]byte, error) { return AesKeyUnwrapPad(kek, make([]byte, 20)) }},
+		{name: "kek", call: func() ([]byte, error) { return Sm4KeyWrap(kek[:8], make([]byte, 16)) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.call(); err == nil {
+				t.Errorf("error = nil, wantErr true")
+			}
+		})
+	}
+}
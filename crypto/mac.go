@@ -0,0 +1,310 @@
+// Package crypto 消息认证码工具包
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+)
+
+/*
+消息认证码：
+CMAC（NIST SP 800-38B / RFC 4493）：适用于任意分组密码，支持aes、sm4与3des，结果长度为分组长度。
+GMAC（NIST SP 800-38D）：即明文为空的gcm，待认证数据作为附加数据，需要12字节nonce且同一密钥下不能重复。
+CBC-MAC（ISO 9797-1 MAC算法1）：cbc模式加密全部分组后取最后一个分组，只适用于定长消息，否则需要配合填充方式2使用。
+Retail MAC（ISO 9797-1 MAC算法3）：使用单des密钥做cbc-mac，最后一个分组使用3des处理，常用于金融支付报文。
+
+校验函数使用常量时间比较，并且允许传入截断后的mac（支付报文中通常只取前4字节），截断后的长度不能小于4字节。
+*/
+
+// ISO 9797-1填充方式枚举
+const (
+	// MacPaddingIso9797M1 填充方式1：补0至分组长度的整数倍，数据为空时补一个完整分组
+	MacPaddingIso9797M1 = "iso9797-m1"
+	// MacPaddingIso9797M2 填充方式2：先补0x80，再补0至分组长度的整数倍
+	MacPaddingIso9797M2 = "iso9797-m2"
+)
+
+// macMinLength 校验时允许的最短mac长度
+const macMinLength = 4
+
+// ErrMacMismatch mac校验失败
+var ErrMacMismatch = errors.New("mac mismatch")
+
+// Cmac 使用任意分组密码计算CMAC，分组长度需要为8或16字节
+// @param block 分组密码
+// @param data 待认证数据
+func Cmac(block cipher.Block, data []byte) ([]byte, error) {
+	if block.BlockSize() != 8 && block.BlockSize() != 16 {
+		return nil, errors.New("block size must be 8 or 16")
+	}
+	return cmacSum(block, data), nil
+}
+
+// CmacVerify 校验CMAC
+// @param block 分组密码
+// @param data 待认证数据
+// @param mac 待校验的mac，可以是截断后的结果
+func CmacVerify(block cipher.Block, data, mac []byte) error {
+	expected, err := Cmac(block, data)
+	if err != nil {
+		return err
+	}
+	return macVerify(expected, mac)
+}
+
+// AesCmac 计算AES-CMAC
+// @param key 16、24或32字节密钥
+// @param data 待认证数据
+func AesCmac(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create block failed: %w", err)
+	}
+	return Cmac(block, data)
+}
+
+// AesCmacVerify 校验AES-CMAC
+// @param key 16、24或32字节密钥
+// @param data 待认证数据
+// @param mac 待校验的mac，可以是截断后的结果
+func AesCmacVerify(key, data, mac []byte) error {
+	expected, err := AesCmac(key, data)
+	if err != nil {
+		return err
+	}
+	return macVerify(expected, mac)
+}
+
+// Sm4Cmac 计算SM4-CMAC
+// @param key 16字节密钥
+// @param data 待认证数据
+func Sm4Cmac(key, data []byte) ([]byte, error) {
+	block, err := NewSm4Cipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create block failed: %w", err)
+	}
+	return Cmac(block, data)
+}
+
+// Sm4CmacVerify 校验SM4-CMAC
+// @param key 16字节密钥
+// @param data 待认证数据
+// @param mac 待校验的mac，可以是截断后的结果
+func Sm4CmacVerify(key, data, mac []byte) error {
+	expected, err := Sm4Cmac(key, data)
+	if err != nil {
+		return err
+	}
+	return macVerify(expected, mac)
+}
+
+// AesGmac 计算AES-GMAC
+// @param key 16、24或32字节密钥
+// @param nonce 12字节随机数
+// @param data 待认证数据
+func AesGmac(key, nonce, data []byte) ([]byte, error) {
+	aead, err := newAesGcm(key)
+	if err != nil {
+		return nil, err
+	}
+	return aeadEncrypt(aead, nonce, nil, data)
+}
+
+// AesGmacVerify 校验AES-GMAC
+// @param key 16、24或32字节密钥
+// @param nonce 12字节随机数
+// @param data 待认证数据
+// @param mac 待校验的mac，可以是截断后的结果
+func AesGmacVerify(key, nonce, data, mac []byte) error {
+	expected, err := AesGmac(key, nonce, data)
+	if err != nil {
+		return err
+	}
+	return macVerify(expected, mac)
+}
+
+// Sm4Gmac 计算SM4-GMAC
+// @param key 16字节密钥
+// @param nonce 12字节随机数
+// @param data 待认证数据
+func Sm4Gmac(key, nonce, data []byte) ([]byte, error) {
+	aead, err := newSm4Gcm(key)
+	if err != nil {
+		return nil, err
+	}
+	return aeadEncrypt(aead, nonce, nil, data)
+}
+
+// Sm4GmacVerify 校验SM4-GMAC
+// @param key 16字节密钥
+// @param nonce 12字节随机数
+// @param data 待认证数据
+// @param mac 待校验的mac，可以是截断后的结果
+func Sm4GmacVerify(key, nonce, data, mac []byte) error {
+	expected, err := Sm4Gmac(key, nonce, data)
+	if err != nil {
+		return err
+	}
+	return macVerify(expected, mac)
+}
+
+// CbcMac 计算CBC-MAC（ISO 9797-1 MAC算法1），iv固定为0
+// @param block 分组密码
+// @param data 待认证数据
+// @param padding 填充方式，MacPaddingIso9797M1或MacPaddingIso9797M2
+func CbcMac(block cipher.Block, data []byte, padding string) ([]byte, error) {
+	padded, err := iso9797Padding(padding, data, block.BlockSize())
+	if err != nil {
+		return nil, err
+	}
+	return cbcMac(block, padded), nil
+}
+
+// CbcMacVerify 校验CBC-MAC（ISO 9797-1 MAC算法1）
+// @param block 分组密码
+// @param data 待认证数据
+// @param padding 填充方式，MacPaddingIso9797M1或MacPaddingIso9797M2
+// @param mac 待校验的mac，可以是截断后的结果
+func CbcMacVerify(block cipher.Block, data []byte, padding string, mac []byte) error {
+	expected, err := CbcMac(block, data, padding)
+	if err != nil {
+		return err
+	}
+	return macVerify(expected, mac)
+}
+
+// TripleDesRetailMac 计算Retail MAC（ISO 9797-1 MAC算法3，也称ANSI X9.19 MAC）
+// @param key 16字节（K1||K2）或24字节（K1||K2||K3）密钥，16字节时K3=K1
+// @param data 待认证数据
+// @param padding 填充方式，MacPaddingIso9797M1或MacPaddingIso9797M2
+func TripleDesRetailMac(key, data []byte, padding string) ([]byte, error) {
+	if len(key) != 16 && len(key) != 24 {
+		return nil, errors.New("key length must be 16 or 24")
+	}
+	if len(key) == 16 {
+		key = append(key[:16:16], key[:8]...)
+	}
+	single, err := des.NewCipher(key[:8])
+	if err != nil {
+		return nil, fmt.Errorf("create block failed: %w", err)
+	}
+	triple, err := des.NewTripleDESCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create block failed: %w", err)
+	}
+	padded, err := iso9797Padding(padding, data, des.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	// 除最后一个分组外使用K1做cbc-mac，最后一个分组使用3des（E_K3(D_K2(E_K1(x))))）
+	n := len(padded) - des.BlockSize
+	mac := cbcMac(single, padded[:n])
+	subtle.XORBytes(mac, mac, padded[n:])
+	triple.Encrypt(mac, mac)
+	return mac, nil
+}
+
+// TripleDesRetailMacVerify 校验Retail MAC（ISO 9797-1 MAC算法3）
+// @param key 16字节（K1||K2）或24字节（K1||K2||K3）密钥，16字节时K3=K1
+// @param data 待认证数据
+// @param padding 填充方式，MacPaddingIso9797M1或MacPaddingIso9797M2
+// @param mac 待校验的mac，可以是截断后的结果
+func TripleDesRetailMacVerify(key, data []byte, padding string, mac []byte) error {
+	expected, err := TripleDesRetailMac(key, data, padding)
+	if err != nil {
+		return err
+	}
+	return macVerify(expected, mac)
+}
+
+// macVerify 常量时间比较mac，允许mac为expected截断后的结果
+func macVerify(expected, mac []byte) error {
+	if len(mac) < macMinLength || len(mac) > len(expected) {
+		return ErrMacMismatch
+	}
+	if subtle.ConstantTimeCompare(expected[:len(mac)], mac) != 1 {
+		return ErrMacMismatch
+	}
+	return nil
+}
+
+// iso9797Padding ISO 9797-1填充
+func iso9797Padding(padding string, data []byte, blockSize int) ([]byte, error) {
+	switch padding {
+	case MacPaddingIso9797M1:
+		n := max((len(data)+blockSize-1)/blockSize, 1)
+		padded := make([]byte, n*blockSize)
+		copy(padded, data)
+		return padded, nil
+	case MacPaddingIso9797M2:
+		padded := make([]byte, (len(data)/blockSize+1)*blockSize)
+		copy(padded, data)
+		padded[len(data)] = 0x80
+		return padded, nil
+	default:
+		return nil, fmt.Errorf("unsupported mac padding: %s", padding)
+	}
+}
+
+// cbcMac 计算iv为0的cbc-mac，data长度需要为分组长度的整数倍
+func cbcMac(block cipher.Block, data []byte) []byte {
+	bs := block.BlockSize()
+	x := make([]byte, bs)
+	for i := 0; i < len(data); i += bs {
+		subtle.XORBytes(x, x, data[i:i+bs])
+		block.Encrypt(x, x)
+	}
+	return x
+}
+
+// iso7816Pad 填充0x80后补0至一个完整分组，src长度需要小于分组长度
+func iso7816Pad(src []byte, blockSize int) []byte {
+	padded := make([]byte, blockSize)
+	copy(padded, src)
+	padded[len(src)] = 0x80
+	return padded
+}
+
+// cmacDouble GF(2^64)或GF(2^128)上乘以x，即CMAC子密钥生成中的dbl操作
+func cmacDouble(src []byte) []byte {
+	dst := make([]byte, len(src))
+	var carry byte
+	for i := len(src) - 1; i >= 0; i-- {
+		dst[i] = src[i]<<1 | carry
+		carry = src[i] >> 7
+	}
+	// 最高位为1时异或R128 = 0x87或R64 = 0x1b
+	r := byte(0x87)
+	if len(src) == 8 {
+		r = 0x1b
+	}
+	dst[len(dst)-1] ^= byte(subtle.ConstantTimeByteEq(carry, 1)) * r
+	return dst
+}
+
+// cmacSum 计算CMAC（NIST SP 800-38B），分组长度需要为8或16字节
+func cmacSum(block cipher.Block, data []byte) []byte {
+	bs := block.BlockSize()
+	k1 := make([]byte, bs)
+	block.Encrypt(k1, k1)
+	k1 = cmacDouble(k1)
+	// 最后一个分组：完整分组异或k1，不完整分组填充后异或k2
+	n := (len(data) + bs - 1) / bs
+	var last []byte
+	if n > 0 && len(data)%bs == 0 {
+		last = make([]byte, bs)
+		subtle.XORBytes(last, data[(n-1)*bs:], k1)
+	} else {
+		n = max(n, 1)
+		last = iso7816Pad(data[(n-1)*bs:], bs)
+		subtle.XORBytes(last, last, cmacDouble(k1))
+	}
+	x := cbcMac(block, data[:(n-1)*bs])
+	subtle.XORBytes(x, x, last)
+	block.Encrypt(x, x)
+	return x
+}
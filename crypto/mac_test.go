@@ -0,0 +1,176 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/des"
+	"errors"
+	"testing"
+)
+
+func Test_AesCmac(t *testing.T) {
+	key := mustHex("2b7e151628aed2a6abf7158809cf4f3c")
+	message := mustHex("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51" +
+		"30c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710")
+	tests := []struct {
+		name   string
+		length int
+		mac    string
+	}{
+		// RFC 4493 第4节测试向量
+		{name: "empty", length: 0, mac: "bb1d6929e95937287fa37d129b756746"},
+		{name: "16bytes", length: 16, mac: "070a16b46b4d4144f79bdd9dd04a287c"},
+		{name: "40bytes", length: 40, mac: "dfa66747de9ae63030ca32611497c827"},
+		{name: "64bytes", length: 64, mac: "51f0bebf7e3b9d92fc49741779363cfe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := mustHex(tt.mac)
+			got, err := AesCmac(key, message[:tt.length])
+			if err != nil {
+				t.Errorf("AesCmac() error = %v", err)
+				return
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("AesCmac() got = %x, want %x", got, want)
+			}
+			if err = AesCmacVerify(key, message[:tt.length], want); err != nil {
+				t.Errorf("AesCmacVerify() error = %v", err)
+			}
+		})
+	}
+}
+
+func Test_TripleDesCmac(t *testing.T) {
+	block, _ := des.NewTripleDESCipher(mustHex("8aa83bf8cbda10620bc1bf19fbb6cd58bc313d4a371ca8b5"))
+	message := mustHex("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51")
+	tests := []struct {
+		name   string
+		length int
+		mac    string
+	}{
+		// NIST SP 800-38B 附录D.4测试向量（三密钥3des）
+		{name: "empty", length: 0, mac: "b7a688e122ffaf95"},
+		{name: "8bytes", length: 8, mac: "8e8f293136283797"},
+		{name: "20bytes", length: 20, mac: "743ddbe0ce2dc2ed"},
+		{name: "32bytes", length: 32, mac: "33e6b1092400eae5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := mustHex(tt.mac)
+			got, err := Cmac(block, message[:tt.length])
+			if err != nil {
+				t.Errorf("Cmac() error = %v", err)
+				return
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Cmac() got = %x, want %x", got, want)
+			}
+			if err = CmacVerify(block, message[:tt.length], want); err != nil {
+				t.Errorf("CmacVerify() error = %v", err)
+			}
+		})
+	}
+}
+
+func Test_Iso9797Mac(t *testing.T) {
+	// ISO 9797-1 附录B测试向量
+	key := mustHex("0123456789abcdeffedcba9876543210")
+	data := []byte("Now is the time for all ")
+	block, _ := des.NewCipher(key[:8])
+	got, err := CbcMac(block, data, MacPaddingIso9797M1)
+	if err != nil || !bytes.Equal(got, mustHex("70a30640cc76dd8b")) {
+		t.Errorf("CbcMac() got = %x, error = %v", got, err)
+	}
+	got, err = TripleDesRetailMac(key, data, MacPaddingIso9797M1)
+	if err != nil || !bytes.Equal(got, mustHex("a1c72e74ea3fa9b6")) {
+		t.Errorf("TripleDesRetailMac() got = %x, error = %v", got, err)
+	}
+	// 24字节密钥K3=K1时与16字节密钥结果一致
+	long, err := TripleDesRetailMac(append(bytes.Clone(key), key[:8]...), data, MacPaddingIso9797M1)
+	if err != nil || !bytes.Equal(long, got) {
+		t.Errorf("TripleDesRetailMac() with 24 bytes key got = %x, error = %v, want %x", long, err, got)
+	}
+	if err = TripleDesRetailMacVerify(key, data, MacPaddingIso9797M1, got[:4]); err != nil {
+		t.Errorf("TripleDesRetailMacVerify() truncated error = %v", err)
+	}
+	if err = CbcMacVerify(block, data, MacPaddingIso9797M2, mustHex("70a30640cc76dd8b")); !errors.Is(err, ErrMacMismatch) {
+		t.Errorf("CbcMacVerify() with other padding error = %v, want %v", err, ErrMacMismatch)
+	}
+}
+
+func Test_Gmac(t *testing.T) {
+	// gcm规范测试用例1，明文与附加数据均为空
+	got, err := AesGmac(make([]byte, 16), make([]byte, 12), nil)
+	if err != nil || !bytes.Equal(got, mustHex("58e2fccefa7e3061367f1d57a4e7455a")) {
+		t.Errorf("AesGmac() got = %x, error = %v", got, err)
+	}
+	key, nonce := mustHex("0123456789abcdeffedcba9876543210"), mustHex("000102030405060708090a0b")
+	data := []byte("Hello World")
+	tests := []struct {
+		name   string
+		mac    func(key, nonce, data []byte) ([]byte, error)
+		verify func(key, nonce, data, mac []byte) error
+	}{
+		{name: "aes", mac: AesGmac, verify: AesGmacVerify},
+		{name: "sm4", mac: Sm4Gmac, verify: Sm4GmacVerify},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mac, err := tt.mac(key, nonce, data)
+			if err != nil {
+				t.Fatalf("gmac error = %v", err)
+			}
+			if err = tt.verify(key, nonce, data, mac); err != nil {
+				t.Errorf("verify error = %v", err)
+			}
+			if err = tt.verify(key, nonce, []byte("Hello World!"), mac); !errors.Is(err, ErrMacMismatch) {
+				t.Errorf("verify with wrong data error = %v, want %v", err, ErrMacMismatch)
+			}
+			if _, err = tt.mac(key, nonce[:8], data); err == nil {
+				t.Errorf("gmac with short nonce error = nil, wantErr true")
+			}
+		})
+	}
+}
+
+func Test_MacVerifyMismatch(t *testing.T) {
+	key := mustHex("0123456789abcdeffedcba9876543210")
+	data := []byte("Hello World")
+	mac, _ := Sm4Cmac(key, data)
+	tampered := bytes.Clone(mac)
+	tampered[0] ^= 1
+	tests := []struct {
+		name string
+		mac  []byte
+		data []byte
+		want error
+	}{
+		{name: "ok", mac: mac, data: data, want: nil},
+		{name: "truncated", mac: mac[:8], data: data, want: nil},
+		{name: "tampered", mac: tampered, data: data, want: ErrMacMismatch},
+		{name: "data", mac: mac, data: []byte("Hello World!"), want: ErrMacMismatch},
+		{name: "short", mac: mac[:3], data: data, want: ErrMacMismatch},
+		{name: "long", mac: append(bytes.Clone(mac), 0), data: data, want: ErrMacMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Sm4CmacVerify(key, tt.data, tt.mac); !errors.Is(err, tt.want) {
+				t.Errorf("Sm4CmacVerify() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func Test_MacInvalidInput(t *testing.T) {
+	block, _ := aes.NewCipher(make([]byte, 16))
+	if _, err := CbcMac(block, nil, PaddingPkcs7); err == nil {
+		t.Errorf("CbcMac() with unsupported padding error = nil, wantErr true")
+	}
+	if _, err := TripleDesRetailMac(make([]byte, 8), nil, MacPaddingIso9797M1); err == nil {
+		t.Errorf("TripleDesRetailMac() with short key error = nil, wantErr true")
+	}
+	if _, err := AesCmac(make([]byte, 10), nil); err == nil {
+		t.Errorf("AesCmac() with invalid key error = nil, wantErr true")
+	}
+}
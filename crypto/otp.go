@@ -0,0 +1,287 @@
+// Package crypto 一次性密码工具包
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+HOTP（RFC 4226）与TOTP（RFC 6238）一次性密码，用于管理后台等场景的双因素认证：
+HOTP: HMAC(密钥, 8字节大端计数器)，动态截断后取31位整数，再对10^位数取模并左侧补0
+TOTP: 计数器为当前unix时间 / 时间步长的HOTP
+哈希算法支持sha1（默认，兼容绝大多数身份验证器应用）、sha256、sha512与sm3，
+其中sm3为使用HMAC-SM3的RFC 4226结构，并非GM/T 0021中的算法，只能与同样实现的客户端互通。
+sha1与sha512只在一次性密码中使用，保存在otpHashMap中，不加入通用的hashMap，避免盲索引、签名url、密钥派生等功能误用sha1。
+校验时允许一定的窗口：
+HOTP: 向后查找[counter, counter+Window]，返回匹配的计数器，调用方需要将下次使用的计数器保存为匹配值+1
+TOTP: 查找[当前步-Window, 当前步+Window]，以容忍客户端与服务端的时钟偏差
+TOTP在同一个时间步内可以重复使用，需要通过OtpConfig.ReplayGuard防止重放，
+MemoryOtpReplayGuard为单机内存实现，多实例部署时需要基于redis等实现OtpReplayGuard。
+OtpAuthUri生成身份验证器应用扫码绑定使用的otpauth://地址，密钥使用不带填充的base32编码。
+*/
+
+// 一次性密码校验错误枚举
+var (
+	// ErrOtpInvalid 一次性密码不正确
+	ErrOtpInvalid = errors.New("invalid one-time password")
+	// ErrOtpReplayed 一次性密码已经使用过
+	ErrOtpReplayed = errors.New("one-time password replayed")
+)
+
+// 一次性密码类型枚举
+const (
+	// OtpTypeHotp 基于计数器的一次性密码
+	OtpTypeHotp = "hotp"
+	// OtpTypeTotp 基于时间的一次性密码
+	OtpTypeTotp = "totp"
+)
+
+// 一次性密码专用的哈希算法枚举，HmacSum、DeriveKey等其它函数不支持
+const (
+	// OtpHashSha1 sha1，用于兼容身份验证器应用
+	OtpHashSha1 = "sha1"
+	// OtpHashSha512 sha512
+	OtpHashSha512 = "sha512"
+)
+
+// otpHashMap 一次性密码支持的哈希算法映射
+var otpHashMap = map[string]func() hash.Hash{
+	OtpHashSha1:   sha1.New,
+	HashSha256:    sha256.New,
+	OtpHashSha512: sha512.New,
+	HashSm3:       newSm3,
+}
+
+// 一次性密码默认配置
+const (
+	otpDefaultDigits = 6
+	otpDefaultPeriod = 30 * time.Second
+	otpMinDigits     = 6
+	otpMaxDigits     = 10
+)
+
+// otpPow10 10的幂，下标为位数
+var otpPow10 = [otpMaxDigits + 1]uint64{1, 10, 100, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10}
+
+// OtpReplayGuard 一次性密码防重放检查
+type OtpReplayGuard interface {
+	// Use 标记账号已经使用了指定计数器（时间步），计数器不大于该账号上次使用的计数器时返回false
+	Use(account string, counter uint64) (bool, error)
+}
+
+// OtpConfig 一次性密码配置
+type OtpConfig struct {
+	Hash        string           // 哈希算法，可选OtpHashSha1、HashSha256、OtpHashSha512与HashSm3，为空时使用OtpHashSha1
+	Digits      int              // 位数，为0时使用6，范围为6到10
+	Period      time.Duration    // TOTP时间步长，为0时使用30秒，需要是整数秒
+	Window      int              // 校验窗口，含义见包说明
+	Now         func() time.Time // 获取当前时间，为空时使用time.Now
+	ReplayGuard OtpReplayGuard   // TOTP防重放检查，可以为空
+}
+
+// Otp 一次性密码生成与校验器
+type Otp struct {
+	config OtpConfig // 配置
+}
+
+// NewOtp 创建一次性密码生成与校验器
+// @param config 一次性密码配置
+func NewOtp(config OtpConfig) (*Otp, error) {
+	if config.Hash == "" {
+		config.Hash = OtpHashSha1
+	}
+	config.Hash = strings.ToLower(config.Hash)
+	if _, ok := otpHashMap[config.Hash]; !ok {
+		return nil, fmt.Errorf("unsupported hash: %s", config.Hash)
+	}
+	if config.Digits == 0 {
+		config.Digits = otpDefaultDigits
+	}
+	if config.Digits < otpMinDigits || config.Digits > otpMaxDigits {
+		return nil, fmt.Errorf("digits must be between %d and %d", otpMinDigits, otpMaxDigits)
+	}
+	if config.Period == 0 {
+		config.Period = otpDefaultPeriod
+	}
+	if config.Period < time.Second || config.Period%time.Second != 0 {
+		return nil, errors.New("period must be a positive whole number of seconds")
+	}
+	if config.Window < 0 {
+		return nil, errors.New("window must not be negative")
+	}
+	return &Otp{config: config}, nil
+}
+
+// Hotp 生成HOTP
+// @param secret 密钥
+// @param counter 计数器
+func (o *Otp) Hotp(secret []byte, counter uint64) (string, error) {
+	h := hmac.New(otpHashMap[o.config.Hash], secret)
+	h.Write(binary.BigEndian.AppendUint64(nil, counter))
+	mac := h.Sum(nil)
+	// 动态截断：使用最后一个字节的低4位作为偏移量，取4字节并去掉最高位
+	offset := mac[len(mac)-1] & 0x0f
+	value := uint64(binary.BigEndian.Uint32(mac[offset:]) & 0x7fffffff)
+	code := strconv.FormatUint(value%otpPow10[o.config.Digits], 10)
+	return strings.Repeat("0", o.config.Digits-len(code)) + code, nil
+}
+
+// VerifyHotp 校验HOTP并返回匹配的计数器
+// @param secret 密钥
+// @param counter 期望的计数器，会向后查找Window个
+// @param code 一次性密码
+func (o *Otp) VerifyHotp(secret []byte, counter uint64, code string) (uint64, error) {
+	return o.verify(secret, counter, counter+uint64(o.config.Window), code)
+}
+
+// Totp 生成当前时间的TOTP
+// @param secret 密钥
+func (o *Otp) Totp(secret []byte) (string, error) {
+	return o.TotpAt(secret, o.now())
+}
+
+// TotpAt 生成指定时间的TOTP
+// @param secret 密钥
+// @param t 时间
+func (o *Otp) TotpAt(secret []byte, t time.Time) (string, error) {
+	return o.Hotp(secret, o.timeStep(t))
+}
+
+// VerifyTotp 校验TOTP并返回匹配的时间步
+// @param secret 密钥
+// @param account 账号，用于防重放检查，未配置ReplayGuard时可以为空
+// @param code 一次性密码
+func (o *Otp) VerifyTotp(secret []byte, account, code string) (uint64, error) {
+	step, window := o.timeStep(o.now()), uint64(o.config.Window)
+	first := uint64(0)
+	if step > window {
+		first = step - window
+	}
+	counter, err := o.verify(secret, first, step+window, code)
+	if err != nil {
+		return 0, err
+	}
+	if o.config.ReplayGuard != nil {
+		ok, err := o.config.ReplayGuard.Use(account, counter)
+		if err != nil {
+			return 0, fmt.Errorf("check replay failed: %w", err)
+		}
+		if !ok {
+			return 0, ErrOtpReplayed
+		}
+	}
+	return counter, nil
+}
+
+// OtpAuthUri 生成身份验证器应用绑定使用的otpauth://地址
+// @param otpType 类型，OtpTypeHotp或OtpTypeTotp
+// @param secret 密钥
+// @param issuer 发行方，例如系统名称，可以为空
+// @param account 账号
+// @param counter HOTP的初始计数器，TOTP时忽略
+func (o *Otp) OtpAuthUri(otpType string, secret []byte, issuer, account string, counter uint64) (string, error) {
+	if otpType != OtpTypeHotp && otpType != OtpTypeTotp {
+		return "", fmt.Errorf("unsupported otp type: %s", otpType)
+	}
+	if strings.Contains(issuer, ":") {
+		return "", errors.New("issuer must not contain ':'")
+	}
+	label := account
+	if issuer != "" {
+		label = issuer + ":" + account
+	}
+	query := url.Values{}
+	query.Set("secret", base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret))
+	if issuer != "" {
+		query.Set("issuer", issuer)
+	}
+	query.Set("algorithm", strings.ToUpper(o.config.Hash))
+	query.Set("digits", strconv.Itoa(o.config.Digits))
+	if otpType == OtpTypeHotp {
+		query.Set("counter", strconv.FormatUint(counter, 10))
+	} else {
+		query.Set("period", strconv.FormatInt(int64(o.config.Period/time.Second), 10))
+	}
+	u := url.URL{Scheme: "otpauth", Host: otpType, Path: "/" + label, RawQuery: query.Encode()}
+	return u.String(), nil
+}
+
+// verify 在[first, last]范围内查找匹配的计数器
+func (o *Otp) verify(secret []byte, first, last uint64, code string) (uint64, error) {
+	if len(code) != o.config.Digits {
+		return 0, ErrOtpInvalid
+	}
+	for counter := first; ; counter++ {
+		expected, err := o.Hotp(secret, counter)
+		if err != nil {
+			return 0, err
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return counter, nil
+		}
+		if counter == last {
+			return 0, ErrOtpInvalid
+		}
+	}
+}
+
+// timeStep 计算时间对应的时间步
+func (o *Otp) timeStep(t time.Time) uint64 {
+	return uint64(t.Unix()) / uint64(o.config.Period/time.Second)
+}
+
+// now 获取当前时间
+func (o *Otp) now() time.Time {
+	if o.config.Now != nil {
+		return o.config.Now()
+	}
+	return time.Now()
+}
+
+// GenerateOtpSecret 生成随机的一次性密码密钥，RFC 4226建议至少16字节，推荐20字节
+// @param length 密钥长度
+func GenerateOtpSecret(length int) ([]byte, error) {
+	if length < 16 {
+		return nil, errors.New("otp secret length must be at least 16")
+	}
+	secret := make([]byte, length)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("generate otp secret failed: %w", err)
+	}
+	return secret, nil
+}
+
+// MemoryOtpReplayGuard 基于内存的一次性密码防重放检查，记录每个账号最后使用的计数器
+type MemoryOtpReplayGuard struct {
+	mu       sync.Mutex        // 保护counters
+	counters map[string]uint64 // 账号最后使用的计数器
+}
+
+// Use 标记账号已经使用了指定计数器
+func (g *MemoryOtpReplayGuard) Use(account string, counter uint64) (bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if last, ok := g.counters[account]; ok && counter <= last {
+		return false, nil
+	}
+	if g.counters == nil {
+		g.counters = make(map[string]uint64)
+	}
+	g.counters[account] = counter
+	return true, nil
+}
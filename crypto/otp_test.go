@@ -0,0 +1,159 @@
+package crypto
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_OtpHotp(t *testing.T) {
+	// RFC 4226 附录D
+	secret := []byte("12345678901234567890")
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	otp, err := NewOtp(OtpConfig{Window: 2})
+	if err != nil {
+		t.Fatalf("NewOtp() error = %v", err)
+	}
+	for counter, code := range want {
+		if got, err := otp.Hotp(secret, uint64(counter)); err != nil || got != code {
+			t.Errorf("Hotp(%d) got = %s, want %s, error = %v", counter, got, code, err)
+		}
+	}
+	// 向后查找窗口
+	if got, err := otp.VerifyHotp(secret, 3, want[5]); err != nil || got != 5 {
+		t.Errorf("VerifyHotp() got = %d, error = %v", got, err)
+	}
+	if _, err = otp.VerifyHotp(secret, 3, want[6]); !errors.Is(err, ErrOtpInvalid) {
+		t.Errorf("VerifyHotp() out of window error = %v, want %v", err, ErrOtpInvalid)
+	}
+	if _, err = otp.VerifyHotp(secret, 3, want[2]); !errors.Is(err, ErrOtpInvalid) {
+		t.Errorf("VerifyHotp() used counter error = %v, want %v", err, ErrOtpInvalid)
+	}
+}
+
+func Test_OtpTotp(t *testing.T) {
+	// RFC 6238 附录B
+	secrets := map[string][]byte{
+		OtpHashSha1:   []byte("12345678901234567890"),
+		HashSha256:    []byte("12345678901234567890123456789012"),
+		OtpHashSha512: []byte("1234567890123456789012345678901234567890123456789012345678901234"),
+	}
+	tests := []struct {
+		time int64
+		want map[string]string
+	}{
+		{59, map[string]string{OtpHashSha1: "94287082", HashSha256: "46119246", OtpHashSha512: "90693936"}},
+		{1111111109, map[string]string{OtpHashSha1: "07081804", HashSha256: "68084774", OtpHashSha512: "25091201"}},
+		{1111111111, map[string]string{OtpHashSha1: "14050471", HashSha256: "67062674", OtpHashSha512: "99943326"}},
+		{1234567890, map[string]string{OtpHashSha1: "89005924", HashSha256: "91819424", OtpHashSha512: "93441116"}},
+		{2000000000, map[string]string{OtpHashSha1: "69279037", HashSha256: "90698825", OtpHashSha512: "38618901"}},
+		{20000000000, map[string]string{OtpHashSha1: "65353130", HashSha256: "77737706", OtpHashSha512: "47863826"}},
+	}
+	for _, tt := range tests {
+		for hashName, want := range tt.want {
+			otp, _ := NewOtp(OtpConfig{Hash: hashName, Digits: 8})
+			if got, err := otp.TotpAt(secrets[hashName], time.Unix(tt.time, 0)); err != nil || got != want {
+				t.Errorf("TotpAt(%d) %s got = %s, want %s, error = %v", tt.time, hashName, got, want, err)
+			}
+		}
+	}
+}
+
+func Test_OtpVerifyTotp(t *testing.T) {
+	secret, err := GenerateOtpSecret(20)
+	if err != nil {
+		t.Fatalf("GenerateOtpSecret() error = %v", err)
+	}
+	for _, hashName := range []string{OtpHashSha1, HashSha256, OtpHashSha512, HashSm3} {
+		t.Run(hashName, func(t *testing.T) {
+			clock := &testClock{now: time.Unix(1700000000, 0)}
+			otp, err := NewOtp(OtpConfig{Hash: hashName, Window: 1, Now: clock.Now, ReplayGuard: &MemoryOtpReplayGuard{}})
+			if err != nil {
+				t.Fatalf("NewOtp() error = %v", err)
+			}
+			code, _ := otp.Totp(secret)
+			// 上一个时间步的密码在窗口内
+			clock.now = clock.now.Add(30 * time.Second)
+			step, err := otp.VerifyTotp(secret, "alice", code)
+			if err != nil || step != 1700000000/30 {
+				t.Errorf("VerifyTotp() got = %d, error = %v", step, err)
+			}
+			if _, err = otp.VerifyTotp(secret, "alice", code); !errors.Is(err, ErrOtpReplayed) {
+				t.Errorf("VerifyTotp() replay error = %v, want %v", err, ErrOtpReplayed)
+			}
+			if _, err = otp.VerifyTotp(secret, "bob", code); err != nil {
+				t.Errorf("VerifyTotp() other account error = %v", err)
+			}
+			clock.now = clock.now.Add(30 * time.Second)
+			if _, err = otp.VerifyTotp(secret, "carol", code); !errors.Is(err, ErrOtpInvalid) {
+				t.Errorf("VerifyTotp() out of window error = %v, want %v", err, ErrOtpInvalid)
+			}
+			if _, err = otp.VerifyTotp(secret, "carol", "12345"); !errors.Is(err, ErrOtpInvalid) {
+				t.Errorf("VerifyTotp() wrong length error = %v, want %v", err, ErrOtpInvalid)
+			}
+		})
+	}
+}
+
+func Test_OtpAuthUri(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	otp, _ := NewOtp(OtpConfig{Hash: HashSha256, Digits: 8, Period: time.Minute})
+	tests := []struct {
+		name    string
+		otpType string
+		issuer  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "totp",
+			otpType: OtpTypeTotp,
+			issuer:  "Example Admin",
+			want: "otpauth://totp/Example%20Admin:alice@example.com?algorithm=SHA256&digits=8" +
+				"&issuer=Example+Admin&period=60&secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		},
+		{
+			name:    "hotp",
+			otpType: OtpTypeHotp,
+			want: "otpauth://hotp/alice@example.com?algorithm=SHA256&counter=7&digits=8" +
+				"&secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		},
+		{name: "type", otpType: "motp", wantErr: true},
+		{name: "issuer", otpType: OtpTypeTotp, issuer: "a:b", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := otp.OtpAuthUri(tt.otpType, secret, tt.issuer, "alice@example.com", 7)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("OtpAuthUri() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("OtpAuthUri() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_NewOtpError(t *testing.T) {
+	tests := []struct {
+		name   string
+		config OtpConfig
+	}{
+		{name: "hash", config: OtpConfig{Hash: "md5"}},
+		{name: "digits", config: OtpConfig{Digits: 5}},
+		{name: "period", config: OtpConfig{Period: 1500 * time.Millisecond}},
+		{name: "window", config: OtpConfig{Window: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewOtp(tt.config); err == nil {
+				t.Errorf("NewOtp() error = nil, wantErr true")
+			}
+		})
+	}
+	if _, err := GenerateOtpSecret(10); err == nil || !strings.Contains(err.Error(), "16") {
+		t.Errorf("GenerateOtpSecret() error = %v, wantErr true", err)
+	}
+}
@@ -72,12 +72,16 @@ func UnPadding(padding string, src []byte) []byte {
 func pkcs7Padding(src []byte, blockSize int) []byte {
 	padding := blockSize - len(src)%blockSize
 	padtext := bytes.Repeat([]byte{byte(padding)}, padding)
-	return append(src, padtext...)
+	// 限制容量使append总是分配新的数组，避免覆盖调用方src容量内len之后的数据
+	return append(src[:len(src):len(src)], padtext...)
 }
 
-// pkcs7UnPadding pkcs7去填充
+// pkcs7UnPadding pkcs7去填充，数据为空或填充长度超出数据长度时原样返回
 func pkcs7UnPadding(src []byte) []byte {
 	length := len(src)
+	if length == 0 {
+		return src
+	}
 	unpadding := int(src[length-1])
 	end := length - unpadding
 	if end < 0 {
@@ -90,7 +94,7 @@ func pkcs7UnPadding(src []byte) []byte {
 func zeroPadding(src []byte, blockSize int) []byte {
 	padding := blockSize - len(src)%blockSize
 	padtext := bytes.Repeat([]byte{0}, padding)
-	return append(src, padtext...)
+	return append(src[:len(src):len(src)], padtext...)
 }
 
 // zeroPadding 0去填充
@@ -90,6 +90,22 @@ func TestUnPadding(t *testing.T) {
 			},
 			want: []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
 		},
+		{
+			name: "pkcs7#empty",
+			args: args{
+				padding: PaddingPkcs7,
+				src:     []byte{},
+			},
+			want: []byte{},
+		},
+		{
+			name: "pkcs7#overflow",
+			args: args{
+				padding: PaddingPkcs7,
+				src:     []byte{1, 2, 5},
+			},
+			want: []byte{1, 2, 5},
+		},
 		{
 			name: "zero#1",
 			args: args{
@@ -0,0 +1,553 @@
+// Package crypto 大数据量并行加解密工具包
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+/*
+ECB、CTR、GCM与XTS的各个分组可以独立计算，数据量较大时按分片拆分给多个goroutine并行处理，结果与顺序处理完全一致：
+ECB: 每个分片独立加解密
+CTR: 分片的起始计数器为iv加上分片之前的分组数（按大端整数相加）
+XTS: 分片的起始调整值由顺序乘以α得到，乘法开销远小于分组加密，最后一个分片负责密文挪用
+GCM: 只支持12字节nonce，计数器部分同CTR；GHASH按霍纳法则可以拆分为
+     GHASH(A || B) = GHASH(A)·H^m ⊕ GHASH(B)，m为B的分组数，
+     因此每个分片在加解密的同时计算自己的GHASH，最后按顺序合并；解密时先并行计算GHASH并校验标签，校验通过后才并行解密
+部分分组密码（例如tjfoc/gmsm的sm4）在结构体中保存了中间状态，不能在多个goroutine中共用，因此并行函数接收密钥与创建分组密码的函数，
+每个goroutine单独创建分组密码。
+数据长度小于ParallelOptions.Threshold或只有一个并发时直接使用顺序实现，避免小数据量时的调度开销。
+aes在支持AES-NI的平台上本身已经很快，并行主要用于纯软件实现的sm4。
+并行GCM的GHASH为纯Go的4比特查表实现，吞吐量远低于标准库使用PCLMULQDQ等指令的实现，且查表下标依赖数据，不是常量时间的，
+因此GcmEncryptParallel与GcmDecryptParallel只面向sm4等没有硬件GHASH的分组密码：
+newCipher返回标准库aes分组密码时始终直接使用cipher.NewGCM，不做并行处理。
+*/
+
+// 并行处理默认配置
+const (
+	parallelDefaultThreshold = 256 * 1024
+	parallelDefaultChunkSize = 64 * 1024
+	gcmBlockSize             = 16
+	gcmStandardNonceSize     = 12
+	gcmTagSize               = 16
+	// gcmMaxPlaintextLength gcm单次加密的最大明文长度，(2^32-2)个分组
+	gcmMaxPlaintextLength = (1<<32 - 2) * gcmBlockSize
+)
+
+// errGcmOpen gcm标签校验失败，与标准库的错误信息保持一致
+var errGcmOpen = errors.New("cipher: message authentication failed")
+
+// ParallelOptions 并行处理配置，零值使用默认配置
+type ParallelOptions struct {
+	Threshold int // 数据长度不小于该值时才并行处理，为0时使用256KiB
+	ChunkSize int // 每个分片的长度，需要是分组长度的整数倍，为0时使用64KiB
+	Workers   int // 并发数，为0时使用runtime.GOMAXPROCS(0)
+}
+
+// EcbEncryptParallel 并行的ecb模式加密
+// @param newCipher 创建分组密码的函数，例如aes.NewCipher、NewSm4Cipher
+// @param key 密钥
+// @param plaintext 明文，需要是分组长度的整数倍
+// @param options 并行处理配置
+func EcbEncryptParallel(newCipher func(key []byte) (cipher.Block, error), key, plaintext []byte,
+	options ParallelOptions) ([]byte, error) {
+	return ecbCryptParallel(newCipher, key, plaintext, options, true)
+}
+
+// EcbDecryptParallel 并行的ecb模式解密
+// @param newCipher 创建分组密码的函数
+// @param key 密钥
+// @param ciphertext 密文，需要是分组长度的整数倍
+// @param options 并行处理配置
+func EcbDecryptParallel(newCipher func(key []byte) (cipher.Block, error), key, ciphertext []byte,
+	options ParallelOptions) ([]byte, error) {
+	return ecbCryptParallel(newCipher, key, ciphertext, options, false)
+}
+
+// CtrXorParallel 并行的ctr模式加解密，结果与cipher.NewCTR相同，加密与解密为同一操作
+// @param newCipher 创建分组密码的函数
+// @param key 密钥
+// @param iv 初始计数器，长度为分组长度
+// @param src 明文或密文
+// @param options 并行处理配置
+func CtrXorParallel(newCipher func(key []byte) (cipher.Block, error), key, iv, src []byte,
+	options ParallelOptions) ([]byte, error) {
+	block, err := newCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create block failed: %w", err)
+	}
+	if len(iv) != block.BlockSize() {
+		return nil, fmt.Errorf("iv length must be %d", block.BlockSize())
+	}
+	dst := make([]byte, len(src))
+	if !options.enabled(len(src)) {
+		cipher.NewCTR(block, iv).XORKeyStream(dst, src)
+		return dst, nil
+	}
+	chunkSize, err := options.chunkSize(block.BlockSize())
+	if err != nil {
+		return nil, err
+	}
+	err = parallelRun(newCipher, key, len(src), chunkSize, options, func(block cipher.Block, start, end int) error {
+		counter := ctrAdd(iv, uint64(start/block.BlockSize()))
+		cipher.NewCTR(block, counter).XORKeyStream(dst[start:end], src[start:end])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// GcmEncryptParallel 并行的gcm模式加密，结果与cipher.NewGCM相同，为密文||16字节标签，
+// 用于sm4等没有硬件GHASH的分组密码，标准库aes分组密码直接使用cipher.NewGCM
+// @param newCipher 创建分组密码的函数，分组长度需要为16字节
+// @param key 密钥
+// @param nonce 12字节随机数
+// @param plaintext 明文
+// @param additionalData 附加认证数据，可以为空
+// @param options 并行处理配置
+func GcmEncryptParallel(newCipher func(key []byte) (cipher.Block, error), key, nonce, plaintext, additionalData []byte,
+	options ParallelOptions) ([]byte, error) {
+	block, err := newCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create block failed: %w", err)
+	}
+	if !options.enabled(len(plaintext)) || gcmUseStandard(block) {
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("create gcm failed: %w", err)
+		}
+		return aeadEncrypt(aead, nonce, plaintext, additionalData)
+	}
+	g, err := newParallelGcm(block, nonce, len(plaintext), options)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := make([]byte, len(plaintext)+gcmTagSize)
+	partials := make([]gcmFieldElement, g.chunks(len(plaintext)))
+	err = parallelRun(newCipher, key, len(plaintext), g.chunkSize, options, func(block cipher.Block, start, end int) error {
+		cipher.NewCTR(block, g.counter(start)).XORKeyStream(ciphertext[start:end], plaintext[start:end])
+		g.table.update(&partials[start/g.chunkSize], ciphertext[start:end])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	g.tag(ciphertext[len(plaintext):], additionalData, partials, len(plaintext))
+	return ciphertext, nil
+}
+
+// GcmDecryptParallel 并行的gcm模式解密，先校验标签，校验通过后才解密，
+// 用于sm4等没有硬件GHASH的分组密码，标准库aes分组密码直接使用cipher.NewGCM
+// @param newCipher 创建分组密码的函数，分组长度需要为16字节
+// @param key 密钥
+// @param nonce 12字节随机数
+// @param ciphertext 密文||16字节标签
+// @param additionalData 附加认证数据，需要与加密时一致
+// @param options 并行处理配置
+func GcmDecryptParallel(newCipher func(key []byte) (cipher.Block, error), key, nonce, ciphertext, additionalData []byte,
+	options ParallelOptions) ([]byte, error) {
+	block, err := newCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create block failed: %w", err)
+	}
+	if !options.enabled(len(ciphertext)) || gcmUseStandard(block) {
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("create gcm failed: %w", err)
+		}
+		return aeadDecrypt(aead, nonce, ciphertext, additionalData)
+	}
+	length := len(ciphertext) - gcmTagSize
+	g, err := newParallelGcm(block, nonce, length, options)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, expectedTag := ciphertext[:length], ciphertext[length:]
+	partials := make([]gcmFieldElement, g.chunks(length))
+	err = parallelRun(newCipher, key, length, g.chunkSize, options, func(_ cipher.Block, start, end int) error {
+		g.table.update(&partials[start/g.chunkSize], ciphertext[start:end])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var tag [gcmTagSize]byte
+	g.tag(tag[:], additionalData, partials, length)
+	if subtle.ConstantTimeCompare(tag[:], expectedTag) != 1 {
+		return nil, fmt.Errorf("open failed: %w", errGcmOpen)
+	}
+	plaintext := make([]byte, length)
+	err = parallelRun(newCipher, key, length, g.chunkSize, options, func(block cipher.Block, start, end int) error {
+		cipher.NewCTR(block, g.counter(start)).XORKeyStream(plaintext[start:end], ciphertext[start:end])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plaintext, nil
+}
+
+// XtsEncryptParallel 并行的xts模式加密，结果与AesXtsEncrypt、Sm4XtsEncrypt相同
+// @param newCipher 创建分组密码的函数，分组长度需要为16字节
+// @param key 两个等长密钥拼接
+// @param sector 扇区号
+// @param plaintext 明文，至少16字节
+// @param standard 标准，XtsStandardIeee或XtsStandardGb
+// @param options 并行处理配置
+func XtsEncryptParallel(newCipher func(key []byte) (cipher.Block, error), key []byte, sector uint64, plaintext []byte,
+	standard string, options ParallelOptions) ([]byte, error) {
+	return xtsCryptParallel(newCipher, key, sector, plaintext, standard, options, true)
+}
+
+// XtsDecryptParallel 并行的xts模式解密
+// @param newCipher 创建分组密码的函数，分组长度需要为16字节
+// @param key 两个等长密钥拼接
+// @param sector 扇区号
+// @param ciphertext 密文
+// @param standard 标准，需要与加密时一致
+// @param options 并行处理配置
+func XtsDecryptParallel(newCipher func(key []byte) (cipher.Block, error), key []byte, sector uint64, ciphertext []byte,
+	standard string, options ParallelOptions) ([]byte, error) {
+	return xtsCryptParallel(newCipher, key, sector, ciphertext, standard, options, false)
+}
+
+// ecbCryptParallel 并行的ecb模式加解密
+func ecbCryptParallel(newCipher func(key []byte) (cipher.Block, error), key, src []byte,
+	options ParallelOptions, encrypt bool) ([]byte, error) {
+	block, err := newCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create block failed: %w", err)
+	}
+	if !options.enabled(len(src)) {
+		if encrypt {
+			return EcbEncrypt(block, src)
+		}
+		return EcbDecrypt(block, src)
+	}
+	blockSize := block.BlockSize()
+	if len(src)%blockSize != 0 {
+		return nil, errors.New("data not full blocks")
+	}
+	chunkSize, err := options.chunkSize(blockSize)
+	if err != nil {
+		return nil, err
+	}
+	dst := make([]byte, len(src))
+	err = parallelRun(newCipher, key, len(src), chunkSize, options, func(block cipher.Block, start, end int) error {
+		if multi, ok := block.(MultiBlock); ok {
+			if encrypt {
+				multi.EncryptBlocks(dst[start:end], src[start:end])
+			} else {
+				multi.DecryptBlocks(dst[start:end], src[start:end])
+			}
+			return nil
+		}
+		for i := start; i < end; i += blockSize {
+			if encrypt {
+				block.Encrypt(dst[i:], src[i:i+blockSize])
+			} else {
+				block.Decrypt(dst[i:], src[i:i+blockSize])
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// xtsCryptParallel 并行的xts模式加解密
+func xtsCryptParallel(newCipher func(key []byte) (cipher.Block, error), key []byte, sector uint64, src []byte,
+	standard string, options ParallelOptions, encrypt bool) ([]byte, error) {
+	tweak := xtsSectorTweak(sector)
+	if !options.enabled(len(src)) {
+		return xtsCrypt(newCipher, key, tweak, src, standard, encrypt)
+	}
+	_, mul, err := xtsInit(newCipher, key, &tweak, len(src), standard)
+	if err != nil {
+		return nil, err
+	}
+	chunkSize, err := options.chunkSize(xtsBlockSize)
+	if err != nil {
+		return nil, err
+	}
+	// 最后一个分片不足一个分组时合并到前一个分片，由其完成密文挪用
+	chunks := len(src) / chunkSize
+	if len(src)-chunks*chunkSize >= xtsBlockSize {
+		chunks++
+	}
+	tweaks := make([][xtsBlockSize]byte, chunks)
+	for i := range tweaks {
+		tweaks[i] = tweak
+		for j := 0; j < chunkSize/xtsBlockSize; j++ {
+			mul(&tweak)
+		}
+	}
+	dst := make([]byte, len(src))
+	err = parallelRun(newCipher, key[:len(key)/2], len(src), chunkSize, options, func(k1 cipher.Block, start, end int) error {
+		index := start / chunkSize
+		if index == chunks-1 {
+			end = len(src)
+		} else if index >= chunks {
+			return nil
+		}
+		xtsBlocks(k1, mul, tweaks[index], dst[start:end], src[start:end], encrypt)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// parallelRun 将长度为length的数据按chunkSize拆分为分片，使用多个goroutine调用fn处理，每个goroutine单独创建分组密码
+func parallelRun(newCipher func(key []byte) (cipher.Block, error), key []byte, length, chunkSize int,
+	options ParallelOptions, fn func(block cipher.Block, start, end int) error) error {
+	chunks := (length + chunkSize - 1) / chunkSize
+	workers := min(options.workers(), chunks)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			block, err := newCipher(key)
+			if err != nil {
+				errs[w] = fmt.Errorf("create block failed: %w", err)
+				return
+			}
+			// 各goroutine按固定步长领取分片
+			for i := w; i < chunks; i += workers {
+				start := i * chunkSize
+				if err := fn(block, start, min(start+chunkSize, length)); err != nil {
+					errs[w] = err
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// enabled 数据长度是否需要并行处理
+func (o ParallelOptions) enabled(length int) bool {
+	threshold := o.Threshold
+	if threshold <= 0 {
+		threshold = parallelDefaultThreshold
+	}
+	return length >= threshold && o.workers() > 1
+}
+
+// workers 获取并发数
+func (o ParallelOptions) workers() int {
+	if o.Workers > 0 {
+		return o.Workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// chunkSize 获取分片长度
+func (o ParallelOptions) chunkSize(blockSize int) (int, error) {
+	if o.ChunkSize == 0 {
+		return parallelDefaultChunkSize, nil
+	}
+	if o.ChunkSize < 0 || o.ChunkSize%blockSize != 0 {
+		return 0, fmt.Errorf("chunk size must be a positive multiple of %d", blockSize)
+	}
+	return o.ChunkSize, nil
+}
+
+// ctrAdd 计算iv加上n（大端整数相加，超出分组长度时回绕）后的计数器
+func ctrAdd(iv []byte, n uint64) []byte {
+	counter := make([]byte, len(iv))
+	copy(counter, iv)
+	for i := len(counter) - 1; i >= 0 && n > 0; i-- {
+		sum := uint64(counter[i]) + n&0xff
+		counter[i] = byte(sum)
+		n = n>>8 + sum>>8
+	}
+	return counter
+}
+
+// aesBlockType 标准库aes分组密码的类型
+var aesBlockType = func() reflect.Type {
+	block, _ := aes.NewCipher(make([]byte, 16))
+	return reflect.TypeOf(block)
+}()
+
+// gcmUseStandard 是否直接使用标准库的gcm：标准库aes分组密码的gcm在支持的平台上使用硬件GHASH，
+// 其余平台也比这里的纯Go GHASH更快，并行处理没有收益
+func gcmUseStandard(block cipher.Block) bool {
+	return reflect.TypeOf(block) == aesBlockType
+}
+
+// parallelGcm 并行gcm的公共状态
+type parallelGcm struct {
+	block     cipher.Block    // 用于计算H、J0的分组密码，只在调用方goroutine中使用
+	table     *gcmTable       // 乘以H的预计算表
+	h         gcmFieldElement // H = E(0^128)
+	j0        [gcmBlockSize]byte
+	chunkSize int
+}
+
+// newParallelGcm 校验参数并计算H与J0
+func newParallelGcm(block cipher.Block, nonce []byte, length int, options ParallelOptions) (*parallelGcm, error) {
+	if block.BlockSize() != gcmBlockSize {
+		return nil, errors.New("gcm requires a 16-byte block cipher")
+	}
+	if len(nonce) != gcmStandardNonceSize {
+		return nil, fmt.Errorf("nonce length must be %d", gcmStandardNonceSize)
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("open failed: %w", errGcmOpen)
+	}
+	if uint64(length) > gcmMaxPlaintextLength {
+		return nil, errors.New("message too large for gcm")
+	}
+	chunkSize, err := options.chunkSize(gcmBlockSize)
+	if err != nil {
+		return nil, err
+	}
+	g := &parallelGcm{block: block, chunkSize: chunkSize}
+	var h [gcmBlockSize]byte
+	block.Encrypt(h[:], h[:])
+	g.h = gcmFieldElement{low: binary.BigEndian.Uint64(h[:8]), high: binary.BigEndian.Uint64(h[8:])}
+	g.table = newGcmTable(g.h)
+	copy(g.j0[:], nonce)
+	g.j0[gcmBlockSize-1] = 1
+	return g, nil
+}
+
+// chunks 分片数量
+func (g *parallelGcm) chunks(length int) int {
+	return (length + g.chunkSize - 1) / g.chunkSize
+}
+
+// counter 分片起始位置对应的计数器，数据从J0+1开始加密，长度限制保证了低32位不会回绕
+func (g *parallelGcm) counter(start int) []byte {
+	return ctrAdd(g.j0[:], uint64(1+start/gcmBlockSize))
+}
+
+// tag 合并各分片的GHASH并计算标签
+func (g *parallelGcm) tag(dst, additionalData []byte, partials []gcmFieldElement, length int) {
+	var y gcmFieldElement
+	g.table.update(&y, additionalData)
+	// 除最后一个分片外分组数相同，共用H^m的预计算表
+	full := newGcmTable(gcmPow(g.h, g.chunkSize/gcmBlockSize))
+	for i, partial := range partials {
+		if i == len(partials)-1 {
+			last := length - i*g.chunkSize
+			newGcmTable(gcmPow(g.h, (last+gcmBlockSize-1)/gcmBlockSize)).mul(&y)
+		} else {
+			full.mul(&y)
+		}
+		y.low ^= partial.low
+		y.high ^= partial.high
+	}
+	y.low ^= uint64(len(additionalData)) * 8
+	y.high ^= uint64(length) * 8
+	g.table.mul(&y)
+	var s [gcmBlockSize]byte
+	g.block.Encrypt(s[:], g.j0[:])
+	binary.BigEndian.PutUint64(dst, y.low)
+	binary.BigEndian.PutUint64(dst[8:], y.high)
+	subtle.XORBytes(dst, dst, s[:])
+}
+
+// gcmFieldElement gcm中GF(2^128)的元素，low为前8字节、high为后8字节（大端），比特序与gcm相同，即low的最高位为x^0的系数
+type gcmFieldElement struct {
+	low, high uint64
+}
+
+// gcmTable 乘以固定元素x时使用的4比特预计算表，由于比特序相反，k·x保存在下标reverseBits(k)处。
+// 查表下标来自被乘数，访问的缓存行依赖数据，不是常量时间的实现，共享缓存的攻击者可能据此推测H
+type gcmTable [16]gcmFieldElement
+
+// gcmReductionTable 右移4位时溢出的比特模既约多项式后的值
+var gcmReductionTable = [16]uint16{
+	0x0000, 0x1c20, 0x3840, 0x2460, 0x7080, 0x6ca0, 0x48c0, 0x54e0,
+	0xe100, 0xfd20, 0xd940, 0xc560, 0x9180, 0x8da0, 0xa9c0, 0xb5e0,
+}
+
+// newGcmTable 创建乘以x的预计算表
+func newGcmTable(x gcmFieldElement) *gcmTable {
+	t := &gcmTable{}
+	t[gcmReverseBits(1)] = x
+	for i := 2; i < 16; i += 2 {
+		t[gcmReverseBits(i)] = gcmDouble(t[gcmReverseBits(i/2)])
+		double := t[gcmReverseBits(i)]
+		t[gcmReverseBits(i+1)] = gcmFieldElement{low: double.low ^ x.low, high: double.high ^ x.high}
+	}
+	return t
+}
+
+// mul y = y·x，按y的每4比特查表，查表下标依赖数据
+func (t *gcmTable) mul(y *gcmFieldElement) {
+	var z gcmFieldElement
+	for i := 0; i < 2; i++ {
+		word := y.high
+		if i == 1 {
+			word = y.low
+		}
+		// 每次将z乘以x^4后加上word低4位对应的倍数
+		for j := 0; j < 64; j += 4 {
+			msw := z.high & 0xf
+			z.high = z.high>>4 | z.low<<60
+			z.low = z.low>>4 ^ uint64(gcmReductionTable[msw])<<48
+			p := &t[word&0xf]
+			z.low ^= p.low
+			z.high ^= p.high
+			word >>= 4
+		}
+	}
+	*y = z
+}
+
+// update 按霍纳法则将data追加到GHASH中，末尾不完整的分组补0
+func (t *gcmTable) update(y *gcmFieldElement, data []byte) {
+	for len(data) > 0 {
+		var block [gcmBlockSize]byte
+		n := copy(block[:], data)
+		y.low ^= binary.BigEndian.Uint64(block[:8])
+		y.high ^= binary.BigEndian.Uint64(block[8:])
+		t.mul(y)
+		data = data[n:]
+	}
+}
+
+// gcmPow 计算x^n
+func gcmPow(x gcmFieldElement, n int) gcmFieldElement {
+	result := gcmFieldElement{low: 1 << 63}
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			newGcmTable(x).mul(&result)
+		}
+		newGcmTable(x).mul(&x)
+	}
+	return result
+}
+
+// gcmDouble 乘以x^1，由于比特序相反为右移
+func gcmDouble(x gcmFieldElement) gcmFieldElement {
+	double := gcmFieldElement{low: x.low >> 1, high: x.high>>1 | x.low<<63}
+	// 溢出x^127时模既约多项式x^128+x^7+x^2+x+1
+	double.low ^= 0xe100000000000000 & -(x.high & 1)
+	return double
+}
+
+// gcmReverseBits 反转4比特
+func gcmReverseBits(i int) int {
+	i = i<<2&0xc | i>>2&0x3
+	return i<<1&0xa | i>>1&0x5
+}
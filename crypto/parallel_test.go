@@ -0,0 +1,262 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"testing"
+)
+
+// parallelTestOptions 测试使用较小的分片，保证数据被拆分为多个分片且最后一个分片不完整
+var parallelTestOptions = ParallelOptions{Threshold: 1, ChunkSize: 64, Workers: 4}
+
+// parallelTestData 生成测试数据
+func parallelTestData(length int) []byte {
+	data := make([]byte, length)
+	for i := range data {
+		data[i] = byte(i*7 + 3)
+	}
+	return data
+}
+
+func Test_EcbParallel(t *testing.T) {
+	key := mustHex("0123456789abcdeffedcba9876543210")
+	for _, newCipher := range []func([]byte) (cipher.Block, error){aes.NewCipher, NewSm4Cipher} {
+		for _, length := range []int{16, 64, 80, 1024, 1040} {
+			plaintext := parallelTestData(length)
+			block, _ := newCipher(key)
+			want, _ := EcbEncrypt(block, plaintext)
+			got, err := EcbEncryptParallel(newCipher, key, plaintext, parallelTestOptions)
+			if err != nil {
+				t.Fatalf("EcbEncryptParallel() error = %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("EcbEncryptParallel() length %d got = %x, want %x", length, got, want)
+			}
+			decrypted, err := EcbDecryptParallel(newCipher, key, got, parallelTestOptions)
+			if err != nil || !bytes.Equal(decrypted, plaintext) {
+				t.Fatalf("EcbDecryptParallel() length %d got = %x, err = %v", length, decrypted, err)
+			}
+		}
+	}
+	if _, err := EcbEncryptParallel(NewSm4Cipher, key, make([]byte, 17), parallelTestOptions); err == nil {
+		t.Fatal("EcbEncryptParallel() not full blocks error = nil")
+	}
+	if _, err := EcbEncryptParallel(NewSm4Cipher, key, make([]byte, 32), ParallelOptions{Threshold: 1, ChunkSize: 24, Workers: 2}); err == nil {
+		t.Fatal("EcbEncryptParallel() invalid chunk size error = nil")
+	}
+}
+
+func Test_CtrXorParallel(t *testing.T) {
+	key := mustHex("0123456789abcdeffedcba9876543210")
+	ivs := [][]byte{
+		mustHex("000102030405060708090a0b0c0d0e0f"),
+		// 低位计数器在分片之间进位
+		mustHex("00000000000000fffffffffffffffffe"),
+	}
+	for _, newCipher := range []func([]byte) (cipher.Block, error){aes.NewCipher, NewSm4Cipher} {
+		for _, iv := range ivs {
+			for _, length := range []int{1, 63, 64, 65, 1000} {
+				src := parallelTestData(length)
+				block, _ := newCipher(key)
+				want := make([]byte, length)
+				cipher.NewCTR(block, iv).XORKeyStream(want, src)
+				got, err := CtrXorParallel(newCipher, key, iv, src, parallelTestOptions)
+				if err != nil {
+					t.Fatalf("CtrXorParallel() error = %v", err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Fatalf("CtrXorParallel() iv %x length %d got = %x, want %x", iv, length, got, want)
+				}
+			}
+		}
+	}
+	if _, err := CtrXorParallel(NewSm4Cipher, key, make([]byte, 12), nil, parallelTestOptions); err == nil {
+		t.Fatal("CtrXorParallel() invalid iv error = nil")
+	}
+}
+
+// newWrappedAesCipher 隐藏标准库aes分组密码的类型，使并行gcm使用纯Go GHASH
+func newWrappedAesCipher(key []byte) (cipher.Block, error) {
+	block, err := aes.NewCipher(key)
+	return struct{ cipher.Block }{block}, err
+}
+
+func Test_GcmParallel(t *testing.T) {
+	key := mustHex("0123456789abcdeffedcba9876543210")
+	nonce := mustHex("cafebabefacedbaddecaf888")
+	aesBlock, _ := aes.NewCipher(key)
+	wrappedAesBlock, _ := newWrappedAesCipher(key)
+	sm4Block, _ := NewSm4Cipher(key)
+	if !gcmUseStandard(aesBlock) || gcmUseStandard(wrappedAesBlock) || gcmUseStandard(sm4Block) {
+		t.Fatalf("gcmUseStandard() should only be true for crypto/aes blocks")
+	}
+	for _, newCipher := range []func([]byte) (cipher.Block, error){aes.NewCipher, newWrappedAesCipher, NewSm4Cipher} {
+		for _, additionalData := range [][]byte{nil, []byte("header"), parallelTestData(33)} {
+			for _, length := range []int{0, 1, 16, 64, 65, 200, 1024} {
+				plaintext := parallelTestData(length)
+				block, _ := newCipher(key)
+				aead, _ := cipher.NewGCM(block)
+				want := aead.Seal(nil, nonce, plaintext, additionalData)
+				got, err := GcmEncryptParallel(newCipher, key, nonce, plaintext, additionalData, parallelTestOptions)
+				if err != nil {
+					t.Fatalf("GcmEncryptParallel() error = %v", err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Fatalf("GcmEncryptParallel() length %d got = %x, want %x", length, got, want)
+				}
+				decrypted, err := GcmDecryptParallel(newCipher, key, nonce, got, additionalData, parallelTestOptions)
+				if err != nil || !bytes.Equal(decrypted, plaintext) {
+					t.Fatalf("GcmDecryptParallel() length %d got = %x, err = %v", length, decrypted, err)
+				}
+				got[0] ^= 1
+				if _, err := GcmDecryptParallel(newCipher, key, nonce, got, additionalData, parallelTestOptions); err == nil {
+					t.Fatalf("GcmDecryptParallel() length %d tampered error = nil", length)
+				}
+			}
+		}
+	}
+	tests := []struct {
+		name       string
+		nonce      []byte
+		ciphertext []byte
+	}{
+		{name: "nonce", nonce: make([]byte, 16), ciphertext: make([]byte, 32)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := GcmDecryptParallel(NewSm4Cipher, key, tt.nonce, tt.ciphertext, nil, parallelTestOptions); err == nil {
+				t.Fatal("GcmDecryptParallel() error = nil")
+			}
+		})
+	}
+	short, err := GcmDecryptParallel(NewSm4Cipher, key, nonce, make([]byte, 15), nil, parallelTestOptions)
+	if !errors.Is(err, errGcmOpen) {
+		t.Fatalf("GcmDecryptParallel() short got = %x, err = %v", short, err)
+	}
+}
+
+func Test_XtsParallel(t *testing.T) {
+	key := mustHex("2718281828459045235360287471352631415926535897932384626433832795")
+	for _, standard := range []string{XtsStandardIeee, XtsStandardGb} {
+		for _, length := range []int{16, 17, 64, 65, 79, 80, 81, 1000, 1024} {
+			plaintext := parallelTestData(length)
+			want, err := Sm4XtsEncrypt(key, 3, plaintext, standard)
+			if err != nil {
+				t.Fatalf("Sm4XtsEncrypt() error = %v", err)
+			}
+			got, err := XtsEncryptParallel(NewSm4Cipher, key, 3, plaintext, standard, parallelTestOptions)
+			if err != nil {
+				t.Fatalf("XtsEncryptParallel() error = %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("XtsEncryptParallel() %s length %d got = %x, want %x", standard, length, got, want)
+			}
+			decrypted, err := XtsDecryptParallel(NewSm4Cipher, key, 3, got, standard, parallelTestOptions)
+			if err != nil || !bytes.Equal(decrypted, plaintext) {
+				t.Fatalf("XtsDecryptParallel() %s length %d got = %x, err = %v", standard, length, decrypted, err)
+			}
+		}
+	}
+	if _, err := XtsEncryptParallel(aes.NewCipher, key, 0, make([]byte, 15), XtsStandardIeee, parallelTestOptions); err == nil {
+		t.Fatal("XtsEncryptParallel() short data error = nil")
+	}
+}
+
+func Test_ParallelOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options ParallelOptions
+		length  int
+		want    bool
+	}{
+		{name: "default-below", options: ParallelOptions{Workers: 2}, length: parallelDefaultThreshold - 1, want: false},
+		{name: "default-above", options: ParallelOptions{Workers: 2}, length: parallelDefaultThreshold, want: true},
+		{name: "single-worker", options: ParallelOptions{Threshold: 1, Workers: 1}, length: 1 << 20, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.enabled(tt.length); got != tt.want {
+				t.Errorf("enabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// benchmarkParallel 对比顺序实现与并行实现，并行实现使用默认配置，单核环境下会退化为顺序实现
+func benchmarkParallel(b *testing.B, sequential, parallel func(data []byte) error) {
+	data := parallelTestData(4 << 20)
+	for _, bench := range []struct {
+		name string
+		f    func(data []byte) error
+	}{{name: "sequential", f: sequential}, {name: "parallel", f: parallel}} {
+		f := bench.f
+		b.Run(bench.name, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if err := f(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func Benchmark_Sm4Ecb(b *testing.B) {
+	key := make([]byte, 16)
+	block, _ := NewSm4Cipher(key)
+	benchmarkParallel(b, func(data []byte) error {
+		_, err := EcbEncrypt(block, data)
+		return err
+	}, func(data []byte) error {
+		_, err := EcbEncryptParallel(NewSm4Cipher, key, data, ParallelOptions{})
+		return err
+	})
+}
+
+func Benchmark_Sm4Ctr(b *testing.B) {
+	key, iv := make([]byte, 16), make([]byte, 16)
+	benchmarkParallel(b, func(data []byte) error {
+		block, _ := NewSm4Cipher(key)
+		cipher.NewCTR(block, iv).XORKeyStream(make([]byte, len(data)), data)
+		return nil
+	}, func(data []byte) error {
+		_, err := CtrXorParallel(NewSm4Cipher, key, iv, data, ParallelOptions{})
+		return err
+	})
+}
+
+func Benchmark_Sm4Gcm(b *testing.B) {
+	key, nonce := make([]byte, 16), make([]byte, 12)
+	benchmarkParallel(b, func(data []byte) error {
+		_, err := Sm4GcmEncrypt(key, nonce, data, nil)
+		return err
+	}, func(data []byte) error {
+		_, err := GcmEncryptParallel(NewSm4Cipher, key, nonce, data, nil, ParallelOptions{})
+		return err
+	})
+}
+
+func Benchmark_AesGcm(b *testing.B) {
+	key, nonce := make([]byte, 32), make([]byte, 12)
+	benchmarkParallel(b, func(data []byte) error {
+		_, err := AesGcmEncrypt(key, nonce, data, nil)
+		return err
+	}, func(data []byte) error {
+		_, err := GcmEncryptParallel(aes.NewCipher, key, nonce, data, nil, ParallelOptions{})
+		return err
+	})
+}
+
+func Benchmark_Sm4Xts(b *testing.B) {
+	key := make([]byte, 32)
+	key[16] = 1
+	benchmarkParallel(b, func(data []byte) error {
+		_, err := Sm4XtsEncrypt(key, 0, data, XtsStandardIeee)
+		return err
+	}, func(data []byte) error {
+		_, err := XtsEncryptParallel(NewSm4Cipher, key, 0, data, XtsStandardIeee, ParallelOptions{})
+		return err
+	})
+}
@@ -0,0 +1,319 @@
+// Package crypto 密码哈希工具包
+package crypto
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+)
+
+/*
+密码哈希结果统一使用PHC字符串格式：https://github.com/P-H-C/phc-string-format/blob/master/phc-sf-spec.md
+$<id>[$v=<version>]$<param>=<value>(,<param>=<value>)*$<salt>$<hash>
+其中salt和hash使用不带填充的标准base64编码，例如：
+argon2id:   $argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>
+scrypt:     $scrypt$ln=15,r=8,p=1$<salt>$<hash>
+pbkdf2-sm3: $pbkdf2-sm3$i=100000$<salt>$<hash>
+bcrypt保持其自身的模块化格式（$2a$10$...），各语言的bcrypt实现均可直接识别该格式。
+*/
+
+// 密码哈希算法枚举
+const (
+	// PasswordArgon2id argon2id
+	PasswordArgon2id = "argon2id"
+	// PasswordBcrypt bcrypt
+	PasswordBcrypt = "bcrypt"
+	// PasswordScrypt scrypt
+	PasswordScrypt = "scrypt"
+	// PasswordPbkdf2Sm3 使用sm3作为伪随机函数的pbkdf2，满足国密合规要求
+	PasswordPbkdf2Sm3 = "pbkdf2-sm3"
+)
+
+// argon2Version argon2算法版本号（0x13）
+const argon2Version = argon2.Version
+
+// PasswordParams 密码哈希参数，未使用到的字段会被忽略
+type PasswordParams struct {
+	Algorithm string // 哈希算法
+
+	Argon2Time    uint32 // argon2id迭代次数
+	Argon2Memory  uint32 // argon2id内存大小，单位KiB
+	Argon2Threads uint8  // argon2id并行度
+
+	BcryptCost int // bcrypt计算成本
+
+	ScryptLogN int // scrypt的CPU/内存成本，N=2^ScryptLogN
+	ScryptR    int // scrypt块大小
+	ScryptP    int // scrypt并行度
+
+	Pbkdf2Iterations int // pbkdf2迭代次数
+
+	SaltLength int // 盐长度，bcrypt固定为16字节
+	KeyLength  int // 哈希结果长度，bcrypt固定为23字节
+}
+
+// DefaultPasswordParams 获取指定算法的推荐参数，不支持的算法返回错误
+// @param algorithm 哈希算法
+func DefaultPasswordParams(algorithm string) (PasswordParams, error) {
+	params := PasswordParams{Algorithm: strings.ToLower(algorithm), SaltLength: 16, KeyLength: 32}
+	switch params.Algorithm {
+	case PasswordArgon2id:
+		params.Argon2Time, params.Argon2Memory, params.Argon2Threads = 3, 64*1024, 4
+	case PasswordBcrypt:
+		params.BcryptCost = bcrypt.DefaultCost
+	case PasswordScrypt:
+		params.ScryptLogN, params.ScryptR, params.ScryptP = 15, 8, 1
+	case PasswordPbkdf2Sm3:
+		params.Pbkdf2Iterations = 100000
+	default:
+		return PasswordParams{}, fmt.Errorf("unsupported password algorithm: %s", algorithm)
+	}
+	return params, nil
+}
+
+// PasswordHash 计算密码哈希并返回PHC格式字符串
+// @param password 密码明文
+// @param params 哈希参数
+func PasswordHash(password string, params PasswordParams) (string, error) {
+	algorithm := strings.ToLower(params.Algorithm)
+	if algorithm == PasswordBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), params.BcryptCost)
+		if err != nil {
+			return "", fmt.Errorf("bcrypt generate failed: %w", err)
+		}
+		return string(hash), nil
+	}
+	if params.SaltLength <= 0 || params.KeyLength <= 0 {
+		return "", errors.New("salt length and key length must be positive")
+	}
+	salt := make([]byte, params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generate salt failed: %w", err)
+	}
+	phc := &phcHash{id: algorithm, salt: salt}
+	switch algorithm {
+	case PasswordArgon2id:
+		phc.version = argon2Version
+		phc.params = []phcParam{
+			{"m", int64(params.Argon2Memory)},
+			{"t", int64(params.Argon2Time)},
+			{"p", int64(params.Argon2Threads)},
+		}
+	case PasswordScrypt:
+		phc.params = []phcParam{
+			{"ln", int64(params.ScryptLogN)},
+			{"r", int64(params.ScryptR)},
+			{"p", int64(params.ScryptP)},
+		}
+	case PasswordPbkdf2Sm3:
+		phc.params = []phcParam{{"i", int64(params.Pbkdf2Iterations)}}
+	default:
+		return "", fmt.Errorf("unsupported password algorithm: %s", params.Algorithm)
+	}
+	hash, err := phc.derive(password, params.KeyLength)
+	if err != nil {
+		return "", err
+	}
+	phc.hash = hash
+	return phc.String(), nil
+}
+
+// PasswordVerify 校验密码与哈希是否匹配，哈希比较使用常量时间
+// @param password 密码明文
+// @param encoded PasswordHash返回的哈希字符串
+func PasswordVerify(password, encoded string) (bool, error) {
+	if isBcryptHash(encoded) {
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("bcrypt compare failed: %w", err)
+		}
+		return true, nil
+	}
+	phc, err := parsePhcHash(encoded)
+	if err != nil {
+		return false, err
+	}
+	hash, err := phc.derive(password, len(phc.hash))
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(hash, phc.hash) == 1, nil
+}
+
+// PasswordNeedsRehash 判断已存储的哈希是否需要使用新参数重新计算，
+// 算法、算法参数、盐长度或哈希长度与params不一致时返回true，通常在用户登录校验成功后调用
+// @param encoded 已存储的哈希字符串
+// @param params 当前使用的哈希参数
+func PasswordNeedsRehash(encoded string, params PasswordParams) (bool, error) {
+	algorithm := strings.ToLower(params.Algorithm)
+	if isBcryptHash(encoded) {
+		cost, err := bcrypt.Cost([]byte(encoded))
+		if err != nil {
+			return false, fmt.Errorf("bcrypt parse cost failed: %w", err)
+		}
+		return algorithm != PasswordBcrypt || cost != params.BcryptCost, nil
+	}
+	phc, err := parsePhcHash(encoded)
+	if err != nil {
+		return false, err
+	}
+	if phc.id != algorithm || len(phc.salt) != params.SaltLength || len(phc.hash) != params.KeyLength {
+		return true, nil
+	}
+	switch algorithm {
+	case PasswordArgon2id:
+		return phc.version != argon2Version ||
+			phc.param("m") != int64(params.Argon2Memory) ||
+			phc.param("t") != int64(params.Argon2Time) ||
+			phc.param("p") != int64(params.Argon2Threads), nil
+	case PasswordScrypt:
+		return phc.param("ln") != int64(params.ScryptLogN) ||
+			phc.param("r") != int64(params.ScryptR) ||
+			phc.param("p") != int64(params.ScryptP), nil
+	default:
+		return phc.param("i") != int64(params.Pbkdf2Iterations), nil
+	}
+}
+
+// isBcryptHash 判断是否为bcrypt格式的哈希
+func isBcryptHash(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") ||
+		strings.HasPrefix(encoded, "$2y$")
+}
+
+// phcParam PHC格式中的单个参数
+type phcParam struct {
+	name  string
+	value int64
+}
+
+// phcHash PHC格式哈希字符串解析结果
+type phcHash struct {
+	id      string     // 算法标识
+	version int        // 算法版本，0表示不存在
+	params  []phcParam // 算法参数，保持顺序
+	salt    []byte     // 盐
+	hash    []byte     // 哈希结果
+}
+
+// parsePhcHash 解析PHC格式哈希字符串
+func parsePhcHash(encoded string) (*phcHash, error) {
+	fields := strings.Split(encoded, "$")
+	if len(fields) < 5 || fields[0] != "" {
+		return nil, errors.New("invalid phc hash format")
+	}
+	phc := &phcHash{id: fields[1]}
+	fields = fields[2:]
+	if strings.HasPrefix(fields[0], "v=") {
+		version, err := strconv.Atoi(strings.TrimPrefix(fields[0], "v="))
+		if err != nil {
+			return nil, fmt.Errorf("parse version failed: %w", err)
+		}
+		phc.version = version
+		fields = fields[1:]
+	}
+	if len(fields) != 3 {
+		return nil, errors.New("invalid phc hash format")
+	}
+	for _, kv := range strings.Split(fields[0], ",") {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid phc param: %s", kv)
+		}
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse param %s failed: %w", name, err)
+		}
+		phc.params = append(phc.params, phcParam{name: name, value: v})
+	}
+	var err error
+	if phc.salt, err = base64.RawStdEncoding.DecodeString(fields[1]); err != nil {
+		return nil, fmt.Errorf("decode salt failed: %w", err)
+	}
+	if phc.hash, err = base64.RawStdEncoding.DecodeString(fields[2]); err != nil {
+		return nil, fmt.Errorf("decode hash failed: %w", err)
+	}
+	if len(phc.hash) == 0 {
+		return nil, errors.New("empty hash")
+	}
+	return phc, nil
+}
+
+// param 获取参数值，不存在时返回-1
+func (h *phcHash) param(name string) int64 {
+	for _, p := range h.params {
+		if p.name == name {
+			return p.value
+		}
+	}
+	return -1
+}
+
+// derive 根据算法参数计算密码哈希
+func (h *phcHash) derive(password string, keyLength int) ([]byte, error) {
+	switch h.id {
+	case PasswordArgon2id:
+		if h.version != argon2Version {
+			return nil, fmt.Errorf("unsupported argon2 version: %d", h.version)
+		}
+		m, t, p := h.param("m"), h.param("t"), h.param("p")
+		if m <= 0 || m > 1<<32-1 || t <= 0 || t > 1<<32-1 || p <= 0 || p > 255 {
+			return nil, errors.New("invalid argon2id params")
+		}
+		return argon2.IDKey([]byte(password), h.salt, uint32(t), uint32(m), uint8(p), uint32(keyLength)), nil
+	case PasswordScrypt:
+		ln, r, p := h.param("ln"), h.param("r"), h.param("p")
+		if ln <= 0 || ln >= 63 || r <= 0 || p <= 0 {
+			return nil, errors.New("invalid scrypt params")
+		}
+		hash, err := scrypt.Key([]byte(password), h.salt, 1<<ln, int(r), int(p), keyLength)
+		if err != nil {
+			return nil, fmt.Errorf("scrypt derive failed: %w", err)
+		}
+		return hash, nil
+	case PasswordPbkdf2Sm3:
+		i := h.param("i")
+		if i <= 0 || i > 1<<31-1 {
+			return nil, errors.New("invalid pbkdf2 params")
+		}
+		hash, err := pbkdf2.Key(newSm3, password, h.salt, int(i), keyLength)
+		if err != nil {
+			return nil, fmt.Errorf("pbkdf2 derive failed: %w", err)
+		}
+		return hash, nil
+	default:
+		return nil, fmt.Errorf("unsupported password algorithm: %s", h.id)
+	}
+}
+
+// String 编码为PHC格式字符串
+func (h *phcHash) String() string {
+	var sb strings.Builder
+	sb.WriteString("$" + h.id)
+	if h.version != 0 {
+		sb.WriteString("$v=" + strconv.Itoa(h.version))
+	}
+	for i, p := range h.params {
+		if i == 0 {
+			sb.WriteString("$")
+		} else {
+			sb.WriteString(",")
+		}
+		sb.WriteString(p.name + "=" + strconv.FormatInt(p.value, 10))
+	}
+	sb.WriteString("$" + base64.RawStdEncoding.EncodeToString(h.salt))
+	sb.WriteString("$" + base64.RawStdEncoding.EncodeToString(h.hash))
+	return sb.String()
+}
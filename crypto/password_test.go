@@ -0,0 +1,117 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+// testPasswordParams 测试使用的低成本参数
+var testPasswordParams = []PasswordParams{
+	{Algorithm: PasswordArgon2id, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: 1, SaltLength: 16, KeyLength: 32},
+	{Algorithm: PasswordBcrypt, BcryptCost: 4},
+	{Algorithm: PasswordScrypt, ScryptLogN: 4, ScryptR: 8, ScryptP: 1, SaltLength: 16, KeyLength: 32},
+	{Algorithm: PasswordPbkdf2Sm3, Pbkdf2Iterations: 10, SaltLength: 16, KeyLength: 32},
+	{Algorithm: PasswordPbkdf2Sm3, Pbkdf2Iterations: 10, SaltLength: 16, KeyLength: 48},
+}
+
+func Test_PasswordHashVerify(t *testing.T) {
+	for _, params := range testPasswordParams {
+		t.Run(fmt.Sprintf("%s-%d", params.Algorithm, params.KeyLength), func(t *testing.T) {
+			encoded, err := PasswordHash("Hello World", params)
+			if err != nil {
+				t.Errorf("PasswordHash() error = %v", err)
+				return
+			}
+			ok, err := PasswordVerify("Hello World", encoded)
+			if err != nil || !ok {
+				t.Errorf("PasswordVerify() got = %v, error = %v, want true", ok, err)
+			}
+			ok, err = PasswordVerify("Hello World!", encoded)
+			if err != nil || ok {
+				t.Errorf("PasswordVerify() with wrong password got = %v, error = %v, want false", ok, err)
+			}
+			needsRehash, err := PasswordNeedsRehash(encoded, params)
+			if err != nil || needsRehash {
+				t.Errorf("PasswordNeedsRehash() got = %v, error = %v, want false", needsRehash, err)
+			}
+		})
+	}
+}
+
+func Test_PasswordVerifyScryptVector(t *testing.T) {
+	// RFC 7914 第12节测试向量
+	hash, _ := hex.DecodeString("fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b373162" +
+		"2eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640")
+	encoded := "$scrypt$ln=10,r=8,p=16$" + base64.RawStdEncoding.EncodeToString([]byte("NaCl")) +
+		"$" + base64.RawStdEncoding.EncodeToString(hash)
+	ok, err := PasswordVerify("password", encoded)
+	if err != nil || !ok {
+		t.Errorf("PasswordVerify() got = %v, error = %v, want true", ok, err)
+	}
+}
+
+func Test_PasswordNeedsRehash(t *testing.T) {
+	argon2Params := testPasswordParams[0]
+	encoded, err := PasswordHash("Hello World", argon2Params)
+	if err != nil {
+		t.Fatalf("PasswordHash() error = %v", err)
+	}
+	upgraded := argon2Params
+	upgraded.Argon2Time = 2
+	tests := []struct {
+		name   string
+		params PasswordParams
+		want   bool
+	}{
+		{name: "same", params: argon2Params, want: false},
+		{name: "upgraded", params: upgraded, want: true},
+		{name: "algorithm", params: testPasswordParams[2], want: true},
+		{name: "bcrypt", params: testPasswordParams[1], want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PasswordNeedsRehash(encoded, tt.params)
+			if err != nil {
+				t.Errorf("PasswordNeedsRehash() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("PasswordNeedsRehash() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_PasswordVerifyMalformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{name: "empty", encoded: ""},
+		{name: "unknown", encoded: "$md5$i=1$c2FsdA$aGFzaA"},
+		{name: "fields", encoded: "$argon2id$v=19$m=64,t=1,p=1$c2FsdA"},
+		{name: "param", encoded: "$pbkdf2-sm3$i=x$c2FsdA$aGFzaA"},
+		{name: "salt", encoded: "$pbkdf2-sm3$i=1$!!$aGFzaA"},
+		{name: "zero", encoded: "$scrypt$ln=0,r=8,p=1$c2FsdA$aGFzaA"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := PasswordVerify("Hello World", tt.encoded); err == nil {
+				t.Errorf("PasswordVerify() error = nil, wantErr true")
+			}
+		})
+	}
+}
+
+func Test_DefaultPasswordParams(t *testing.T) {
+	for _, algorithm := range []string{PasswordArgon2id, PasswordBcrypt, PasswordScrypt, PasswordPbkdf2Sm3} {
+		if _, err := DefaultPasswordParams(algorithm); err != nil {
+			t.Errorf("DefaultPasswordParams(%s) error = %v", algorithm, err)
+		}
+	}
+	if _, err := DefaultPasswordParams("md5"); err == nil {
+		t.Errorf("DefaultPasswordParams(md5) error = nil, wantErr true")
+	}
+}
@@ -0,0 +1,285 @@
+// Package crypto rsa非对称加密工具包
+package crypto
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+/*
+rsa单次加密的明文长度受密钥长度限制：
+OAEP:        k - 2*hLen - 2
+PKCS#1 v1.5: k - 11
+其中k为密钥字节长度，hLen为哈希算法输出长度。
+本文件的加密函数会将超长明文按上述长度分段加密，密文为各段密文（长度均为k）的直接拼接，解密时按k切分后逐段解密。
+*/
+
+// CreateRsaPrivateKeyWithPem 通过pem编码内容构造rsa私钥，支持PKCS#1（RSA PRIVATE KEY）与PKCS#8（PRIVATE KEY）
+func CreateRsaPrivateKeyWithPem(privateKey []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return nil, errors.New("decode pem failed")
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse pkcs1 private key failed: %w", err)
+		}
+		return key, nil
+	case "PRIVATE KEY":
+		return parseRsaPkcs8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported pem type: %s", block.Type)
+	}
+}
+
+// CreateRsaPrivateKeyWithDer 通过der编码内容构造rsa私钥，依次尝试PKCS#1与PKCS#8格式
+func CreateRsaPrivateKeyWithDer(privateKey []byte) (*rsa.PrivateKey, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(privateKey); err == nil {
+		return key, nil
+	}
+	return parseRsaPkcs8PrivateKey(privateKey)
+}
+
+// CreateRsaPrivateKeyWithBase64 通过base64编码的der内容构造rsa私钥
+func CreateRsaPrivateKeyWithBase64(privateKey string) (*rsa.PrivateKey, error) {
+	k, err := base64.StdEncoding.DecodeString(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode with base64 failed: %w", err)
+	}
+	return CreateRsaPrivateKeyWithDer(k)
+}
+
+// CreateRsaPublicKeyWithPem 通过pem编码内容构造rsa公钥，支持PKCS#1（RSA PUBLIC KEY）与PKIX（PUBLIC KEY）
+func CreateRsaPublicKeyWithPem(publicKey []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(publicKey)
+	if block == nil {
+		return nil, errors.New("decode pem failed")
+	}
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse pkcs1 public key failed: %w", err)
+		}
+		return key, nil
+	case "PUBLIC KEY":
+		return parseRsaPkixPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported pem type: %s", block.Type)
+	}
+}
+
+// CreateRsaPublicKeyWithDer 通过der编码内容构造rsa公钥，依次尝试PKIX与PKCS#1格式
+func CreateRsaPublicKeyWithDer(publicKey []byte) (*rsa.PublicKey, error) {
+	if key, err := parseRsaPkixPublicKey(publicKey); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS1PublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key failed: %w", err)
+	}
+	return key, nil
+}
+
+// CreateRsaPublicKeyWithBase64 通过base64编码的der内容构造rsa公钥
+func CreateRsaPublicKeyWithBase64(publicKey string) (*rsa.PublicKey, error) {
+	k, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode with base64 failed: %w", err)
+	}
+	return CreateRsaPublicKeyWithDer(k)
+}
+
+// RsaEncryptOaep rsa-oaep加密，超长明文会分段加密
+// @param publicKey 公钥
+// @param plaintext 明文内容
+// @param label 标签，可以为空，解密时需要使用相同的标签
+// @param hash 哈希算法，例如crypto.SHA256
+func RsaEncryptOaep(publicKey *rsa.PublicKey, plaintext, label []byte, hash crypto.Hash) ([]byte, error) {
+	if !hash.Available() {
+		return nil, errors.New("hash function not available")
+	}
+	chunkSize := publicKey.Size() - 2*hash.Size() - 2
+	return rsaEncryptChunks(publicKey, plaintext, chunkSize, func(chunk []byte) ([]byte, error) {
+		return rsa.EncryptOAEP(hash.New(), rand.Reader, publicKey, chunk, label)
+	})
+}
+
+// RsaDecryptOaep rsa-oaep解密，支持分段加密的密文
+// @param privateKey 私钥
+// @param ciphertext 密文
+// @param label 标签，需要与加密时一致
+// @param hash 哈希算法，需要与加密时一致
+func RsaDecryptOaep(privateKey *rsa.PrivateKey, ciphertext, label []byte, hash crypto.Hash) ([]byte, error) {
+	if !hash.Available() {
+		return nil, errors.New("hash function not available")
+	}
+	return rsaDecryptChunks(privateKey, ciphertext, func(chunk []byte) ([]byte, error) {
+		return rsa.DecryptOAEP(hash.New(), nil, privateKey, chunk, label)
+	})
+}
+
+// RsaEncryptPkcs1v15 rsa PKCS#1 v1.5加密，超长明文会分段加密
+// @param publicKey 公钥
+// @param plaintext 明文内容
+func RsaEncryptPkcs1v15(publicKey *rsa.PublicKey, plaintext []byte) ([]byte, error) {
+	chunkSize := publicKey.Size() - 11
+	return rsaEncryptChunks(publicKey, plaintext, chunkSize, func(chunk []byte) ([]byte, error) {
+		return rsa.EncryptPKCS1v15(rand.Reader, publicKey, chunk)
+	})
+}
+
+// RsaDecryptPkcs1v15 rsa PKCS#1 v1.5解密，支持分段加密的密文
+// @param privateKey 私钥
+// @param ciphertext 密文
+func RsaDecryptPkcs1v15(privateKey *rsa.PrivateKey, ciphertext []byte) ([]byte, error) {
+	return rsaDecryptChunks(privateKey, ciphertext, func(chunk []byte) ([]byte, error) {
+		return rsa.DecryptPKCS1v15(nil, privateKey, chunk)
+	})
+}
+
+// RsaSignPss rsa-pss签名，盐长度与哈希长度相同
+// @param privateKey 私钥
+// @param data 待签名内容，函数内部会使用hash计算摘要
+// @param hash 哈希算法，例如crypto.SHA256
+func RsaSignPss(privateKey *rsa.PrivateKey, data []byte, hash crypto.Hash) ([]byte, error) {
+	digest, err := rsaDigest(data, hash)
+	if err != nil {
+		return nil, err
+	}
+	opts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash}
+	signature, err := rsa.SignPSS(rand.Reader, privateKey, hash, digest, opts)
+	if err != nil {
+		return nil, fmt.Errorf("sign failed: %w", err)
+	}
+	return signature, nil
+}
+
+// RsaVerifyPss rsa-pss验签，签名不匹配时返回错误，盐长度自动识别
+// @param publicKey 公钥
+// @param data 签名原文
+// @param signature 签名
+// @param hash 哈希算法，需要与签名时一致
+func RsaVerifyPss(publicKey *rsa.PublicKey, data, signature []byte, hash crypto.Hash) error {
+	digest, err := rsaDigest(data, hash)
+	if err != nil {
+		return err
+	}
+	opts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto, Hash: hash}
+	if err := rsa.VerifyPSS(publicKey, hash, digest, signature, opts); err != nil {
+		return fmt.Errorf("verify failed: %w", err)
+	}
+	return nil
+}
+
+// RsaSignPkcs1v15 rsa PKCS#1 v1.5签名
+// @param privateKey 私钥
+// @param data 待签名内容，函数内部会使用hash计算摘要
+// @param hash 哈希算法，例如crypto.SHA256
+func RsaSignPkcs1v15(privateKey *rsa.PrivateKey, data []byte, hash crypto.Hash) ([]byte, error) {
+	digest, err := rsaDigest(data, hash)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := rsa.SignPKCS1v15(nil, privateKey, hash, digest)
+	if err != nil {
+		return nil, fmt.Errorf("sign failed: %w", err)
+	}
+	return signature, nil
+}
+
+// RsaVerifyPkcs1v15 rsa PKCS#1 v1.5验签，签名不匹配时返回错误
+// @param publicKey 公钥
+// @param data 签名原文
+// @param signature 签名
+// @param hash 哈希算法，需要与签名时一致
+func RsaVerifyPkcs1v15(publicKey *rsa.PublicKey, data, signature []byte, hash crypto.Hash) error {
+	digest, err := rsaDigest(data, hash)
+	if err != nil {
+		return err
+	}
+	if err := rsa.VerifyPKCS1v15(publicKey, hash, digest, signature); err != nil {
+		return fmt.Errorf("verify failed: %w", err)
+	}
+	return nil
+}
+
+// parseRsaPkcs8PrivateKey 解析PKCS#8格式的rsa私钥
+func parseRsaPkcs8PrivateKey(der []byte) (*rsa.PrivateKey, error) {
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("parse pkcs8 private key failed: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key type %T is not rsa", key)
+	}
+	return rsaKey, nil
+}
+
+// parseRsaPkixPublicKey 解析PKIX格式的rsa公钥
+func parseRsaPkixPublicKey(der []byte) (*rsa.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("parse pkix public key failed: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key type %T is not rsa", key)
+	}
+	return rsaKey, nil
+}
+
+// rsaDigest 计算签名摘要
+func rsaDigest(data []byte, hash crypto.Hash) ([]byte, error) {
+	if !hash.Available() {
+		return nil, errors.New("hash function not available")
+	}
+	h := hash.New()
+	h.Write(data)
+	return h.Sum(nil), nil
+}
+
+// rsaEncryptChunks 按chunkSize分段加密
+func rsaEncryptChunks(publicKey *rsa.PublicKey, plaintext []byte, chunkSize int,
+	encrypt func(chunk []byte) ([]byte, error)) ([]byte, error) {
+	if chunkSize <= 0 {
+		return nil, errors.New("key size too small")
+	}
+	ciphertext := make([]byte, 0, (len(plaintext)/chunkSize+1)*publicKey.Size())
+	for start := 0; start == 0 || start < len(plaintext); start += chunkSize {
+		end := min(start+chunkSize, len(plaintext))
+		c, err := encrypt(plaintext[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("encrypt failed: %w", err)
+		}
+		ciphertext = append(ciphertext, c...)
+	}
+	return ciphertext, nil
+}
+
+// rsaDecryptChunks 按密钥长度分段解密
+func rsaDecryptChunks(privateKey *rsa.PrivateKey, ciphertext []byte,
+	decrypt func(chunk []byte) ([]byte, error)) ([]byte, error) {
+	k := privateKey.Size()
+	if len(ciphertext) == 0 || len(ciphertext)%k != 0 {
+		return nil, errors.New("ciphertext not full chunks")
+	}
+	plaintext := make([]byte, 0, len(ciphertext))
+	for start := 0; start < len(ciphertext); start += k {
+		p, err := decrypt(ciphertext[start : start+k])
+		if err != nil {
+			return nil, fmt.Errorf("decrypt failed: %w", err)
+		}
+		plaintext = append(plaintext, p...)
+	}
+	return plaintext, nil
+}
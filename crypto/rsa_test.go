@@ -0,0 +1,222 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"reflect"
+	"testing"
+)
+
+var rsaPrivateKey *rsa.PrivateKey
+
+func init() {
+	var err error
+	rsaPrivateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func Test_CreateRsaPrivateKey(t *testing.T) {
+	pkcs1 := x509.MarshalPKCS1PrivateKey(rsaPrivateKey)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(rsaPrivateKey)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		create  func() (*rsa.PrivateKey, error)
+		wantErr bool
+	}{
+		{
+			name: "pem-pkcs1",
+			create: func() (*rsa.PrivateKey, error) {
+				return CreateRsaPrivateKeyWithPem(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: pkcs1}))
+			},
+		},
+		{
+			name: "pem-pkcs8",
+			create: func() (*rsa.PrivateKey, error) {
+				return CreateRsaPrivateKeyWithPem(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+			},
+		},
+		{
+			name:   "der-pkcs1",
+			create: func() (*rsa.PrivateKey, error) { return CreateRsaPrivateKeyWithDer(pkcs1) },
+		},
+		{
+			name: "base64-pkcs8",
+			create: func() (*rsa.PrivateKey, error) {
+				return CreateRsaPrivateKeyWithBase64(base64.StdEncoding.EncodeToString(pkcs8))
+			},
+		},
+		{
+			name:    "invalid",
+			create:  func() (*rsa.PrivateKey, error) { return CreateRsaPrivateKeyWithPem([]byte("Hello World")) },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.create()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateRsaPrivateKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !rsaPrivateKey.Equal(got) {
+				t.Errorf("CreateRsaPrivateKey() got different key")
+			}
+		})
+	}
+}
+
+func Test_CreateRsaPublicKey(t *testing.T) {
+	pkcs1 := x509.MarshalPKCS1PublicKey(&rsaPrivateKey.PublicKey)
+	pkix, err := x509.MarshalPKIXPublicKey(&rsaPrivateKey.PublicKey)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey() error = %v", err)
+	}
+	tests := []struct {
+		name   string
+		create func() (*rsa.PublicKey, error)
+	}{
+		{
+			name: "pem-pkcs1",
+			create: func() (*rsa.PublicKey, error) {
+				return CreateRsaPublicKeyWithPem(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: pkcs1}))
+			},
+		},
+		{
+			name: "pem-pkix",
+			create: func() (*rsa.PublicKey, error) {
+				return CreateRsaPublicKeyWithPem(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}))
+			},
+		},
+		{
+			name:   "der-pkcs1",
+			create: func() (*rsa.PublicKey, error) { return CreateRsaPublicKeyWithDer(pkcs1) },
+		},
+		{
+			name: "base64-pkix",
+			create: func() (*rsa.PublicKey, error) {
+				return CreateRsaPublicKeyWithBase64(base64.StdEncoding.EncodeToString(pkix))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.create()
+			if err != nil {
+				t.Errorf("CreateRsaPublicKey() error = %v", err)
+				return
+			}
+			if !rsaPrivateKey.PublicKey.Equal(got) {
+				t.Errorf("CreateRsaPublicKey() got different key")
+			}
+		})
+	}
+}
+
+func Test_RsaEncryptDecrypt(t *testing.T) {
+	publicKey := &rsaPrivateKey.PublicKey
+	tests := []struct {
+		name      string
+		plaintext []byte
+		encrypt   func(plaintext []byte) ([]byte, error)
+		decrypt   func(ciphertext []byte) ([]byte, error)
+	}{
+		{
+			name:      "oaep",
+			plaintext: []byte("Hello World"),
+			encrypt: func(p []byte) ([]byte, error) {
+				return RsaEncryptOaep(publicKey, p, []byte("label"), crypto.SHA256)
+			},
+			decrypt: func(c []byte) ([]byte, error) {
+				return RsaDecryptOaep(rsaPrivateKey, c, []byte("label"), crypto.SHA256)
+			},
+		},
+		{
+			name:      "oaep-chunks",
+			plaintext: bytes.Repeat([]byte("Hello World"), 100),
+			encrypt: func(p []byte) ([]byte, error) {
+				return RsaEncryptOaep(publicKey, p, nil, crypto.SHA1)
+			},
+			decrypt: func(c []byte) ([]byte, error) {
+				return RsaDecryptOaep(rsaPrivateKey, c, nil, crypto.SHA1)
+			},
+		},
+		{
+			name:      "pkcs1v15-empty",
+			plaintext: []byte{},
+			encrypt:   func(p []byte) ([]byte, error) { return RsaEncryptPkcs1v15(publicKey, p) },
+			decrypt:   func(c []byte) ([]byte, error) { return RsaDecryptPkcs1v15(rsaPrivateKey, c) },
+		},
+		{
+			name:      "pkcs1v15-chunks",
+			plaintext: bytes.Repeat([]byte("Hello World"), 100),
+			encrypt:   func(p []byte) ([]byte, error) { return RsaEncryptPkcs1v15(publicKey, p) },
+			decrypt:   func(c []byte) ([]byte, error) { return RsaDecryptPkcs1v15(rsaPrivateKey, c) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ciphertext, err := tt.encrypt(tt.plaintext)
+			if err != nil {
+				t.Errorf("encrypt error = %v", err)
+				return
+			}
+			if len(ciphertext)%publicKey.Size() != 0 {
+				t.Errorf("encrypt got ciphertext length %d", len(ciphertext))
+			}
+			got, err := tt.decrypt(ciphertext)
+			if err != nil {
+				t.Errorf("decrypt error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.plaintext) {
+				t.Errorf("decrypt got = %v, want %v", got, tt.plaintext)
+			}
+		})
+	}
+}
+
+func Test_RsaSignVerify(t *testing.T) {
+	publicKey := &rsaPrivateKey.PublicKey
+	data := []byte("Hello World")
+	tests := []struct {
+		name   string
+		sign   func(data []byte) ([]byte, error)
+		verify func(data, signature []byte) error
+	}{
+		{
+			name:   "pss",
+			sign:   func(d []byte) ([]byte, error) { return RsaSignPss(rsaPrivateKey, d, crypto.SHA256) },
+			verify: func(d, s []byte) error { return RsaVerifyPss(publicKey, d, s, crypto.SHA256) },
+		},
+		{
+			name:   "pkcs1v15",
+			sign:   func(d []byte) ([]byte, error) { return RsaSignPkcs1v15(rsaPrivateKey, d, crypto.SHA256) },
+			verify: func(d, s []byte) error { return RsaVerifyPkcs1v15(publicKey, d, s, crypto.SHA256) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature, err := tt.sign(data)
+			if err != nil {
+				t.Errorf("sign error = %v", err)
+				return
+			}
+			if err := tt.verify(data, signature); err != nil {
+				t.Errorf("verify error = %v", err)
+			}
+			if err := tt.verify([]byte("Hello World!"), signature); err == nil {
+				t.Errorf("verify tampered data error = nil, wantErr true")
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Package crypto 加密cookie工具包
+package crypto
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+/*
+SecureCookie使用aead加密cookie的值，客户端无法查看或修改内容，格式为<kid>.BASE64URL(nonce||密文||标签)，
+其中base64url不带填充，明文为8字节过期时间（unix秒，大端） || 业务值。
+cookie名称与kid作为附加认证数据，因此无法将一个cookie的值挪用到另一个名称的cookie上，也无法修改kid。
+服务端校验过期时间，不依赖浏览器的Expires，防止客户端延长有效期。
+实际密钥由主密钥通过DeriveKey派生（info为"tutils-cookie/"+算法名称），与令牌相同支持按kid轮换密钥。
+浏览器限制单个cookie为4096字节，编码后超过该长度时返回错误。
+校验失败时返回ErrMalformed、ErrTampered、ErrExpired。
+*/
+
+// 加密cookie相关常量
+const (
+	secureCookieInfoPrefix = "tutils-cookie/"
+	secureCookieMaxLength  = 4096
+	secureCookieTimeLength = 8
+)
+
+// SecureCookie 加密cookie编解码器
+type SecureCookie struct {
+	cipherName string       // 加密算法
+	provider   KeyProvider  // 密钥提供者
+	keyID      string       // 加密使用的密钥id
+	options    TokenOptions // 配置
+}
+
+// NewSecureCookie 创建加密cookie编解码器
+// @param cipherName 加密算法，支持Encrypt中的全部算法
+// @param provider 密钥提供者
+// @param keyID 加密使用的密钥id，不能包含"."
+// @param options 配置，与令牌共用
+func NewSecureCookie(cipherName string, provider KeyProvider, keyID string, options TokenOptions) (*SecureCookie, error) {
+	cipherName = strings.ToLower(cipherName)
+	if _, err := getCipherSuite(cipherName); err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return nil, errors.New("key provider is nil")
+	}
+	if err := validateTokenKeyID(keyID); err != nil {
+		return nil, err
+	}
+	return &SecureCookie{cipherName: cipherName, provider: provider, keyID: keyID, options: options}, nil
+}
+
+// Encode 加密cookie的值
+// @param name cookie名称
+// @param value cookie的值
+// @param ttl 有效期
+func (c *SecureCookie) Encode(name string, value []byte, ttl time.Duration) (string, error) {
+	if ttl <= 0 {
+		return "", errors.New("ttl must be positive")
+	}
+	key, err := c.key(c.keyID)
+	if err != nil {
+		return "", err
+	}
+	plaintext := make([]byte, secureCookieTimeLength, secureCookieTimeLength+len(value))
+	binary.BigEndian.PutUint64(plaintext, uint64(c.now().Add(ttl).Unix()))
+	plaintext = append(plaintext, value...)
+	ciphertext, err := Encrypt(c.cipherName, key, plaintext, c.additionalData(name, c.keyID))
+	if err != nil {
+		return "", fmt.Errorf("encrypt cookie failed: %w", err)
+	}
+	encoded := c.keyID + "." + base64.RawURLEncoding.EncodeToString(ciphertext)
+	if len(name)+1+len(encoded) > secureCookieMaxLength {
+		return "", fmt.Errorf("cookie exceeds %d bytes", secureCookieMaxLength)
+	}
+	return encoded, nil
+}
+
+// Decode 解密cookie的值
+// @param name cookie名称，需要与加密时一致
+// @param value 加密后的cookie值
+func (c *SecureCookie) Decode(name, value string) ([]byte, error) {
+	kid, encoded, ok := strings.Cut(value, ".")
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid cookie format", ErrMalformed)
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: decode cookie failed: %w", ErrMalformed, err)
+	}
+	key, err := c.key(kid)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTampered, err)
+	}
+	plaintext, err := Decrypt(c.cipherName, key, ciphertext, c.additionalData(name, kid))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTampered, err)
+	}
+	if len(plaintext) < secureCookieTimeLength {
+		return nil, fmt.Errorf("%w: cookie too short", ErrMalformed)
+	}
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(plaintext)), 0)
+	if !c.now().Add(-c.options.ClockSkew).Before(expiresAt) {
+		return nil, fmt.Errorf("%w: cookie expired at %s", ErrExpired, expiresAt.Format(time.RFC3339))
+	}
+	return plaintext[secureCookieTimeLength:], nil
+}
+
+// NewCookie 创建加密后的cookie，默认设置Path为"/"、HttpOnly、Secure与SameSite=Lax，可以在返回后修改
+// @param name cookie名称
+// @param value cookie的值
+// @param ttl 有效期，同时用于设置Expires与MaxAge
+func (c *SecureCookie) NewCookie(name string, value []byte, ttl time.Duration) (*http.Cookie, error) {
+	encoded, err := c.Encode(name, value, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Cookie{
+		Name:     name,
+		Value:    encoded,
+		Path:     "/",
+		Expires:  c.now().Add(ttl),
+		MaxAge:   int(ttl / time.Second),
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}, nil
+}
+
+// DecodeRequest 读取并解密http请求中的cookie
+// @param r http请求
+// @param name cookie名称
+func (c *SecureCookie) DecodeRequest(r *http.Request, name string) ([]byte, error) {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.Decode(name, cookie.Value)
+}
+
+// key 获取派生后的密钥
+func (c *SecureCookie) key(kid string) ([]byte, error) {
+	masterKey, err := c.provider.Key(kid)
+	if err != nil {
+		return nil, fmt.Errorf("get key failed: %w", err)
+	}
+	suite, _ := getCipherSuite(c.cipherName)
+	return DeriveKey(suite.hash, masterKey, secureCookieInfoPrefix+c.cipherName, suite.keySize)
+}
+
+// additionalData 附加认证数据，cookie名称使用长度前缀，避免与kid拼接时产生歧义
+func (c *SecureCookie) additionalData(name, kid string) []byte {
+	data := binary.BigEndian.AppendUint32(nil, uint32(len(name)))
+	data = append(data, name...)
+	return append(data, kid...)
+}
+
+// now 获取当前时间
+func (c *SecureCookie) now() time.Time {
+	if c.options.Now != nil {
+		return c.options.Now()
+	}
+	return time.Now()
+}
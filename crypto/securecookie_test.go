@@ -0,0 +1,91 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_SecureCookie(t *testing.T) {
+	provider := StaticKeyProvider{"k1": []byte("0123456789abcdef"), "k2": []byte("fedcba9876543210")}
+	for _, cipherName := range []string{CipherAesGcm, CipherSm4Gcm, CipherXChaCha20Poly1305} {
+		t.Run(cipherName, func(t *testing.T) {
+			clock := &testClock{now: time.Unix(1700000000, 0)}
+			options := TokenOptions{ClockSkew: 30 * time.Second, Now: clock.Now}
+			old, err := NewSecureCookie(cipherName, provider, "k1", options)
+			if err != nil {
+				t.Fatalf("NewSecureCookie() error = %v", err)
+			}
+			cookie, err := old.NewCookie("session", []byte("user:1"), time.Minute)
+			if err != nil {
+				t.Fatalf("NewCookie() error = %v", err)
+			}
+			if !strings.HasPrefix(cookie.Value, "k1.") || cookie.MaxAge != 60 || !cookie.HttpOnly || !cookie.Secure ||
+				!cookie.Expires.Equal(clock.now.Add(time.Minute)) {
+				t.Errorf("NewCookie() got = %+v", cookie)
+			}
+			// 轮换后旧密钥加密的cookie仍然可以解密
+			rotated, _ := NewSecureCookie(cipherName, provider, "k2", options)
+			request := httptest.NewRequest("GET", "/", nil)
+			request.AddCookie(cookie)
+			value, err := rotated.DecodeRequest(request, "session")
+			if err != nil || !bytes.Equal(value, []byte("user:1")) {
+				t.Errorf("DecodeRequest() got = %s, error = %v", value, err)
+			}
+			if _, err = rotated.DecodeRequest(request, "other"); !errors.Is(err, http.ErrNoCookie) {
+				t.Errorf("DecodeRequest() error = %v, want %v", err, http.ErrNoCookie)
+			}
+			clock.now = clock.now.Add(time.Minute + 20*time.Second)
+			if _, err = rotated.Decode("session", cookie.Value); err != nil {
+				t.Errorf("Decode() within clock skew error = %v", err)
+			}
+			clock.now = clock.now.Add(20 * time.Second)
+			if _, err = rotated.Decode("session", cookie.Value); !errors.Is(err, ErrExpired) {
+				t.Errorf("Decode() expired error = %v, want %v", err, ErrExpired)
+			}
+		})
+	}
+}
+
+func Test_SecureCookieError(t *testing.T) {
+	provider := StaticKeyProvider{"k1": []byte("0123456789abcdef"), "k2": []byte("fedcba9876543210")}
+	cookie, _ := NewSecureCookie(CipherAesGcm, provider, "k1", TokenOptions{})
+	value, _ := cookie.Encode("session", []byte("user:1"), time.Minute)
+	_, encoded, _ := strings.Cut(value, ".")
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  error
+	}{
+		{name: "format", key: "session", value: encoded, want: ErrMalformed},
+		{name: "base64", key: "session", value: value + "!", want: ErrMalformed},
+		{name: "name", key: "other", value: value, want: ErrTampered},
+		{name: "kid", key: "session", value: "k2." + encoded, want: ErrTampered},
+		{name: "unknown-kid", key: "session", value: "k3." + encoded, want: ErrTampered},
+		{name: "ciphertext", key: "session", value: tamperTail(value), want: ErrTampered},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := cookie.Decode(tt.key, tt.value); !errors.Is(err, tt.want) {
+				t.Errorf("Decode() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if _, err := cookie.Encode("session", make([]byte, 4096), time.Minute); err == nil {
+		t.Errorf("Encode() too large error = nil, wantErr true")
+	}
+	if _, err := cookie.Encode("session", nil, 0); err == nil {
+		t.Errorf("Encode() with zero ttl error = nil, wantErr true")
+	}
+	if _, err := NewSecureCookie("des", provider, "k1", TokenOptions{}); err == nil {
+		t.Errorf("NewSecureCookie() with unsupported cipher error = nil, wantErr true")
+	}
+	if _, err := NewSecureCookie(CipherAesGcm, provider, "k.1", TokenOptions{}); err == nil {
+		t.Errorf("NewSecureCookie() with invalid key id error = nil, wantErr true")
+	}
+}
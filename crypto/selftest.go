@@ -0,0 +1,774 @@
+// Package crypto 已知答案自检工具包
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/x509"
+)
+
+/*
+自检参考FIPS 140中的上电自检（power-on self-test），使用标准文档中的已知答案测试向量（KAT）校验各个算法的实现，
+用于发现编译器、汇编实现、依赖库升级或运行环境异常导致的计算错误：
+对称算法与模式同时校验加密结果与解密结果，摘要与mac校验输出，sm2校验密钥派生、标准签名验签与标准密文解密，
+并额外做一次签名后验签的一致性测试（pairwise consistency test）。
+ISO10126填充包含随机字节，没有可比对的固定输出，只校验填充长度与去填充结果；
+ANSIX923在本仓库中沿用PKCS7的填充方式（见padding.go），与ANSI X9.23标准的补0加长度字节不同，按照本仓库的约定校验PKCS7输出。
+sm4没有公开的密钥包装测试向量，sm4-keywrap与sm4-keywrap-pad使用本实现生成的回归向量，
+其构造与通过RFC 3394、RFC 5649向量校验的aes密钥包装共用同一实现。
+每一项自检相互独立，单项panic会被恢复并记录为失败，不影响其余自检的执行。
+SelfTest可以在服务启动时或定期调用，也可以使用构建标签tutils_selftest在包初始化时自动执行，自检失败时直接panic：
+go build -tags tutils_selftest
+*/
+
+// ErrSelfTest 自检失败
+var ErrSelfTest = errors.New("crypto self-test failed")
+
+// SelfTestResult 单项自检结果
+type SelfTestResult struct {
+	Name      string        // 自检名称
+	Algorithm string        // 算法，例如aes、sm4
+	Reference string        // 测试向量来源
+	Err       error         // 失败原因，成功时为nil
+	Duration  time.Duration // 耗时
+}
+
+// SelfTestReport 自检报告
+type SelfTestReport struct {
+	Results  []SelfTestResult // 每一项自检的结果，顺序与执行顺序一致
+	Duration time.Duration    // 总耗时
+}
+
+// Passed 是否全部自检通过
+func (r *SelfTestReport) Passed() bool {
+	return len(r.Failed()) == 0
+}
+
+// Failed 失败的自检结果
+func (r *SelfTestReport) Failed() []SelfTestResult {
+	var failed []SelfTestResult
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Err 全部自检通过时返回nil，否则返回包装了ErrSelfTest与每一项失败原因的错误
+func (r *SelfTestReport) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	errs := make([]error, len(failed))
+	for i, result := range failed {
+		errs[i] = fmt.Errorf("%s: %w", result.Name, result.Err)
+	}
+	return fmt.Errorf("%w: %d of %d failed: %w", ErrSelfTest, len(failed), len(r.Results), errors.Join(errs...))
+}
+
+// String 可读的自检报告，每项一行
+func (r *SelfTestReport) String() string {
+	var builder strings.Builder
+	for _, result := range r.Results {
+		status := "PASS"
+		if result.Err != nil {
+			status = "FAIL"
+		}
+		fmt.Fprintf(&builder, "%s %-28s %-10s %-24s %v", status, result.Name, result.Algorithm, result.Reference,
+			result.Duration)
+		if result.Err != nil {
+			fmt.Fprintf(&builder, " %v", result.Err)
+		}
+		builder.WriteByte('\n')
+	}
+	fmt.Fprintf(&builder, "%d passed, %d failed in %v", len(r.Results)-len(r.Failed()), len(r.Failed()), r.Duration)
+	return builder.String()
+}
+
+// SelfTest 执行全部已知答案自检，返回结构化的自检报告，可以使用报告的Err方法判断是否通过
+func SelfTest() *SelfTestReport {
+	return runSelfTests(selfTests)
+}
+
+// selfTest 单项自检
+type selfTest struct {
+	name      string
+	algorithm string
+	reference string
+	run       func() error
+}
+
+// runSelfTests 依次执行自检，单项自检panic时记录为失败
+func runSelfTests(tests []selfTest) *SelfTestReport {
+	report := &SelfTestReport{Results: make([]SelfTestResult, 0, len(tests))}
+	start := time.Now()
+	for _, test := range tests {
+		result := SelfTestResult{Name: test.name, Algorithm: test.algorithm, Reference: test.reference}
+		begin := time.Now()
+		result.Err = runSelfTest(test.run)
+		result.Duration = time.Since(begin)
+		report.Results = append(report.Results, result)
+	}
+	report.Duration = time.Since(start)
+	return report
+}
+
+// runSelfTest 执行单项自检并恢复panic
+func runSelfTest(run func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return run()
+}
+
+// selfTestHex 解码测试向量中的16进制字符串，测试向量为常量，解码失败时panic
+func selfTestHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(fmt.Sprintf("invalid self-test vector %q: %v", s, err))
+	}
+	return b
+}
+
+// selfTestExpect 比对计算结果与期望结果
+// @param step 步骤名称，用于错误信息
+// @param got 计算结果
+// @param err 计算过程的错误
+// @param want 16进制的期望结果
+func selfTestExpect(step string, got []byte, err error, want string) error {
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", step, err)
+	}
+	if !bytes.Equal(got, selfTestHex(want)) {
+		return fmt.Errorf("%s mismatch: got %x, want %s", step, got, want)
+	}
+	return nil
+}
+
+// selfTestCrypt 校验加密结果，并校验对加密结果解密后得到原文
+// @param encrypt 加密函数
+// @param decrypt 解密函数
+// @param plaintext 16进制的明文
+// @param ciphertext 16进制的期望密文
+func selfTestCrypt(encrypt func(plaintext []byte) ([]byte, error), decrypt func(ciphertext []byte) ([]byte, error),
+	plaintext, ciphertext string) error {
+	got, err := encrypt(selfTestHex(plaintext))
+	if err = selfTestExpect("encrypt", got, err, ciphertext); err != nil {
+		return err
+	}
+	got, err = decrypt(selfTestHex(ciphertext))
+	return selfTestExpect("decrypt", got, err, plaintext)
+}
+
+// selfTestBlock 校验分组密码单个分组的加密与解密
+func selfTestBlock(newCipher func(key []byte) (cipher.Block, error), key, plaintext, ciphertext string) error {
+	block, err := newCipher(selfTestHex(key))
+	if err != nil {
+		return fmt.Errorf("create block failed: %w", err)
+	}
+	crypt := func(f func(dst, src []byte)) func(src []byte) ([]byte, error) {
+		return func(src []byte) ([]byte, error) {
+			dst := make([]byte, len(src))
+			f(dst, src)
+			return dst, nil
+		}
+	}
+	return selfTestCrypt(crypt(block.Encrypt), crypt(block.Decrypt), plaintext, ciphertext)
+}
+
+// selfTestEcb 校验ecb模式的加密与解密
+func selfTestEcb(newCipher func(key []byte) (cipher.Block, error), key, plaintext, ciphertext string) error {
+	block, err := newCipher(selfTestHex(key))
+	if err != nil {
+		return fmt.Errorf("create block failed: %w", err)
+	}
+	return selfTestCrypt(func(plaintext []byte) ([]byte, error) {
+		return EcbEncrypt(block, plaintext)
+	}, func(ciphertext []byte) ([]byte, error) {
+		return EcbDecrypt(block, ciphertext)
+	}, plaintext, ciphertext)
+}
+
+// selfTestAead 校验aead的加密与解密，ciphertext为密文与认证标签拼接，并校验篡改后解密失败
+func selfTestAead(encrypt, decrypt func(key, nonce, data, additionalData []byte) ([]byte, error),
+	key, nonce, plaintext, additionalData, ciphertext string) error {
+	k, n, ad := selfTestHex(key), selfTestHex(nonce), selfTestHex(additionalData)
+	err := selfTestCrypt(func(plaintext []byte) ([]byte, error) {
+		return encrypt(k, n, plaintext, ad)
+	}, func(ciphertext []byte) ([]byte, error) {
+		return decrypt(k, n, ciphertext, ad)
+	}, plaintext, ciphertext)
+	if err != nil {
+		return err
+	}
+	tampered := selfTestHex(ciphertext)
+	tampered[len(tampered)-1] ^= 1
+	if _, err = decrypt(k, n, tampered, ad); err == nil {
+		return errors.New("decrypt accepted tampered ciphertext")
+	}
+	return nil
+}
+
+// selfTestXts 校验xts模式的加密与解密
+func selfTestXts(encrypt, decrypt func(key []byte, sector uint64, data []byte, standard string) ([]byte, error),
+	key string, sector uint64, plaintext, ciphertext string) error {
+	k := selfTestHex(key)
+	return selfTestCrypt(func(plaintext []byte) ([]byte, error) {
+		return encrypt(k, sector, plaintext, XtsStandardIeee)
+	}, func(ciphertext []byte) ([]byte, error) {
+		return decrypt(k, sector, ciphertext, XtsStandardIeee)
+	}, plaintext, ciphertext)
+}
+
+// selfTestMac 校验mac计算结果与校验函数
+func selfTestMac(sum func(data []byte) ([]byte, error), verify func(data, mac []byte) error, data, mac string) error {
+	got, err := sum(selfTestHex(data))
+	if err = selfTestExpect("mac", got, err, mac); err != nil {
+		return err
+	}
+	if err = verify(selfTestHex(data), selfTestHex(mac)); err != nil {
+		return fmt.Errorf("verify failed: %w", err)
+	}
+	tampered := selfTestHex(mac)
+	tampered[0] ^= 1
+	if verify(selfTestHex(data), tampered) == nil {
+		return errors.New("verify accepted tampered mac")
+	}
+	return nil
+}
+
+// selfTestHash 校验哈希结果
+func selfTestHash(hashName string, data []byte, want string) error {
+	newHash, ok := hashMap[hashName]
+	if !ok {
+		return fmt.Errorf("unsupported hash: %s", hashName)
+	}
+	h := newHash()
+	h.Write(data)
+	return selfTestExpect(hashName, h.Sum(nil), nil, want)
+}
+
+// selfTestPadding 校验填充与去填充，want为空时只校验填充后长度与去填充结果（填充内容包含随机数）
+func selfTestPadding(padding string, blockSize int, src, want string) error {
+	data := selfTestHex(src)
+	padded := Padding(padding, bytes.Clone(data), blockSize)
+	if want != "" {
+		if err := selfTestExpect("padding", padded, nil, want); err != nil {
+			return err
+		}
+	} else if len(padded) == 0 || len(padded)%blockSize != 0 || int(padded[len(padded)-1]) != len(padded)-len(data) {
+		return fmt.Errorf("padding mismatch: got %x", padded)
+	}
+	return selfTestExpect("unpadding", UnPadding(padding, padded), nil, src)
+}
+
+// selfTestSm2 校验sm2密钥派生、标准签名验签、标准密文解密以及签名一致性
+func selfTestSm2() error {
+	// GM/T 0003.5 附录A中的私钥、公钥、签名与密文
+	privateKey, err := x509.ReadPrivateKeyFromHex("3945208f7b2144b13f36e38ac6d39f95889393692860b51a42fb81ef4df7c5b8")
+	if err != nil {
+		return fmt.Errorf("read private key failed: %w", err)
+	}
+	publicKey := append(privateKey.X.FillBytes(make([]byte, 32)), privateKey.Y.FillBytes(make([]byte, 32))...)
+	err = selfTestExpect("public key", publicKey, nil,
+		"09f9df311e5421a150dd7d161e4bc5c672179fad1833fc076bb08ff356f35020"+
+			"ccea490ce26775a52dc6ea718cc1aa600aed05fbf35e084a6632f6072da9ad13")
+	if err != nil {
+		return err
+	}
+	data := []byte("message digest")
+	signature, err := asn1.Marshal(struct{ R, S *big.Int }{
+		R: new(big.Int).SetBytes(selfTestHex("f5a03b0648d2c4630eeac513e1bb81a15944da3827d5b74143ac7eaceee720b3")),
+		S: new(big.Int).SetBytes(selfTestHex("b1b6aa29df212fd8763182bc0d421ca1bb9038fd1f7f42d4840b69c485bbc1aa")),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal signature failed: %w", err)
+	}
+	if err = Sm2Verify(&privateKey.PublicKey, data, signature); err != nil {
+		return fmt.Errorf("verify standard signature failed: %w", err)
+	}
+	if Sm2Verify(&privateKey.PublicKey, []byte("message digesT"), signature) == nil {
+		return errors.New("verify accepted signature of another message")
+	}
+	signature, err = Sm2Sign(privateKey, data)
+	if err != nil {
+		return err
+	}
+	if err = Sm2Verify(&privateKey.PublicKey, data, signature); err != nil {
+		return fmt.Errorf("pairwise consistency failed: %w", err)
+	}
+	// 密文为C1C3C2顺序，C1前带有未压缩点标识0x04
+	ciphertext, err := sm2.CipherMarshal(selfTestHex("04" +
+		"04ebfc718e8d1798620432268e77feb6415e2ede0e073c0f4f640ecd2e149a73" +
+		"e858f9d81e5430a57b36daab8f950a3c64e6ee6a63094d99283aff767e124df0" +
+		"59983c18f809e262923c53aec295d30383b54e39d609d160afcb1908d0bd8766" +
+		"21886ca989ca9c7d58087307ca93092d651efa"))
+	if err != nil {
+		return fmt.Errorf("marshal ciphertext failed: %w", err)
+	}
+	plaintext, err := Sm2DecryptAsn1(privateKey, ciphertext, sm2.C1C3C2)
+	return selfTestExpect("decrypt", plaintext, err, hex.EncodeToString([]byte("encryption standard")))
+}
+
+// 常用测试数据
+const (
+	// selfTestSp80038aKey NIST SP 800-38A附录F中AES-128使用的密钥
+	selfTestSp80038aKey = "2b7e151628aed2a6abf7158809cf4f3c"
+	// selfTestSp80038aPlaintext NIST SP 800-38A附录F使用的明文的前两个分组
+	selfTestSp80038aPlaintext = "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51"
+	// selfTestSm4Key GB/T 32907 附录A使用的密钥，同时作为明文
+	selfTestSm4Key = "0123456789abcdeffedcba9876543210"
+)
+
+// selfTests 全部自检项，按照摘要、填充、分组密码、模式、mac、流密码、非对称算法的顺序执行
+var selfTests = []selfTest{
+	{
+		name: "sha256", algorithm: "sha256", reference: "FIPS 180-4",
+		run: func() error {
+			return selfTestHash(HashSha256, []byte("abc"),
+				"ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad")
+		},
+	},
+	{
+		name: "sm3-abc", algorithm: "sm3", reference: "GB/T 32905 A.1",
+		run: func() error {
+			return selfTestHash(HashSm3, []byte("abc"),
+				"66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0")
+		},
+	},
+	{
+		name: "sm3-64bytes", algorithm: "sm3", reference: "GB/T 32905 A.2",
+		run: func() error {
+			return selfTestHash(HashSm3, bytes.Repeat([]byte("abcd"), 16),
+				"debe9ff92275b8a138604889c18e5a4d6fdb70e5387e5765293dcba39c0c5732")
+		},
+	},
+	{
+		name: "hmac-sha256", algorithm: "hmac", reference: "RFC 4231 4.3",
+		run: func() error {
+			got, err := HmacSum(HashSha256, []byte("Jefe"), []byte("what do ya want for nothing?"))
+			return selfTestExpect("hmac", got, err,
+				"5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843")
+		},
+	},
+	{
+		name: "padding-pkcs7", algorithm: "padding", reference: "RFC 5652 6.3",
+		run: func() error {
+			return selfTestPadding(PaddingPkcs7, 16, "616263", "6162630d0d0d0d0d0d0d0d0d0d0d0d0d")
+		},
+	},
+	{
+		name: "padding-pkcs5", algorithm: "padding", reference: "RFC 8018 6.1.1",
+		run: func() error {
+			return selfTestPadding(PaddingPkcs5, 8, "0102030405060708", "01020304050607080808080808080808")
+		},
+	},
+	{
+		name: "padding-zero", algorithm: "padding", reference: "ISO/IEC 9797-1 method 1",
+		run: func() error {
+			return selfTestPadding(PaddingZero, 8, "616263", "6162630000000000")
+		},
+	},
+	{
+		name: "padding-ansix923", algorithm: "padding", reference: "pkcs7-compatible (repo convention)",
+		run: func() error {
+			return selfTestPadding(PaddingAnsix923, 8, "616263", "6162630505050505")
+		},
+	},
+	{
+		name: "padding-iso10126", algorithm: "padding", reference: "ISO 10126",
+		run: func() error {
+			return selfTestPadding(PaddingIso10126, 8, "616263", "")
+		},
+	},
+	{
+		name: "aes-128-block", algorithm: "aes", reference: "FIPS 197 C.1",
+		run: func() error {
+			return selfTestBlock(aes.NewCipher, "000102030405060708090a0b0c0d0e0f",
+				"00112233445566778899aabbccddeeff", "69c4e0d86a7b0430d8cdb78070b4c55a")
+		},
+	},
+	{
+		name: "aes-256-block", algorithm: "aes", reference: "FIPS 197 C.3",
+		run: func() error {
+			return selfTestBlock(aes.NewCipher, "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+				"00112233445566778899aabbccddeeff", "8ea2b7ca516745bfeafc49904b496089")
+		},
+	},
+	{
+		name: "aes-ecb", algorithm: "aes", reference: "SP 800-38A F.1.1",
+		run: func() error {
+			return selfTestEcb(aes.NewCipher, selfTestSp80038aKey, selfTestSp80038aPlaintext,
+				"3ad77bb40d7a3660a89ecaf32466ef97f5d3d58503b9699de785895a96fdbaaf")
+		},
+	},
+	{
+		name: "aes-cbc", algorithm: "aes", reference: "SP 800-38A F.2.1",
+		run: func() error {
+			key, iv := selfTestHex(selfTestSp80038aKey), selfTestHex("000102030405060708090a0b0c0d0e0f")
+			return selfTestCrypt(func(plaintext []byte) ([]byte, error) {
+				return AesCbcEncrypt(key, iv, plaintext, "")
+			}, func(ciphertext []byte) ([]byte, error) {
+				return AesCbcDecrypt(key, iv, ciphertext, "")
+			}, selfTestSp80038aPlaintext, "7649abac8119b246cee98e9b12e9197d5086cb9b507219ee95db113a917678b2")
+		},
+	},
+	{
+		name: "aes-ctr", algorithm: "aes", reference: "SP 800-38A F.5.1",
+		run: func() error {
+			key, iv := selfTestHex(selfTestSp80038aKey), selfTestHex("f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+			crypt := func(src []byte) ([]byte, error) {
+				return CtrXorParallel(aes.NewCipher, key, iv, src, ParallelOptions{Threshold: 1, ChunkSize: 16})
+			}
+			return selfTestCrypt(crypt, crypt, selfTestSp80038aPlaintext,
+				"874d6191b620e3261bef6864990db6ce9806f66b7970fdff8617187bb9fffdff")
+		},
+	},
+	{
+		name: "aes-gcm", algorithm: "aes", reference: "GCM spec test case 4",
+		run: func() error {
+			return selfTestAead(AesGcmEncrypt, AesGcmDecrypt, "feffe9928665731c6d6a8f9467308308",
+				"cafebabefacedbaddecaf888",
+				"d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a72"+
+					"1c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39",
+				"feedfacedeadbeeffeedfacedeadbeefabaddad2",
+				"42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e"+
+					"21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091"+
+					"5bc94fbc3221a5db94fae95ae7121a47")
+		},
+	},
+	{
+		name: "aes-gcm-parallel", algorithm: "aes", reference: "GCM spec test case 4",
+		run: func() error {
+			options := ParallelOptions{Threshold: 1, ChunkSize: 16, Workers: 2}
+			return selfTestAead(func(key, nonce, plaintext, additionalData []byte) ([]byte, error) {
+				return GcmEncryptParallel(aes.NewCipher, key, nonce, plaintext, additionalData, options)
+			}, func(key, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+				return GcmDecryptParallel(aes.NewCipher, key, nonce, ciphertext, additionalData, options)
+			}, "feffe9928665731c6d6a8f9467308308", "cafebabefacedbaddecaf888",
+				"d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a72"+
+					"1c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39",
+				"feedfacedeadbeeffeedfacedeadbeefabaddad2",
+				"42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e"+
+					"21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091"+
+					"5bc94fbc3221a5db94fae95ae7121a47")
+		},
+	},
+	{
+		name: "aes-xts", algorithm: "aes", reference: "IEEE 1619 B vector 2",
+		run: func() error {
+			return selfTestXts(AesXtsEncrypt, AesXtsDecrypt,
+				"1111111111111111111111111111111122222222222222222222222222222222", 0x3333333333,
+				strings.Repeat("44", 32), "c454185e6a16936e39334038acef838bfb186fff7480adc4289382ecd6d394f0")
+		},
+	},
+	{
+		name: "aes-siv", algorithm: "aes", reference: "RFC 5297 A.1",
+		run: func() error {
+			key := selfTestHex("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+			additionalData := selfTestHex("101112131415161718191a1b1c1d1e1f2021222324252627")
+			return selfTestCrypt(func(plaintext []byte) ([]byte, error) {
+				return AesSivEncrypt(key, plaintext, additionalData)
+			}, func(ciphertext []byte) ([]byte, error) {
+				return AesSivDecrypt(key, ciphertext, additionalData)
+			}, "112233445566778899aabbccddee", "85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c")
+		},
+	},
+	{
+		name: "aes-gcm-siv", algorithm: "aes", reference: "RFC 8452 C.1",
+		run: func() error {
+			return selfTestAead(AesGcmSivEncrypt, AesGcmSivDecrypt, "01000000000000000000000000000000",
+				"030000000000000000000000", "0100000000000000", "",
+				"b5d839330ac7b786578782fff6013b815b287c22493a364c")
+		},
+	},
+	{
+		name: "aes-keywrap", algorithm: "aes", reference: "RFC 3394 4.1",
+		run: func() error {
+			kek := selfTestHex("000102030405060708090a0b0c0d0e0f")
+			return selfTestCrypt(func(key []byte) ([]byte, error) {
+				return AesKeyWrap(kek, key)
+			}, func(wrapped []byte) ([]byte, error) {
+				return AesKeyUnwrap(kek, wrapped)
+			}, "00112233445566778899aabbccddeeff", "1fa68b0a8112b447aef34bd8fb5a7b829d3e862371d2cfe5")
+		},
+	},
+	{
+		name: "aes-keywrap-pad", algorithm: "aes", reference: "RFC 5649 6",
+		run: func() error {
+			kek := selfTestHex("5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8")
+			return selfTestCrypt(func(key []byte) ([]byte, error) {
+				return AesKeyWrapPad(kek, key)
+			}, func(wrapped []byte) ([]byte, error) {
+				return AesKeyUnwrapPad(kek, wrapped)
+			}, "c37b7e6492584340bed12207808941155068f738",
+				"138bdeaa9b8fa7fc61f97742e72248ee5ae6ae5360d1ae6a5f54f373fa543b6a")
+		},
+	},
+	{
+		name: "aes-cmac", algorithm: "aes", reference: "RFC 4493 4",
+		run: func() error {
+			key := selfTestHex(selfTestSp80038aKey)
+			return selfTestMac(func(data []byte) ([]byte, error) {
+				return AesCmac(key, data)
+			}, func(data, mac []byte) error {
+				return AesCmacVerify(key, data, mac)
+			}, selfTestSp80038aPlaintext[:32], "070a16b46b4d4144f79bdd9dd04a287c")
+		},
+	},
+	{
+		name: "aes-gmac", algorithm: "aes", reference: "CAVP gcmEncryptExtIV128",
+		run: func() error {
+			key, nonce := selfTestHex("77be63708971c4e240d1cb79e8d77feb"), selfTestHex("e0e00f19fed7ba0136a797f3")
+			return selfTestMac(func(data []byte) ([]byte, error) {
+				return AesGmac(key, nonce, data)
+			}, func(data, mac []byte) error {
+				return AesGmacVerify(key, nonce, data, mac)
+			}, "7a43ec1d9c0a5a78a0b16533a6213cab", "209fcc8d3675ed938e9c7166709dd946")
+		},
+	},
+	{
+		name: "aes-ff1", algorithm: "aes", reference: "SP 800-38G FF1 sample 1",
+		run: func() error {
+			key := selfTestHex(selfTestSp80038aKey)
+			ciphertext, err := AesFf1Encrypt(key, nil, "0123456789", FpeAlphabetDigits)
+			if err = selfTestExpect("encrypt", []byte(ciphertext), err, hex.EncodeToString([]byte("2433477484"))); err != nil {
+				return err
+			}
+			plaintext, err := AesFf1Decrypt(key, nil, ciphertext, FpeAlphabetDigits)
+			return selfTestExpect("decrypt", []byte(plaintext), err, hex.EncodeToString([]byte("0123456789")))
+		},
+	},
+	{
+		name: "aes-ff3-1", algorithm: "aes", reference: "NIST ACVP FF3-1",
+		run: func() error {
+			key, tweak := selfTestHex("2de79d232df5585d68ce47882ae256d6"), selfTestHex("cbd09280979564")
+			ciphertext, err := AesFf3Encrypt(key, tweak, "3992520240", FpeAlphabetDigits)
+			if err = selfTestExpect("encrypt", []byte(ciphertext), err, hex.EncodeToString([]byte("8901801106"))); err != nil {
+				return err
+			}
+			plaintext, err := AesFf3Decrypt(key, tweak, ciphertext, FpeAlphabetDigits)
+			return selfTestExpect("decrypt", []byte(plaintext), err, hex.EncodeToString([]byte("3992520240")))
+		},
+	},
+	{
+		name: "3des-ecb", algorithm: "3des", reference: "SP 800-67 example",
+		run: func() error {
+			key := selfTestHex("0123456789abcdef23456789abcdef01456789abcdef0123")
+			return selfTestCrypt(func(plaintext []byte) ([]byte, error) {
+				return TripleDesEcbEncrypt(key, plaintext, "")
+			}, func(ciphertext []byte) ([]byte, error) {
+				return TripleDesEcbDecrypt(key, ciphertext, "")
+			}, hex.EncodeToString([]byte("The qufck brown fox jump")),
+				"a826fd8ce53b855fcce21c8112256fe668d5c05dd9b6b900")
+		},
+	},
+	{
+		name: "3des-cmac", algorithm: "3des", reference: "SP 800-38B D.4",
+		run: func() error {
+			block, err := des.NewTripleDESCipher(selfTestHex("8aa83bf8cbda10620bc1bf19fbb6cd58bc313d4a371ca8b5"))
+			if err != nil {
+				return fmt.Errorf("create block failed: %w", err)
+			}
+			return selfTestMac(func(data []byte) ([]byte, error) {
+				return Cmac(block, data)
+			}, func(data, mac []byte) error {
+				return CmacVerify(block, data, mac)
+			}, selfTestSp80038aPlaintext[:16], "8e8f293136283797")
+		},
+	},
+	{
+		name: "des-cbc-mac", algorithm: "des", reference: "ISO/IEC 9797-1 B MAC algorithm 1",
+		run: func() error {
+			block, err := des.NewCipher(selfTestHex("0123456789abcdef"))
+			if err != nil {
+				return fmt.Errorf("create block failed: %w", err)
+			}
+			return selfTestMac(func(data []byte) ([]byte, error) {
+				return CbcMac(block, data, MacPaddingIso9797M1)
+			}, func(data, mac []byte) error {
+				return CbcMacVerify(block, data, MacPaddingIso9797M1, mac)
+			}, hex.EncodeToString([]byte("Now is the time for all ")), "70a30640cc76dd8b")
+		},
+	},
+	{
+		name: "3des-retail-mac", algorithm: "3des", reference: "ISO/IEC 9797-1 B MAC algorithm 3",
+		run: func() error {
+			key := selfTestHex("0123456789abcdeffedcba9876543210")
+			return selfTestMac(func(data []byte) ([]byte, error) {
+				return TripleDesRetailMac(key, data, MacPaddingIso9797M1)
+			}, func(data, mac []byte) error {
+				return TripleDesRetailMacVerify(key, data, MacPaddingIso9797M1, mac)
+			}, hex.EncodeToString([]byte("Now is the time for all ")), "a1c72e74ea3fa9b6")
+		},
+	},
+	{
+		name: "sm4-block", algorithm: "sm4", reference: "GB/T 32907 A.1",
+		run: func() error {
+			return selfTestBlock(NewSm4Cipher, selfTestSm4Key, selfTestSm4Key, "681edf34d206965e86b3e94f536e4246")
+		},
+	},
+	{
+		name: "sm4-block-bitsliced", algorithm: "sm4", reference: "GB/T 32907 A.1",
+		run: func() error {
+			return selfTestBlock(NewSm4BitslicedCipher, selfTestSm4Key, selfTestSm4Key,
+				"681edf34d206965e86b3e94f536e4246")
+		},
+	},
+	{
+		name: "sm4-ecb", algorithm: "sm4", reference: "GB/T 32907 A.1",
+		run: func() error {
+			return selfTestEcb(NewSm4Cipher, selfTestSm4Key, strings.Repeat(selfTestSm4Key, 3),
+				strings.Repeat("681edf34d206965e86b3e94f536e4246", 3))
+		},
+	},
+	{
+		name: "sm4-ecb-bitsliced", algorithm: "sm4", reference: "GB/T 32907 A.1",
+		run: func() error {
+			// 多个分组时走批量处理的路径
+			return selfTestEcb(NewSm4BitslicedCipher, selfTestSm4Key, strings.Repeat(selfTestSm4Key, 3),
+				strings.Repeat("681edf34d206965e86b3e94f536e4246", 3))
+		},
+	},
+	{
+		name: "sm4-cbc", algorithm: "sm4", reference: "draft-ribose-cfrg-sm4 A.2.2.1",
+		run: func() error {
+			key, iv := selfTestHex(selfTestSm4Key), selfTestHex("000102030405060708090a0b0c0d0e0f")
+			return selfTestCrypt(func(plaintext []byte) ([]byte, error) {
+				return Sm4CbcEncrypt(key, iv, plaintext, "")
+			}, func(ciphertext []byte) ([]byte, error) {
+				return Sm4CbcDecrypt(key, iv, ciphertext, "")
+			}, "aaaaaaaabbbbbbbbccccccccddddddddeeeeeeeeffffffffaaaaaaaabbbbbbbb",
+				"78ebb11cc40b0a48312aaeb2040244cb4cb7016951909226979b0d15dc6a8f6d")
+		},
+	},
+	{
+		name: "sm4-ctr", algorithm: "sm4", reference: "draft-ribose-cfrg-sm4 A.2.5.1",
+		run: func() error {
+			key, iv := selfTestHex(selfTestSm4Key), selfTestHex("000102030405060708090a0b0c0d0e0f")
+			crypt := func(src []byte) ([]byte, error) {
+				return CtrXorParallel(NewSm4Cipher, key, iv, src, ParallelOptions{Threshold: 1, ChunkSize: 32})
+			}
+			return selfTestCrypt(crypt, crypt,
+				"aaaaaaaaaaaaaaaabbbbbbbbbbbbbbbbccccccccccccccccdddddddddddddddd"+
+					"eeeeeeeeeeeeeeeeffffffffffffffffaaaaaaaaaaaaaaaabbbbbbbbbbbbbbbb",
+				"ac3236cb970cc20791364c395a1342d1a3cbc1878c6f30cd074cce385cdd70c7"+
+					"f234bc0e24c11980fd1286310ce37b926e02fcd0faa0baf38b2933851d824514")
+		},
+	},
+	{
+		name: "sm4-gcm", algorithm: "sm4", reference: "RFC 8998 A.1",
+		run: func() error {
+			return selfTestAead(Sm4GcmEncrypt, Sm4GcmDecrypt, selfTestSm4Key, "00001234567800000000abcd",
+				"aaaaaaaaaaaaaaaabbbbbbbbbbbbbbbbccccccccccccccccdddddddddddddddd"+
+					"eeeeeeeeeeeeeeeeffffffffffffffffeeeeeeeeeeeeeeeeaaaaaaaaaaaaaaaa",
+				"feedfacedeadbeeffeedfacedeadbeefabaddad2",
+				"17f399f08c67d5ee19d0dc9969c4bb7d5fd46fd3756489069157b282bb200735"+
+					"d82710ca5c22f0ccfa7cbf93d496ac15a56834cbcf98c397b4024a2691233b8d"+
+					"83de3541e4c2b58177e065a9bf7b62ec")
+		},
+	},
+	{
+		name: "sm4-xts", algorithm: "sm4", reference: "GB/T 17964-2021",
+		run: func() error {
+			key := selfTestHex("2b7e151628aed2a6abf7158809cf4f3c000102030405060708090a0b0c0d0e0f")
+			var tweak [xtsBlockSize]byte
+			copy(tweak[:], selfTestHex("f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff"))
+			return selfTestCrypt(func(plaintext []byte) ([]byte, error) {
+				return xtsCrypt(NewSm4Cipher, key, tweak, plaintext, XtsStandardGb, true)
+			}, func(ciphertext []byte) ([]byte, error) {
+				return xtsCrypt(NewSm4Cipher, key, tweak, ciphertext, XtsStandardGb, false)
+			}, selfTestSp80038aPlaintext+"30c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17",
+				"e9538251c71d7b80bbe4483fef497bd12c5c581bd6242fc51e08964fb4f60fdb"+
+					"0ba42f63499279213d318d2c11f6886e903be7f93a1b3479")
+		},
+	},
+	{
+		name: "sm4-keywrap", algorithm: "sm4", reference: "RFC 3394 with sm4 (regression)",
+		run: func() error {
+			kek := selfTestHex(selfTestSm4Key)
+			return selfTestCrypt(func(key []byte) ([]byte, error) {
+				return Sm4KeyWrap(kek, key)
+			}, func(wrapped []byte) ([]byte, error) {
+				return Sm4KeyUnwrap(kek, wrapped)
+			}, "00112233445566778899aabbccddeeff", "2f92140188bb01970a726046b111c5fa427ced34d73dcab8")
+		},
+	},
+	{
+		name: "sm4-keywrap-pad", algorithm: "sm4", reference: "RFC 5649 with sm4 (regression)",
+		run: func() error {
+			kek := selfTestHex(selfTestSm4Key)
+			return selfTestCrypt(func(key []byte) ([]byte, error) {
+				return Sm4KeyWrapPad(kek, key)
+			}, func(wrapped []byte) ([]byte, error) {
+				return Sm4KeyUnwrapPad(kek, wrapped)
+			}, "c37b7e6492584340bed12207808941155068f738",
+				"134dfdd962bf2450d070aba893ea8af7b82a2316b6a34ceb9ab456a5bd5fac44")
+		},
+	},
+	{
+		name: "chacha20-poly1305", algorithm: "chacha20", reference: "RFC 8439 2.8.2",
+		run: func() error {
+			return selfTestAead(ChaCha20Poly1305Encrypt, ChaCha20Poly1305Decrypt,
+				"808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f", "070000004041424344454647",
+				hex.EncodeToString([]byte("Ladies and Gentlemen of the class of '99: If I could offer you only one tip "+
+					"for the future, sunscreen would be it.")),
+				"50515253c0c1c2c3c4c5c6c7",
+				"d31a8d34648e60db7b86afbc53ef7ec2a4aded51296e08fea9e2b5a736ee62d6"+
+					"3dbea45e8ca9671282fafb69da92728b1a71de0a9e060b2905d6a5b67ecd3b36"+
+					"92ddbd7f2d778b8c9803aee328091b58fab324e4fad675945585808b4831d7bc"+
+					"3ff4def08e4b7a9de576d26586cec64b6116"+
+					"1ae10b594f09e26a7e902ecbd0600691")
+		},
+	},
+	{
+		name: "zuc-128", algorithm: "zuc", reference: "GB/T 33133.1 A",
+		run: func() error {
+			keystream, err := ZucKeystream(make([]byte, 16), make([]byte, 16), 2)
+			if err != nil {
+				return err
+			}
+			if keystream[0] != 0x27bede74 || keystream[1] != 0x018082da {
+				return fmt.Errorf("keystream mismatch: got %08x, want [27bede74 018082da]", keystream)
+			}
+			return nil
+		},
+	},
+	{
+		name: "zuc-128-eea3", algorithm: "zuc", reference: "3GPP EEA3 test set 1",
+		run: func() error {
+			key := selfTestHex("173d14ba5003731d7a60049470f00a29")
+			return selfTestCrypt(func(plaintext []byte) ([]byte, error) {
+				return Zuc128Eea3Encrypt(key, 0x66035492, 0xf, 0, plaintext, 193)
+			}, func(ciphertext []byte) ([]byte, error) {
+				return Zuc128Eea3Decrypt(key, 0x66035492, 0xf, 0, ciphertext, 193)
+			}, "6cf65340735552ab0c9752fa6f9025fe0bd675d9005875b200", "a6c85fc66afb8533aafc2518dfe784940ee1e4b030238cc800")
+		},
+	},
+	{
+		name: "zuc-128-eia3", algorithm: "zuc", reference: "3GPP EIA3 test set 1",
+		run: func() error {
+			mac, err := Zuc128Eia3(make([]byte, 16), 0, 0, 0, make([]byte, 4), 1)
+			return selfTestExpect("mac", mac, err, "c8a9595e")
+		},
+	},
+	{
+		name: "sm2", algorithm: "sm2", reference: "GM/T 0003.5 A",
+		run: selfTestSm2,
+	},
+}
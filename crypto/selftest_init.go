@@ -0,0 +1,11 @@
+//go:build tutils_selftest
+
+// Package crypto 包初始化时的自检，使用构建标签tutils_selftest开启
+package crypto
+
+// init 包初始化时执行全部自检，任意一项失败时panic，阻止使用有问题的实现继续运行
+func init() {
+	if err := SelfTest().Err(); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,57 @@
+package crypto
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_SelfTest(t *testing.T) {
+	report := SelfTest()
+	if err := report.Err(); err != nil {
+		t.Fatalf("SelfTest() error = %v\n%s", err, report)
+	}
+	if !report.Passed() || len(report.Results) != len(selfTests) {
+		t.Errorf("SelfTest() passed = %v, results = %d, want %d", report.Passed(), len(report.Results), len(selfTests))
+	}
+	// 每种算法都需要有自检
+	algorithms := map[string]bool{}
+	for _, result := range report.Results {
+		algorithms[result.Algorithm] = true
+		if result.Reference == "" {
+			t.Errorf("self-test %s has no reference", result.Name)
+		}
+	}
+	for _, algorithm := range []string{"aes", "3des", "sm2", "sm3", "sm4", "padding"} {
+		if !algorithms[algorithm] {
+			t.Errorf("SelfTest() missing algorithm %s", algorithm)
+		}
+	}
+}
+
+func Test_SelfTestFailure(t *testing.T) {
+	errBroken := errors.New("broken")
+	report := runSelfTests([]selfTest{
+		{name: "ok", algorithm: "test", reference: "none", run: func() error { return nil }},
+		{name: "error", algorithm: "test", reference: "none", run: func() error { return errBroken }},
+		{name: "panic", algorithm: "test", reference: "none", run: func() error { panic("boom") }},
+		{name: "mismatch", algorithm: "test", reference: "none", run: func() error {
+			return selfTestExpect("encrypt", []byte{1}, nil, "02")
+		}},
+	})
+	if report.Passed() {
+		t.Fatalf("Passed() = true, want false")
+	}
+	if got := len(report.Failed()); got != 3 {
+		t.Errorf("Failed() got %d results, want 3", got)
+	}
+	err := report.Err()
+	if !errors.Is(err, ErrSelfTest) || !errors.Is(err, errBroken) {
+		t.Errorf("Err() = %v, want wrapping %v and %v", err, ErrSelfTest, errBroken)
+	}
+	for _, want := range []string{"PASS ok", "FAIL error", "panic: boom", "mismatch: got 01, want 02", "1 passed, 3 failed"} {
+		if !strings.Contains(report.String(), want) {
+			t.Errorf("String() = %q, want containing %q", report.String(), want)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+// Package crypto shamir秘密共享工具包
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+)
+
+/*
+shamir秘密共享用于将主密钥拆分给多个运维人员保管，任意threshold份可以恢复，少于threshold份得不到任何信息。
+在GF(256)（AES使用的既约多项式x^8+x^4+x^3+x+1）上对秘密的每个字节分别构造threshold-1次随机多项式，
+常数项为秘密字节，第i份为多项式在x=i（1到255）处的值，恢复时使用拉格朗日插值计算x=0处的值。
+拆分前会在秘密之后追加4字节sha256校验和一起拆分，恢复后校验，用于发现篡改或错误组合的份额，且不会泄露秘密的任何信息。
+每一份编码为：
+版本(1) || 拆分批次id(8) || threshold(1) || x坐标(1) || 值(秘密长度+4) || 校验和(4)
+拆分批次id为每次拆分随机生成，用于发现混用了不同批次的份额；末尾校验和为前面全部内容sha256的前4字节，用于发现传输或抄写错误。
+GF(256)运算不使用查表，避免与秘密相关的缓存时序泄露。
+*/
+
+// shamir秘密共享错误枚举
+var (
+	// ErrShamirNotEnoughShares 份额数量少于threshold
+	ErrShamirNotEnoughShares = errors.New("not enough shamir shares")
+	// ErrShamirInconsistentShares 份额不属于同一次拆分或已被篡改
+	ErrShamirInconsistentShares = errors.New("inconsistent shamir shares")
+	// ErrShamirChecksum 份额校验和错误
+	ErrShamirChecksum = errors.New("shamir share checksum mismatch")
+)
+
+// shamir份额编码相关常量
+const (
+	shamirVersion        = 1
+	shamirSetIDLength    = 8
+	shamirHeaderLength   = 1 + shamirSetIDLength + 2
+	shamirChecksumLength = 4
+	shamirMaxShares      = 255
+)
+
+// ShamirShare 解析后的shamir份额
+type ShamirShare struct {
+	SetID     []byte // 拆分批次id
+	Threshold int    // 恢复需要的份额数量
+	Index     int    // x坐标，从1开始
+	Value     []byte // 多项式的值
+}
+
+// ShamirSplit 拆分秘密
+// @param secret 秘密，例如主密钥
+// @param threshold 恢复需要的份额数量，范围为2到count
+// @param count 份额数量，最大为255
+func ShamirSplit(secret []byte, threshold, count int) ([][]byte, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("secret is empty")
+	}
+	if threshold < 2 || threshold > count || count > shamirMaxShares {
+		return nil, fmt.Errorf("invalid threshold %d of %d shares", threshold, count)
+	}
+	checksum := sha256.Sum256(secret)
+	data := append(bytes.Clone(secret), checksum[:shamirChecksumLength]...)
+	// 每个字节需要threshold-1个随机系数，另外加上拆分批次id
+	random := make([]byte, len(data)*(threshold-1)+shamirSetIDLength)
+	if _, err := rand.Read(random); err != nil {
+		return nil, fmt.Errorf("generate random failed: %w", err)
+	}
+	setID, coefficients := random[:shamirSetIDLength], random[shamirSetIDLength:]
+	shares := make([][]byte, count)
+	for i := range shares {
+		x := byte(i + 1)
+		share := make([]byte, 0, shamirHeaderLength+len(data)+shamirChecksumLength)
+		share = append(share, shamirVersion)
+		share = append(share, setID...)
+		share = append(share, byte(threshold), x)
+		for j, b := range data {
+			// 霍纳法则计算多项式的值，系数从高次到低次
+			y := byte(0)
+			for _, c := range coefficients[j*(threshold-1) : (j+1)*(threshold-1)] {
+				y = gf256Mul(y, x) ^ c
+			}
+			share = append(share, gf256Mul(y, x)^b)
+		}
+		sum := sha256.Sum256(share)
+		shares[i] = append(share, sum[:shamirChecksumLength]...)
+	}
+	return shares, nil
+}
+
+// ShamirCombine 使用份额恢复秘密，份额数量需要不少于threshold，份额顺序不影响结果
+// @param shares 份额
+func ShamirCombine(shares [][]byte) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrShamirNotEnoughShares
+	}
+	parsed := make([]*ShamirShare, 0, len(shares))
+	seen := make(map[int]*ShamirShare, len(shares))
+	for i, s := range shares {
+		share, err := ParseShamirShare(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse share %d failed: %w", i, err)
+		}
+		first := share
+		if len(parsed) > 0 {
+			first = parsed[0]
+		}
+		if !bytes.Equal(share.SetID, first.SetID) || share.Threshold != first.Threshold ||
+			len(share.Value) != len(first.Value) {
+			return nil, fmt.Errorf("%w: share %d belongs to another split", ErrShamirInconsistentShares, i)
+		}
+		// 重复提供同一份额时忽略，x坐标相同但值不同时无法插值
+		if existing, ok := seen[share.Index]; ok {
+			if !bytes.Equal(existing.Value, share.Value) {
+				return nil, fmt.Errorf("%w: duplicate index %d", ErrShamirInconsistentShares, share.Index)
+			}
+			continue
+		}
+		seen[share.Index] = share
+		parsed = append(parsed, share)
+	}
+	if len(parsed) < parsed[0].Threshold {
+		return nil, fmt.Errorf("%w: need %d, got %d", ErrShamirNotEnoughShares, parsed[0].Threshold, len(parsed))
+	}
+	// 拉格朗日插值计算x=0处的值，GF(2^8)中减法即异或，0-x_m=x_m
+	data := make([]byte, len(parsed[0].Value))
+	for j, share := range parsed {
+		xj := byte(share.Index)
+		basis := byte(1)
+		for m, other := range parsed {
+			if m != j {
+				xm := byte(other.Index)
+				basis = gf256Mul(basis, gf256Mul(xm, gf256Inv(xm^xj)))
+			}
+		}
+		for k, y := range share.Value {
+			data[k] ^= gf256Mul(y, basis)
+		}
+	}
+	secret, checksum := data[:len(data)-shamirChecksumLength], data[len(data)-shamirChecksumLength:]
+	expected := sha256.Sum256(secret)
+	if subtle.ConstantTimeCompare(checksum, expected[:shamirChecksumLength]) != 1 {
+		return nil, fmt.Errorf("%w: secret checksum mismatch", ErrShamirInconsistentShares)
+	}
+	return secret, nil
+}
+
+// ParseShamirShare 解析并校验份额，可以用于在恢复前查看份额所属的拆分批次与threshold
+// @param share 份额
+func ParseShamirShare(share []byte) (*ShamirShare, error) {
+	if len(share) < shamirHeaderLength+shamirChecksumLength+1+shamirChecksumLength {
+		return nil, errors.New("share too short")
+	}
+	body, checksum := share[:len(share)-shamirChecksumLength], share[len(share)-shamirChecksumLength:]
+	expected := sha256.Sum256(body)
+	if subtle.ConstantTimeCompare(checksum, expected[:shamirChecksumLength]) != 1 {
+		return nil, ErrShamirChecksum
+	}
+	if body[0] != shamirVersion {
+		return nil, fmt.Errorf("unsupported share version: %d", body[0])
+	}
+	threshold, index := int(body[1+shamirSetIDLength]), int(body[2+shamirSetIDLength])
+	if threshold < 2 || index == 0 {
+		return nil, errors.New("invalid share header")
+	}
+	return &ShamirShare{
+		SetID:     bytes.Clone(body[1 : 1+shamirSetIDLength]),
+		Threshold: threshold,
+		Index:     index,
+		Value:     bytes.Clone(body[shamirHeaderLength:]),
+	}, nil
+}
+
+// ShamirSplitToString 拆分秘密并编码份额，便于打印或抄写
+// @param secret 秘密
+// @param threshold 恢复需要的份额数量
+// @param count 份额数量
+// @param encoding 编码方式
+func ShamirSplitToString(secret []byte, threshold, count int, encoding string) ([]string, error) {
+	if !isEncodingSupported(encoding) {
+		return nil, fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+	shares, err := ShamirSplit(secret, threshold, count)
+	if err != nil {
+		return nil, err
+	}
+	encoded := make([]string, len(shares))
+	for i, share := range shares {
+		if encoded[i], err = Encode(encoding, share); err != nil {
+			return nil, err
+		}
+	}
+	return encoded, nil
+}
+
+// ShamirCombineFromString 解码份额并恢复秘密
+// @param shares 编码后的份额
+// @param encoding 编码方式
+func ShamirCombineFromString(shares []string, encoding string) ([]byte, error) {
+	decoded := make([][]byte, len(shares))
+	for i, share := range shares {
+		var err error
+		if decoded[i], err = Decode(encoding, share); err != nil {
+			return nil, fmt.Errorf("decode share %d failed: %w", i, err)
+		}
+	}
+	return ShamirCombine(decoded)
+}
+
+// gf256Mul GF(2^8)乘法，既约多项式为x^8+x^4+x^3+x+1，不使用分支与查表
+func gf256Mul(a, b byte) byte {
+	var p byte
+	for i := 0; i < 8; i++ {
+		p ^= a & -(b & 1)
+		// a最高位为1时乘以x后需要模既约多项式
+		a = a<<1 ^ 0x1b&-(a>>7)
+		b >>= 1
+	}
+	return p
+}
+
+// gf256Inv GF(2^8)求逆，a^254 = a^-1，a为0时返回0
+func gf256Inv(a byte) byte {
+	// 254 = 0b11111110
+	result, power := byte(1), a
+	for i := 0; i < 7; i++ {
+		power = gf256Mul(power, power)
+		result = gf256Mul(result, power)
+	}
+	return result
+}
@@ -0,0 +1,135 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"testing"
+)
+
+func Test_Gf256(t *testing.T) {
+	// FIPS 197 4.2节示例
+	if got := gf256Mul(0x57, 0x83); got != 0xc1 {
+		t.Errorf("gf256Mul() got = %#x, want 0xc1", got)
+	}
+	for a := 1; a < 256; a++ {
+		if got := gf256Mul(byte(a), gf256Inv(byte(a))); got != 1 {
+			t.Errorf("gf256Mul(%#x, gf256Inv(%#x)) got = %#x, want 1", a, a, got)
+		}
+	}
+}
+
+func Test_ShamirSplitCombine(t *testing.T) {
+	secret := mustHex("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	tests := []struct {
+		name      string
+		threshold int
+		count     int
+	}{
+		{name: "2-of-2", threshold: 2, count: 2},
+		{name: "3-of-5", threshold: 3, count: 5},
+		{name: "5-of-5", threshold: 5, count: 5},
+		{name: "255-of-255", threshold: 255, count: 255},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares, err := ShamirSplit(secret, tt.threshold, tt.count)
+			if err != nil {
+				t.Fatalf("ShamirSplit() error = %v", err)
+			}
+			// 任意顺序的threshold份都可以恢复
+			subset := make([][]byte, 0, tt.threshold)
+			for i := tt.count - 1; len(subset) < tt.threshold; i-- {
+				subset = append(subset, shares[i])
+			}
+			if got, err := ShamirCombine(subset); err != nil || !bytes.Equal(got, secret) {
+				t.Errorf("ShamirCombine() got = %x, error = %v", got, err)
+			}
+			if got, err := ShamirCombine(shares); err != nil || !bytes.Equal(got, secret) {
+				t.Errorf("ShamirCombine() with all shares got = %x, error = %v", got, err)
+			}
+			if _, err = ShamirCombine(subset[1:]); !errors.Is(err, ErrShamirNotEnoughShares) {
+				t.Errorf("ShamirCombine() error = %v, want %v", err, ErrShamirNotEnoughShares)
+			}
+		})
+	}
+}
+
+func Test_ShamirCombineAllSubsets(t *testing.T) {
+	secret := []byte("master key")
+	shares, _ := ShamirSplit(secret, 3, 5)
+	for i := 0; i < 5; i++ {
+		for j := i + 1; j < 5; j++ {
+			for k := j + 1; k < 5; k++ {
+				got, err := ShamirCombine([][]byte{shares[k], shares[i], shares[j]})
+				if err != nil || !bytes.Equal(got, secret) {
+					t.Errorf("ShamirCombine(%d, %d, %d) got = %s, error = %v", i, j, k, got, err)
+				}
+			}
+		}
+	}
+}
+
+func Test_ShamirCombineError(t *testing.T) {
+	secret := []byte("0123456789abcdef")
+	shares, _ := ShamirSplit(secret, 2, 3)
+	others, _ := ShamirSplit(secret, 2, 3)
+	// 修改值并重新计算份额校验和，只能通过秘密校验和发现
+	forged := bytes.Clone(shares[1])
+	forged[shamirHeaderLength] ^= 1
+	sum := sha256.Sum256(forged[:len(forged)-shamirChecksumLength])
+	copy(forged[len(forged)-shamirChecksumLength:], sum[:])
+	corrupted := bytes.Clone(shares[1])
+	corrupted[shamirHeaderLength] ^= 1
+	sameIndex, _ := ParseShamirShare(others[0])
+	tests := []struct {
+		name   string
+		shares [][]byte
+		want   error
+	}{
+		{name: "empty", shares: nil, want: ErrShamirNotEnoughShares},
+		{name: "duplicate", shares: [][]byte{shares[0], shares[0]}, want: ErrShamirNotEnoughShares},
+		{name: "checksum", shares: [][]byte{shares[0], corrupted}, want: ErrShamirChecksum},
+		{name: "mixed", shares: [][]byte{shares[0], others[1]}, want: ErrShamirInconsistentShares},
+		{name: "forged", shares: [][]byte{shares[0], forged}, want: ErrShamirInconsistentShares},
+		{name: "forged-index", shares: [][]byte{shares[1], shares[2], forged}, want: ErrShamirInconsistentShares},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ShamirCombine(tt.shares); !errors.Is(err, tt.want) {
+				t.Errorf("ShamirCombine() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if sameIndex.Index != 1 || sameIndex.Threshold != 2 || len(sameIndex.Value) != len(secret)+shamirChecksumLength {
+		t.Errorf("ParseShamirShare() got = %+v", sameIndex)
+	}
+	if _, err := ParseShamirShare(shares[0][:10]); err == nil {
+		t.Errorf("ParseShamirShare() too short error = nil, wantErr true")
+	}
+	for _, params := range [][2]int{{1, 3}, {4, 3}, {2, 256}} {
+		if _, err := ShamirSplit(secret, params[0], params[1]); err == nil {
+			t.Errorf("ShamirSplit(%d, %d) error = nil, wantErr true", params[0], params[1])
+		}
+	}
+	if _, err := ShamirSplit(nil, 2, 3); err == nil {
+		t.Errorf("ShamirSplit() with empty secret error = nil, wantErr true")
+	}
+}
+
+func Test_ShamirString(t *testing.T) {
+	secret := []byte("0123456789abcdef")
+	shares, err := ShamirSplitToString(secret, 2, 3, EncodingBase58)
+	if err != nil {
+		t.Fatalf("ShamirSplitToString() error = %v", err)
+	}
+	if got, err := ShamirCombineFromString(shares[1:], EncodingBase58); err != nil || !bytes.Equal(got, secret) {
+		t.Errorf("ShamirCombineFromString() got = %s, error = %v", got, err)
+	}
+	if _, err = ShamirCombineFromString([]string{"0OIl"}, EncodingBase58); err == nil {
+		t.Errorf("ShamirCombineFromString() with invalid share error = nil, wantErr true")
+	}
+	if _, err = ShamirSplitToString(secret, 2, 3, "base100"); err == nil {
+		t.Errorf("ShamirSplitToString() with unsupported encoding error = nil, wantErr true")
+	}
+}
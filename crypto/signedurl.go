@@ -0,0 +1,160 @@
+// Package crypto 带有效期的hmac签名url工具包
+package crypto
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+签名url用于下载链接、回调地址等场景，在原url的查询参数中追加kid、expires、signature三个参数：
+kid:       签名使用的密钥id
+expires:   过期时间，unix秒
+signature: BASE64URL（不带填充）编码的hmac
+签名内容为"转义后的path?排序后的查询参数（不包含signature）"，不包含scheme与host，
+因此可以直接在服务端使用http.Request中的url校验，反向代理改写host不影响校验结果；
+查询参数先排序再签名，参数顺序变化或等价的转义方式不影响校验结果。
+hmac密钥由主密钥通过DeriveKey派生（info为"tutils-signed-url/"+哈希算法名称），与令牌相同支持按kid轮换密钥。
+校验失败时返回ErrMalformed、ErrTampered、ErrExpired，先校验签名再校验有效期，因此ErrExpired表示签名正确但已过期。
+*/
+
+// 签名url的查询参数名称与密钥派生info前缀
+const (
+	signedUrlKeyIDParam     = "kid"
+	signedUrlExpiresParam   = "expires"
+	signedUrlSignatureParam = "signature"
+	signedUrlInfoPrefix     = "tutils-signed-url/"
+)
+
+// UrlSigner 签名url签发与校验器
+type UrlSigner struct {
+	hashName string       // 哈希算法
+	provider KeyProvider  // 密钥提供者
+	keyID    string       // 签发使用的密钥id
+	options  TokenOptions // 配置
+}
+
+// NewUrlSigner 创建签名url签发与校验器
+// @param hashName 哈希算法，HashSha256或HashSm3
+// @param provider 密钥提供者
+// @param keyID 签发使用的密钥id
+// @param options 配置，与令牌共用
+func NewUrlSigner(hashName string, provider KeyProvider, keyID string, options TokenOptions) (*UrlSigner, error) {
+	hashName = strings.ToLower(hashName)
+	if _, err := newHash(hashName); err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return nil, errors.New("key provider is nil")
+	}
+	return &UrlSigner{hashName: hashName, provider: provider, keyID: keyID, options: options}, nil
+}
+
+// Sign 签名url
+// @param rawUrl 原始url，可以是绝对地址或只包含path与查询参数
+// @param ttl 有效期
+func (s *UrlSigner) Sign(rawUrl string, ttl time.Duration) (string, error) {
+	if ttl <= 0 {
+		return "", errors.New("ttl must be positive")
+	}
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", fmt.Errorf("parse url failed: %w", err)
+	}
+	query := u.Query()
+	for _, param := range []string{signedUrlKeyIDParam, signedUrlExpiresParam, signedUrlSignatureParam} {
+		if query.Has(param) {
+			return "", fmt.Errorf("url already contains reserved parameter: %s", param)
+		}
+	}
+	query.Set(signedUrlKeyIDParam, s.keyID)
+	query.Set(signedUrlExpiresParam, strconv.FormatInt(s.now().Add(ttl).Unix(), 10))
+	signature, err := s.signature(s.keyID, u.EscapedPath(), query)
+	if err != nil {
+		return "", err
+	}
+	u.RawQuery = query.Encode() + "&" + signedUrlSignatureParam + "=" + base64.RawURLEncoding.EncodeToString(signature)
+	return u.String(), nil
+}
+
+// Verify 校验签名url
+// @param rawUrl 签名后的url
+func (s *UrlSigner) Verify(rawUrl string) error {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return fmt.Errorf("%w: parse url failed: %w", ErrMalformed, err)
+	}
+	return s.verify(u)
+}
+
+// VerifyRequest 校验http请求的url
+// @param r http请求
+func (s *UrlSigner) VerifyRequest(r *http.Request) error {
+	if r == nil || r.URL == nil {
+		return fmt.Errorf("%w: request url is nil", ErrMalformed)
+	}
+	return s.verify(r.URL)
+}
+
+// verify 校验url的签名与有效期
+func (s *UrlSigner) verify(u *url.URL) error {
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return fmt.Errorf("%w: parse query failed: %w", ErrMalformed, err)
+	}
+	for _, param := range []string{signedUrlKeyIDParam, signedUrlExpiresParam, signedUrlSignatureParam} {
+		if len(query[param]) != 1 {
+			return fmt.Errorf("%w: url must contain exactly one %s parameter", ErrMalformed, param)
+		}
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(query.Get(signedUrlSignatureParam))
+	if err != nil {
+		return fmt.Errorf("%w: decode signature failed: %w", ErrMalformed, err)
+	}
+	expires, err := strconv.ParseInt(query.Get(signedUrlExpiresParam), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: parse expires failed: %w", ErrMalformed, err)
+	}
+	query.Del(signedUrlSignatureParam)
+	kid := query.Get(signedUrlKeyIDParam)
+	expected, err := s.signature(kid, u.EscapedPath(), query)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrTampered, err)
+	}
+	if !hmac.Equal(signature, expected) {
+		return fmt.Errorf("%w: signature mismatch", ErrTampered)
+	}
+	expiresAt := time.Unix(expires, 0)
+	if !s.now().Add(-s.options.ClockSkew).Before(expiresAt) {
+		return fmt.Errorf("%w: url expired at %s", ErrExpired, expiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// signature 计算path与查询参数的hmac
+func (s *UrlSigner) signature(kid, escapedPath string, query url.Values) ([]byte, error) {
+	masterKey, err := s.provider.Key(kid)
+	if err != nil {
+		return nil, fmt.Errorf("get key failed: %w", err)
+	}
+	key, err := DeriveKey(s.hashName, masterKey, signedUrlInfoPrefix+s.hashName, 32)
+	if err != nil {
+		return nil, err
+	}
+	return HmacSum(s.hashName, key, []byte(escapedPath+"?"+query.Encode()))
+}
+
+// now 获取当前时间
+func (s *UrlSigner) now() time.Time {
+	if s.options.Now != nil {
+		return s.options.Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,94 @@
+package crypto
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_UrlSigner(t *testing.T) {
+	provider := StaticKeyProvider{"k1": []byte("0123456789abcdef"), "k2": []byte("fedcba9876543210")}
+	for _, hashName := range []string{HashSha256, HashSm3} {
+		t.Run(hashName, func(t *testing.T) {
+			clock := &testClock{now: time.Unix(1700000000, 0)}
+			options := TokenOptions{ClockSkew: 30 * time.Second, Now: clock.Now}
+			old, err := NewUrlSigner(hashName, provider, "k1", options)
+			if err != nil {
+				t.Fatalf("NewUrlSigner() error = %v", err)
+			}
+			signed, err := old.Sign("https://example.com/files/a%20b.pdf?b=2&a=1", time.Minute)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if !strings.HasPrefix(signed, "https://example.com/files/a%20b.pdf?a=1&b=2&expires=1700000060&kid=k1&signature=") {
+				t.Errorf("Sign() got = %s", signed)
+			}
+			// 轮换后旧密钥签名的url仍然可以校验，且参数顺序与host不影响校验
+			rotated, _ := NewUrlSigner(hashName, provider, "k2", options)
+			if err = rotated.Verify(signed); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+			path, query, _ := strings.Cut(strings.TrimPrefix(signed, "https://example.com"), "?")
+			params := strings.Split(query, "&")
+			params[0], params[1] = params[1], params[0]
+			request := httptest.NewRequest("GET", path+"?"+strings.Join(params, "&"), nil)
+			if err = rotated.VerifyRequest(request); err != nil {
+				t.Errorf("VerifyRequest() error = %v", err)
+			}
+			clock.now = clock.now.Add(time.Minute + 20*time.Second)
+			if err = rotated.Verify(signed); err != nil {
+				t.Errorf("Verify() within clock skew error = %v", err)
+			}
+			clock.now = clock.now.Add(20 * time.Second)
+			if err = rotated.Verify(signed); !errors.Is(err, ErrExpired) {
+				t.Errorf("Verify() expired error = %v, want %v", err, ErrExpired)
+			}
+		})
+	}
+}
+
+func Test_UrlSignerError(t *testing.T) {
+	provider := StaticKeyProvider{"k1": []byte("0123456789abcdef"), "k2": []byte("fedcba9876543210")}
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	signer, _ := NewUrlSigner(HashSha256, provider, "k1", TokenOptions{Now: clock.Now})
+	signed, _ := signer.Sign("/download?file=1", time.Minute)
+	tests := []struct {
+		name string
+		url  string
+		want error
+	}{
+		{name: "url", url: "%zz", want: ErrMalformed},
+		{name: "query", url: "/download?file=%zz", want: ErrMalformed},
+		{name: "missing", url: "/download?file=1", want: ErrMalformed},
+		{name: "duplicate", url: signed + "&kid=k1", want: ErrMalformed},
+		{name: "signature", url: signed + "!", want: ErrMalformed},
+		{name: "expires", url: strings.Replace(signed, "expires=1700000060", "expires=x", 1), want: ErrMalformed},
+		{name: "extend", url: strings.Replace(signed, "expires=1700000060", "expires=1800000000", 1), want: ErrTampered},
+		{name: "param", url: strings.Replace(signed, "file=1", "file=2", 1), want: ErrTampered},
+		{name: "extra", url: signed + "&other=1", want: ErrTampered},
+		{name: "path", url: strings.Replace(signed, "/download", "/upload", 1), want: ErrTampered},
+		{name: "kid", url: strings.Replace(signed, "kid=k1", "kid=k2", 1), want: ErrTampered},
+		{name: "unknown-kid", url: strings.Replace(signed, "kid=k1", "kid=k3", 1), want: ErrTampered},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := signer.Verify(tt.url); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if _, err := signer.Sign("/download?kid=1", time.Minute); err == nil {
+		t.Errorf("Sign() with reserved parameter error = nil, wantErr true")
+	}
+	if _, err := signer.Sign("/download", 0); err == nil {
+		t.Errorf("Sign() with zero ttl error = nil, wantErr true")
+	}
+	if _, err := NewUrlSigner("md5", provider, "k1", TokenOptions{}); err == nil {
+		t.Errorf("NewUrlSigner() with unsupported hash error = nil, wantErr true")
+	}
+	if _, err := NewUrlSigner(OtpHashSha1, provider, "k1", TokenOptions{}); err == nil {
+		t.Errorf("NewUrlSigner() with unsupported hash error = nil, wantErr true")
+	}
+}
@@ -0,0 +1,103 @@
+// Package crypto 签名算法统一接口，便于在国密与国际算法之间切换
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+// Signer 签名器
+type Signer interface {
+	// Sign 对data签名
+	Sign(data []byte) ([]byte, error)
+	// Verifier 获取私钥对应的验签器
+	Verifier() Verifier
+}
+
+// Verifier 验签器
+type Verifier interface {
+	// Verify 验签，签名不匹配时返回错误
+	Verify(data, signature []byte) error
+}
+
+// NewSigner 根据私钥类型构造签名器，支持如下类型：
+// *sm2.PrivateKey: sm2签名（sm3摘要）
+// *ecdsa.PrivateKey: ecdsa签名（摘要算法根据曲线选择）
+// ed25519.PrivateKey: ed25519签名
+// *rsa.PrivateKey: rsa-pss签名（SHA-256摘要）
+func NewSigner(privateKey crypto.PrivateKey) (Signer, error) {
+	switch key := privateKey.(type) {
+	case *sm2.PrivateKey:
+		return &signer{
+			sign:     func(data []byte) ([]byte, error) { return Sm2Sign(key, data) },
+			verifier: &verifier{verify: func(d, s []byte) error { return Sm2Verify(&key.PublicKey, d, s) }},
+		}, nil
+	case *ecdsa.PrivateKey:
+		return &signer{
+			sign:     func(data []byte) ([]byte, error) { return EcdsaSign(key, data) },
+			verifier: &verifier{verify: func(d, s []byte) error { return EcdsaVerify(&key.PublicKey, d, s) }},
+		}, nil
+	case ed25519.PrivateKey:
+		publicKey := key.Public().(ed25519.PublicKey)
+		return &signer{
+			sign:     func(data []byte) ([]byte, error) { return Ed25519Sign(key, data) },
+			verifier: &verifier{verify: func(d, s []byte) error { return Ed25519Verify(publicKey, d, s) }},
+		}, nil
+	case *rsa.PrivateKey:
+		return &signer{
+			sign: func(data []byte) ([]byte, error) { return RsaSignPss(key, data, crypto.SHA256) },
+			verifier: &verifier{verify: func(d, s []byte) error {
+				return RsaVerifyPss(&key.PublicKey, d, s, crypto.SHA256)
+			}},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type: %T", privateKey)
+	}
+}
+
+// NewVerifier 根据公钥类型构造验签器，支持的类型与NewSigner一致
+func NewVerifier(publicKey crypto.PublicKey) (Verifier, error) {
+	switch key := publicKey.(type) {
+	case *sm2.PublicKey:
+		return &verifier{verify: func(d, s []byte) error { return Sm2Verify(key, d, s) }}, nil
+	case *ecdsa.PublicKey:
+		return &verifier{verify: func(d, s []byte) error { return EcdsaVerify(key, d, s) }}, nil
+	case ed25519.PublicKey:
+		return &verifier{verify: func(d, s []byte) error { return Ed25519Verify(key, d, s) }}, nil
+	case *rsa.PublicKey:
+		return &verifier{verify: func(d, s []byte) error { return RsaVerifyPss(key, d, s, crypto.SHA256) }}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type: %T", publicKey)
+	}
+}
+
+// signer Signer的通用实现
+type signer struct {
+	sign     func(data []byte) ([]byte, error)
+	verifier Verifier
+}
+
+// Sign 对data签名
+func (s *signer) Sign(data []byte) ([]byte, error) {
+	return s.sign(data)
+}
+
+// Verifier 获取私钥对应的验签器
+func (s *signer) Verifier() Verifier {
+	return s.verifier
+}
+
+// verifier Verifier的通用实现
+type verifier struct {
+	verify func(data, signature []byte) error
+}
+
+// Verify 验签
+func (v *verifier) Verify(data, signature []byte) error {
+	return v.verify(data, signature)
+}
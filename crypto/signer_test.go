@@ -0,0 +1,66 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+)
+
+func Test_SignerVerifier(t *testing.T) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	ed25519Public, ed25519Private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	tests := []struct {
+		name       string
+		privateKey crypto.PrivateKey
+		publicKey  crypto.PublicKey
+	}{
+		{name: "sm2", privateKey: privateKey, publicKey: publicKey},
+		{name: "ecdsa", privateKey: ecdsaKey, publicKey: &ecdsaKey.PublicKey},
+		{name: "ed25519", privateKey: ed25519Private, publicKey: ed25519Public},
+		{name: "rsa", privateKey: rsaPrivateKey, publicKey: &rsaPrivateKey.PublicKey},
+	}
+	data := []byte("Hello World")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := NewSigner(tt.privateKey)
+			if err != nil {
+				t.Fatalf("NewSigner() error = %v", err)
+			}
+			verifier, err := NewVerifier(tt.publicKey)
+			if err != nil {
+				t.Fatalf("NewVerifier() error = %v", err)
+			}
+			signature, err := signer.Sign(data)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if err := verifier.Verify(data, signature); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+			if err := signer.Verifier().Verify(data, signature); err != nil {
+				t.Errorf("Verifier().Verify() error = %v", err)
+			}
+			if err := verifier.Verify([]byte("Hello World!"), signature); err == nil {
+				t.Errorf("Verify() with tampered data error = nil, wantErr true")
+			}
+		})
+	}
+}
+
+func Test_NewSignerUnsupported(t *testing.T) {
+	if _, err := NewSigner("key"); err == nil {
+		t.Errorf("NewSigner() error = nil, wantErr true")
+	}
+	if _, err := NewVerifier("key"); err == nil {
+		t.Errorf("NewVerifier() error = nil, wantErr true")
+	}
+}
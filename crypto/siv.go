@@ -0,0 +1,395 @@
+// Package crypto siv确定性认证加密工具包
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+/*
+确定性加密：相同的密钥、附加数据与明文总会得到相同的密文，因此可以对加密后的数据库列做等值查询，
+同时仍然具备完整性校验，也不会像ecb模式那样暴露明文的分组结构。
+需要注意确定性加密会暴露"两条密文的明文是否相同"，只应当用于需要等值查询的列。
+
+AES-SIV（RFC 5297）：
+密钥为两个等长的aes密钥拼接（32/48/64字节），前半部分用于S2V（基于CMAC），后半部分用于CTR加密。
+密文格式为：16字节合成iv（同时作为认证标签） || 与明文等长的密文。
+附加数据可以有多个，每个附加数据都会被独立认证，顺序不同结果也不同。
+
+AES-GCM-SIV（RFC 8452）：
+密钥为16或32字节，需要12字节nonce，使用固定nonce时即为确定性加密，使用随机nonce时即为普通的抗nonce误用aead。
+密文格式为：与明文等长的密文 || 16字节认证标签。
+
+SM4-SIV与SM4-GCM-SIV：
+分别将上述两种结构中的aes替换为sm4，用于国密场景，密钥长度分别为32字节与16字节。
+*/
+
+// sivBlockSize siv使用的分组长度
+const sivBlockSize = 16
+
+// ErrSivAuthentication siv认证失败，密文被篡改或密钥、附加数据不匹配
+var ErrSivAuthentication = errors.New("siv authentication failed")
+
+// AesSivEncrypt AES-SIV确定性加密
+// @param key 32、48或64字节密钥
+// @param plaintext 明文
+// @param additionalData 附加认证数据，可以有多个
+func AesSivEncrypt(key, plaintext []byte, additionalData ...[]byte) ([]byte, error) {
+	if len(key) != 32 && len(key) != 48 && len(key) != 64 {
+		return nil, errors.New("key length must be 32, 48 or 64")
+	}
+	return sivEncrypt(aes.NewCipher, key, plaintext, additionalData)
+}
+
+// AesSivDecrypt AES-SIV解密
+// @param key 32、48或64字节密钥
+// @param ciphertext 密文
+// @param additionalData 附加认证数据，需要与加密时一致
+func AesSivDecrypt(key, ciphertext []byte, additionalData ...[]byte) ([]byte, error) {
+	if len(key) != 32 && len(key) != 48 && len(key) != 64 {
+		return nil, errors.New("key length must be 32, 48 or 64")
+	}
+	return sivDecrypt(aes.NewCipher, key, ciphertext, additionalData)
+}
+
+// Sm4SivEncrypt SM4-SIV确定性加密
+// @param key 32字节密钥
+// @param plaintext 明文
+// @param additionalData 附加认证数据，可以有多个
+func Sm4SivEncrypt(key, plaintext []byte, additionalData ...[]byte) ([]byte, error) {
+	if len(key) != 32 {
+		return nil, errors.New("key length must be 32")
+	}
+	return sivEncrypt(NewSm4Cipher, key, plaintext, additionalData)
+}
+
+// Sm4SivDecrypt SM4-SIV解密
+// @param key 32字节密钥
+// @param ciphertext 密文
+// @param additionalData 附加认证数据，需要与加密时一致
+func Sm4SivDecrypt(key, ciphertext []byte, additionalData ...[]byte) ([]byte, error) {
+	if len(key) != 32 {
+		return nil, errors.New("key length must be 32")
+	}
+	return sivDecrypt(NewSm4Cipher, key, ciphertext, additionalData)
+}
+
+// AesGcmSivEncrypt AES-GCM-SIV加密
+// @param key 16或32字节密钥
+// @param nonce 12字节随机数，固定nonce时为确定性加密
+// @param plaintext 明文
+// @param additionalData 附加认证数据，可以为空
+func AesGcmSivEncrypt(key, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newGcmSiv(aes.NewCipher, key)
+	if err != nil {
+		return nil, err
+	}
+	return aeadEncrypt(aead, nonce, plaintext, additionalData)
+}
+
+// AesGcmSivDecrypt AES-GCM-SIV解密
+// @param key 16或32字节密钥
+// @param nonce 12字节随机数
+// @param ciphertext 密文
+// @param additionalData 附加认证数据，需要与加密时一致
+func AesGcmSivDecrypt(key, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := newGcmSiv(aes.NewCipher, key)
+	if err != nil {
+		return nil, err
+	}
+	return aeadDecrypt(aead, nonce, ciphertext, additionalData)
+}
+
+// Sm4GcmSivEncrypt SM4-GCM-SIV加密
+// @param key 16字节密钥
+// @param nonce 12字节随机数，固定nonce时为确定性加密
+// @param plaintext 明文
+// @param additionalData 附加认证数据，可以为空
+func Sm4GcmSivEncrypt(key, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newGcmSiv(NewSm4Cipher, key)
+	if err != nil {
+		return nil, err
+	}
+	return aeadEncrypt(aead, nonce, plaintext, additionalData)
+}
+
+// Sm4GcmSivDecrypt SM4-GCM-SIV解密
+// @param key 16字节密钥
+// @param nonce 12字节随机数
+// @param ciphertext 密文
+// @param additionalData 附加认证数据，需要与加密时一致
+func Sm4GcmSivDecrypt(key, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := newGcmSiv(NewSm4Cipher, key)
+	if err != nil {
+		return nil, err
+	}
+	return aeadDecrypt(aead, nonce, ciphertext, additionalData)
+}
+
+// sivEncrypt RFC 5297 SIV加密
+func sivEncrypt(newBlock func(key []byte) (cipher.Block, error), key, plaintext []byte,
+	additionalData [][]byte) ([]byte, error) {
+	macBlock, ctrBlock, err := newSivBlocks(newBlock, key)
+	if err != nil {
+		return nil, err
+	}
+	v := s2v(macBlock, additionalData, plaintext)
+	ciphertext := make([]byte, sivBlockSize+len(plaintext))
+	copy(ciphertext, v)
+	sivCtr(ctrBlock, v).XORKeyStream(ciphertext[sivBlockSize:], plaintext)
+	return ciphertext, nil
+}
+
+// sivDecrypt RFC 5297 SIV解密
+func sivDecrypt(newBlock func(key []byte) (cipher.Block, error), key, ciphertext []byte,
+	additionalData [][]byte) ([]byte, error) {
+	if len(ciphertext) < sivBlockSize {
+		return nil, errors.New("ciphertext too short")
+	}
+	macBlock, ctrBlock, err := newSivBlocks(newBlock, key)
+	if err != nil {
+		return nil, err
+	}
+	v := ciphertext[:sivBlockSize]
+	plaintext := make([]byte, len(ciphertext)-sivBlockSize)
+	sivCtr(ctrBlock, v).XORKeyStream(plaintext, ciphertext[sivBlockSize:])
+	if subtle.ConstantTimeCompare(s2v(macBlock, additionalData, plaintext), v) != 1 {
+		return nil, ErrSivAuthentication
+	}
+	return plaintext, nil
+}
+
+// newSivBlocks 将密钥拆分为S2V与CTR使用的两个分组密码
+func newSivBlocks(newBlock func(key []byte) (cipher.Block, error), key []byte) (cipher.Block, cipher.Block, error) {
+	macBlock, err := newBlock(key[:len(key)/2])
+	if err != nil {
+		return nil, nil, fmt.Errorf("create block failed: %w", err)
+	}
+	ctrBlock, err := newBlock(key[len(key)/2:])
+	if err != nil {
+		return nil, nil, fmt.Errorf("create block failed: %w", err)
+	}
+	if macBlock.BlockSize() != sivBlockSize {
+		return nil, nil, errors.New("block size must be 16")
+	}
+	return macBlock, ctrBlock, nil
+}
+
+// sivCtr 使用合成iv创建CTR，iv的第31与63位（从右数）需要置0
+func sivCtr(block cipher.Block, v []byte) cipher.Stream {
+	q := make([]byte, sivBlockSize)
+	copy(q, v)
+	q[8] &= 0x7f
+	q[12] &= 0x7f
+	return cipher.NewCTR(block, q)
+}
+
+// s2v RFC 5297 S2V算法，最后一个输入为明文
+func s2v(block cipher.Block, additionalData [][]byte, plaintext []byte) []byte {
+	d := cmacSum(block, make([]byte, sivBlockSize))
+	for _, ad := range additionalData {
+		d = cmacDouble(d)
+		subtle.XORBytes(d, d, cmacSum(block, ad))
+	}
+	var t []byte
+	if len(plaintext) >= sivBlockSize {
+		t = make([]byte, len(plaintext))
+		copy(t, plaintext)
+		end := t[len(t)-sivBlockSize:]
+		subtle.XORBytes(end, end, d)
+	} else {
+		t = cmacDouble(d)
+		subtle.XORBytes(t, t, iso7816Pad(plaintext, sivBlockSize))
+	}
+	return cmacSum(block, t)
+}
+
+// gcmSiv RFC 8452 AES-GCM-SIV，实现cipher.AEAD接口
+type gcmSiv struct {
+	newBlock func(key []byte) (cipher.Block, error) // 分组密码构造函数
+	block    cipher.Block                           // 使用主密钥（key-generating key）的分组密码
+	keySize  int                                    // 主密钥长度
+}
+
+// newGcmSiv 创建GCM-SIV
+func newGcmSiv(newBlock func(key []byte) (cipher.Block, error), key []byte) (cipher.AEAD, error) {
+	if len(key) != 16 && len(key) != 32 {
+		return nil, errors.New("key length must be 16 or 32")
+	}
+	block, err := newBlock(key)
+	if err != nil {
+		return nil, fmt.Errorf("create block failed: %w", err)
+	}
+	if block.BlockSize() != sivBlockSize {
+		return nil, errors.New("block size must be 16")
+	}
+	return &gcmSiv{newBlock: newBlock, block: block, keySize: len(key)}, nil
+}
+
+// NonceSize nonce长度
+func (g *gcmSiv) NonceSize() int {
+	return 12
+}
+
+// Overhead 密文相比明文增加的长度
+func (g *gcmSiv) Overhead() int {
+	return sivBlockSize
+}
+
+// Seal 加密并认证
+func (g *gcmSiv) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	authKey, encBlock, err := g.deriveKeys(nonce)
+	if err != nil {
+		panic(err)
+	}
+	tag := g.tag(authKey, encBlock, nonce, plaintext, additionalData)
+	ret, out := sliceForAppend(dst, len(plaintext)+sivBlockSize)
+	gcmSivCtr(encBlock, tag, out, plaintext)
+	copy(out[len(plaintext):], tag)
+	return ret
+}
+
+// Open 解密并校验
+func (g *gcmSiv) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < sivBlockSize {
+		return nil, errors.New("ciphertext too short")
+	}
+	authKey, encBlock, err := g.deriveKeys(nonce)
+	if err != nil {
+		return nil, err
+	}
+	tag := ciphertext[len(ciphertext)-sivBlockSize:]
+	ciphertext = ciphertext[:len(ciphertext)-sivBlockSize]
+	plaintext := make([]byte, len(ciphertext))
+	gcmSivCtr(encBlock, tag, plaintext, ciphertext)
+	if subtle.ConstantTimeCompare(g.tag(authKey, encBlock, nonce, plaintext, additionalData), tag) != 1 {
+		return nil, ErrSivAuthentication
+	}
+	return append(dst, plaintext...), nil
+}
+
+// deriveKeys 通过主密钥与nonce派生消息认证密钥与加密密钥
+func (g *gcmSiv) deriveKeys(nonce []byte) ([]byte, cipher.Block, error) {
+	if len(nonce) != g.NonceSize() {
+		return nil, nil, fmt.Errorf("nonce length must be %d", g.NonceSize())
+	}
+	derived := make([]byte, 0, 16+g.keySize)
+	in, out := make([]byte, sivBlockSize), make([]byte, sivBlockSize)
+	copy(in[4:], nonce)
+	for i := uint32(0); len(derived) < cap(derived); i++ {
+		binary.LittleEndian.PutUint32(in, i)
+		g.block.Encrypt(out, in)
+		derived = append(derived, out[:8]...)
+	}
+	encBlock, err := g.newBlock(derived[16:])
+	if err != nil {
+		return nil, nil, fmt.Errorf("create block failed: %w", err)
+	}
+	return derived[:16], encBlock, nil
+}
+
+// tag 计算认证标签
+func (g *gcmSiv) tag(authKey []byte, encBlock cipher.Block, nonce, plaintext, additionalData []byte) []byte {
+	p := newPolyval(authKey)
+	p.update(additionalData)
+	p.update(plaintext)
+	lengths := make([]byte, sivBlockSize)
+	binary.LittleEndian.PutUint64(lengths, uint64(len(additionalData))*8)
+	binary.LittleEndian.PutUint64(lengths[8:], uint64(len(plaintext))*8)
+	p.update(lengths)
+	s := p.sum()
+	subtle.XORBytes(s, s, nonce)
+	s[15] &= 0x7f
+	encBlock.Encrypt(s, s)
+	return s
+}
+
+// gcmSivCtr GCM-SIV使用的CTR模式，计数器为前4字节的小端序整数
+func gcmSivCtr(block cipher.Block, tag, dst, src []byte) {
+	counter := make([]byte, sivBlockSize)
+	copy(counter, tag)
+	counter[15] |= 0x80
+	keyStream := make([]byte, sivBlockSize)
+	for start := 0; start < len(src); start += sivBlockSize {
+		block.Encrypt(keyStream, counter)
+		end := min(start+sivBlockSize, len(src))
+		subtle.XORBytes(dst[start:end], src[start:end], keyStream)
+		binary.LittleEndian.PutUint32(counter, binary.LittleEndian.Uint32(counter)+1)
+	}
+}
+
+// polyval RFC 8452 POLYVAL通用哈希，GF(2^128)元素使用小端序，由lo（x^0~x^63）与hi（x^64~x^127）表示
+type polyval struct {
+	hLo, hHi uint64 // 哈希密钥
+	sLo, sHi uint64 // 当前状态
+}
+
+// newPolyval 创建POLYVAL，key为16字节
+func newPolyval(key []byte) *polyval {
+	return &polyval{hLo: binary.LittleEndian.Uint64(key), hHi: binary.LittleEndian.Uint64(key[8:])}
+}
+
+// update 写入数据，不足一个分组的部分补0
+func (p *polyval) update(data []byte) {
+	for start := 0; start < len(data); start += sivBlockSize {
+		block := make([]byte, sivBlockSize)
+		copy(block, data[start:])
+		p.sLo ^= binary.LittleEndian.Uint64(block)
+		p.sHi ^= binary.LittleEndian.Uint64(block[8:])
+		p.sLo, p.sHi = polyvalDot(p.sLo, p.sHi, p.hLo, p.hHi)
+	}
+}
+
+// sum 获取哈希结果
+func (p *polyval) sum() []byte {
+	s := make([]byte, sivBlockSize)
+	binary.LittleEndian.PutUint64(s, p.sLo)
+	binary.LittleEndian.PutUint64(s[8:], p.sHi)
+	return s
+}
+
+// polyvalDot 计算a*b*x^-128，x^-128 = x^127 + x^124 + x^121 + x^114 + 1
+func polyvalDot(aLo, aHi, bLo, bHi uint64) (uint64, uint64) {
+	lo, hi := polyvalMul(aLo, aHi, bLo, bHi)
+	return polyvalMul(lo, hi, 1, 1<<63|1<<60|1<<57|1<<50)
+}
+
+// polyvalMul 计算a*b mod (x^128 + x^127 + x^126 + x^121 + 1)，按位运算且与数据无关的分支
+func polyvalMul(aLo, aHi, bLo, bHi uint64) (uint64, uint64) {
+	var lo, hi uint64
+	for i := 127; i >= 0; i-- {
+		// 乘以x并约减
+		carry := -(hi >> 63)
+		hi = hi<<1 | lo>>63
+		lo <<= 1
+		hi ^= carry & 0xc200000000000000
+		lo ^= carry & 1
+		// 累加a
+		var bit uint64
+		if i >= 64 {
+			bit = bHi >> (i - 64) & 1
+		} else {
+			bit = bLo >> i & 1
+		}
+		mask := -bit
+		lo ^= aLo & mask
+		hi ^= aHi & mask
+	}
+	return lo, hi
+}
+
+// sliceForAppend 扩展切片长度，返回扩展后的切片与新增部分
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
@@ -0,0 +1,197 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_AesSivEncryptDecrypt(t *testing.T) {
+	tests := []struct {
+		name           string
+		key            string
+		additionalData []string
+		plaintext      string
+		want           string
+	}{
+		{
+			// RFC 5297 附录A.1测试向量
+			name:           "rfc5297-a1",
+			key:            "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff",
+			additionalData: []string{"101112131415161718191a1b1c1d1e1f2021222324252627"},
+			plaintext:      "112233445566778899aabbccddee",
+			want:           "85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c",
+		},
+		{
+			// RFC 5297 附录A.2测试向量
+			name: "rfc5297-a2",
+			key:  "7f7e7d7c7b7a79787776757473727170404142434445464748494a4b4c4d4e4f",
+			additionalData: []string{
+				"00112233445566778899aabbccddeeffdeaddadadeaddadaffeeddccbbaa99887766554433221100",
+				"102030405060708090a0",
+				"09f911029d74e35bd84156c5635688c0",
+			},
+			plaintext: "7468697320697320736f6d6520706c61696e7465787420746f20656e63727970" +
+				"74207573696e67205349562d414553",
+			want: "7bdb6e3b432667eb06f4d14bff2fbd0fcb900f2fddbe404326601965c889bf17" +
+				"dba77ceb094fa663b7a3f748ba8af829ea64ad544a272e9c485b62a3fd5c0d",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, plaintext := mustHex(tt.key), mustHex(tt.plaintext)
+			var additionalData [][]byte
+			for _, ad := range tt.additionalData {
+				additionalData = append(additionalData, mustHex(ad))
+			}
+			got, err := AesSivEncrypt(key, plaintext, additionalData...)
+			if err != nil {
+				t.Errorf("AesSivEncrypt() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, mustHex(tt.want)) {
+				t.Errorf("AesSivEncrypt() got = %x, want %v", got, tt.want)
+			}
+			got, err = AesSivDecrypt(key, got, additionalData...)
+			if err != nil {
+				t.Errorf("AesSivDecrypt() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, plaintext) {
+				t.Errorf("AesSivDecrypt() got = %x, want %x", got, plaintext)
+			}
+			if _, err := AesSivDecrypt(key, mustHex(tt.want)); !errors.Is(err, ErrSivAuthentication) {
+				t.Errorf("AesSivDecrypt() without additional data error = %v, want %v", err, ErrSivAuthentication)
+			}
+		})
+	}
+}
+
+func Test_AesGcmSivEncryptDecrypt(t *testing.T) {
+	tests := []struct {
+		name           string
+		key            string
+		nonce          string
+		plaintext      string
+		additionalData string
+		want           string
+	}{
+		{
+			// RFC 8452 附录C.1测试向量
+			name:  "aes-128-empty",
+			key:   "01000000000000000000000000000000",
+			nonce: "030000000000000000000000",
+			want:  "dc20e2d83f25705bb49e439eca56de25",
+		},
+		{
+			// RFC 8452 附录C.1测试向量
+			name:      "aes-128-8bytes",
+			key:       "01000000000000000000000000000000",
+			nonce:     "030000000000000000000000",
+			plaintext: "0100000000000000",
+			want:      "b5d839330ac7b786578782fff6013b815b287c22493a364c",
+		},
+		{
+			// RFC 8452 附录C.2测试向量
+			name:      "aes-256-8bytes",
+			key:       "0100000000000000000000000000000000000000000000000000000000000000",
+			nonce:     "030000000000000000000000",
+			plaintext: "0100000000000000",
+			want:      "c2ef328e5c71c83b843122130f7364b761e0b97427e3df28",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, nonce := mustHex(tt.key), mustHex(tt.nonce)
+			plaintext, additionalData := mustHex(tt.plaintext), mustHex(tt.additionalData)
+			got, err := AesGcmSivEncrypt(key, nonce, plaintext, additionalData)
+			if err != nil {
+				t.Errorf("AesGcmSivEncrypt() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, mustHex(tt.want)) {
+				t.Errorf("AesGcmSivEncrypt() got = %x, want %v", got, tt.want)
+			}
+			got, err = AesGcmSivDecrypt(key, nonce, got, additionalData)
+			if err != nil {
+				t.Errorf("AesGcmSivDecrypt() error = %v", err)
+				return
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Errorf("AesGcmSivDecrypt() got = %x, want %x", got, plaintext)
+			}
+		})
+	}
+}
+
+func Test_Sm4SivDeterministic(t *testing.T) {
+	key := mustHex("0123456789abcdeffedcba98765432100123456789abcdeffedcba9876543210")
+	nonce := make([]byte, 12)
+	tests := []struct {
+		name    string
+		encrypt func(plaintext, additionalData []byte) ([]byte, error)
+		decrypt func(ciphertext, additionalData []byte) ([]byte, error)
+	}{
+		{
+			name:    "sm4-siv",
+			encrypt: func(p, ad []byte) ([]byte, error) { return Sm4SivEncrypt(key, p, ad) },
+			decrypt: func(c, ad []byte) ([]byte, error) { return Sm4SivDecrypt(key, c, ad) },
+		},
+		{
+			name:    "sm4-gcm-siv",
+			encrypt: func(p, ad []byte) ([]byte, error) { return Sm4GcmSivEncrypt(key[:16], nonce, p, ad) },
+			decrypt: func(c, ad []byte) ([]byte, error) { return Sm4GcmSivDecrypt(key[:16], nonce, c, ad) },
+		},
+	}
+	plaintext := []byte("13800138000")
+	additionalData := []byte("user.phone")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c1, err := tt.encrypt(plaintext, additionalData)
+			if err != nil {
+				t.Fatalf("encrypt error = %v", err)
+			}
+			c2, err := tt.encrypt(plaintext, additionalData)
+			if err != nil {
+				t.Fatalf("encrypt error = %v", err)
+			}
+			if !bytes.Equal(c1, c2) {
+				t.Errorf("encrypt is not deterministic: %x != %x", c1, c2)
+			}
+			c3, err := tt.encrypt(plaintext, []byte("user.mobile"))
+			if err != nil {
+				t.Fatalf("encrypt error = %v", err)
+			}
+			if bytes.Equal(c1, c3) {
+				t.Errorf("encrypt with different additional data got same ciphertext")
+			}
+			got, err := tt.decrypt(c1, additionalData)
+			if err != nil {
+				t.Fatalf("decrypt error = %v", err)
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Errorf("decrypt got = %s, want %s", got, plaintext)
+			}
+			c1[0] ^= 1
+			if _, err := tt.decrypt(c1, additionalData); !errors.Is(err, ErrSivAuthentication) {
+				t.Errorf("decrypt tampered ciphertext error = %v, want %v", err, ErrSivAuthentication)
+			}
+		})
+	}
+}
+
+func Test_SivInvalidInput(t *testing.T) {
+	if _, err := AesSivEncrypt(make([]byte, 16), nil); err == nil {
+		t.Errorf("AesSivEncrypt() with short key error = nil, wantErr true")
+	}
+	if _, err := AesSivDecrypt(make([]byte, 32), make([]byte, 8)); err == nil {
+		t.Errorf("AesSivDecrypt() with short ciphertext error = nil, wantErr true")
+	}
+	if _, err := AesGcmSivEncrypt(make([]byte, 24), make([]byte, 12), nil, nil); err == nil {
+		t.Errorf("AesGcmSivEncrypt() with invalid key error = nil, wantErr true")
+	}
+	if _, err := AesGcmSivDecrypt(make([]byte, 16), make([]byte, 12), make([]byte, 8), nil); err == nil {
+		t.Errorf("AesGcmSivDecrypt() with short ciphertext error = nil, wantErr true")
+	}
+}
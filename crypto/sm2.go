@@ -52,6 +52,19 @@ func CreateSm2PublicKeyWithBase64(publicKey string) (*sm2.PublicKey, error) {
 	return x509.ReadPublicKeyFromHex(hex.EncodeToString(k))
 }
 
+// Sm2PrivateKeyToBase64 将sm2私钥导出为base64编码字符串，与CreateSm2PrivateKeyWithBase64互逆
+func Sm2PrivateKeyToBase64(privateKey *sm2.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(privateKey.D.FillBytes(make([]byte, 32)))
+}
+
+// Sm2PublicKeyToBase64 将sm2公钥导出为base64编码字符串（X||Y），与CreateSm2PublicKeyWithBase64互逆
+func Sm2PublicKeyToBase64(publicKey *sm2.PublicKey) string {
+	k := make([]byte, 64)
+	publicKey.X.FillBytes(k[:32])
+	publicKey.Y.FillBytes(k[32:])
+	return base64.StdEncoding.EncodeToString(k)
+}
+
 // Sm2EncryptAsn1 sm2加密并使用asn.1编码
 // @param publicKey 公钥
 // @param plaintext 明文内容
@@ -83,3 +96,25 @@ func Sm2DecryptAsn1(privateKey *sm2.PrivateKey, ciphertext []byte, mode int) ([]
 	}
 	return plaintext, err
 }
+
+// Sm2Sign sm2签名，使用sm3摘要与默认用户id，签名结果为asn.1编码
+// @param privateKey 私钥
+// @param data 待签名内容
+func Sm2Sign(privateKey *sm2.PrivateKey, data []byte) ([]byte, error) {
+	signature, err := privateKey.Sign(rand.Reader, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("sign failed: %w", err)
+	}
+	return signature, nil
+}
+
+// Sm2Verify sm2验签，签名不匹配时返回错误
+// @param publicKey 公钥
+// @param data 签名原文
+// @param signature asn.1编码的签名
+func Sm2Verify(publicKey *sm2.PublicKey, data, signature []byte) error {
+	if !publicKey.Verify(data, signature) {
+		return errors.New("verify failed")
+	}
+	return nil
+}
@@ -115,3 +115,26 @@ func Test_Sm2EncryptDecryptAsn1(t *testing.T) {
 		})
 	}
 }
+
+func Test_Sm2KeyToBase64(t *testing.T) {
+	if got := Sm2PrivateKeyToBase64(privateKey); got != base64.StdEncoding.EncodeToString(privateKeyBytes) {
+		t.Errorf("Sm2PrivateKeyToBase64() got = %v", got)
+	}
+	if got := Sm2PublicKeyToBase64(publicKey); got != base64.StdEncoding.EncodeToString(publicKeyBytes) {
+		t.Errorf("Sm2PublicKeyToBase64() got = %v", got)
+	}
+}
+
+func Test_Sm2SignVerify(t *testing.T) {
+	data := []byte("Hello World")
+	signature, err := Sm2Sign(privateKey, data)
+	if err != nil {
+		t.Fatalf("Sm2Sign() error = %v", err)
+	}
+	if err := Sm2Verify(publicKey, data, signature); err != nil {
+		t.Errorf("Sm2Verify() error = %v", err)
+	}
+	if err := Sm2Verify(publicKey, []byte("Hello World!"), signature); err == nil {
+		t.Errorf("Sm2Verify() with tampered data error = nil, wantErr true")
+	}
+}
@@ -4,8 +4,6 @@ package crypto
 import (
 	"crypto/cipher"
 	"fmt"
-
-	"github.com/tjfoc/gmsm/sm4"
 )
 
 // Sm4CbcEncrypt cbc模式的sm4加密
@@ -19,7 +17,7 @@ func Sm4CbcEncrypt(key, iv, plaintext []byte, padding string) (ciphertext []byte
 			err = fmt.Errorf("encrypt blocks failed: %+v", rc)
 		}
 	}()
-	block, err := sm4.NewCipher(key)
+	block, err := NewSm4Cipher(key)
 	if err != nil {
 		return nil, fmt.Errorf("create sm4 ciphter failed, err: %w", err)
 	}
@@ -41,7 +39,7 @@ func Sm4CbcDecrypt(key, iv, ciphertext []byte, padding string) (plaintext []byte
 			err = fmt.Errorf("decrypt blocks failed: %+v", rc)
 		}
 	}()
-	block, err := sm4.NewCipher(key)
+	block, err := NewSm4Cipher(key)
 	if err != nil {
 		return nil, fmt.Errorf("create sm4 ciphter failed: %w", err)
 	}
@@ -50,3 +48,42 @@ func Sm4CbcDecrypt(key, iv, ciphertext []byte, padding string) (plaintext []byte
 	decrypter.CryptBlocks(plaintext, ciphertext)
 	return UnPadding(padding, plaintext), nil
 }
+
+// Sm4GcmEncrypt gcm模式的sm4加密
+// @param key 密钥
+// @param nonce 12字节随机数
+// @param plaintext 明文内容
+// @param additionalData 附加认证数据，可以为空
+func Sm4GcmEncrypt(key, nonce, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newSm4Gcm(key)
+	if err != nil {
+		return nil, err
+	}
+	return aeadEncrypt(aead, nonce, plaintext, additionalData)
+}
+
+// Sm4GcmDecrypt gcm模式的sm4解密
+// @param key 密钥
+// @param nonce 12字节随机数
+// @param ciphertext 密文
+// @param additionalData 附加认证数据，需要与加密时一致
+func Sm4GcmDecrypt(key, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := newSm4Gcm(key)
+	if err != nil {
+		return nil, err
+	}
+	return aeadDecrypt(aead, nonce, ciphertext, additionalData)
+}
+
+// newSm4Gcm 创建sm4-gcm
+func newSm4Gcm(key []byte) (cipher.AEAD, error) {
+	block, err := NewSm4Cipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create sm4 ciphter failed: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm failed: %w", err)
+	}
+	return aead, nil
+}
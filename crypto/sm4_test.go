@@ -100,3 +100,32 @@ func Test_Sm4CbcDecrypt(t *testing.T) {
 		})
 	}
 }
+
+func Test_Sm4GcmEncryptDecrypt(t *testing.T) {
+	// RFC 8998 附录A.1测试向量
+	key := mustHex("0123456789abcdeffedcba9876543210")
+	nonce := mustHex("00001234567800000000abcd")
+	additionalData := mustHex("feedfacedeadbeeffeedfacedeadbeefabaddad2")
+	plaintext := mustHex("aaaaaaaaaaaaaaaabbbbbbbbbbbbbbbbccccccccccccccccdddddddddddddddd" +
+		"eeeeeeeeeeeeeeeeffffffffffffffffeeeeeeeeeeeeeeeeaaaaaaaaaaaaaaaa")
+	want := mustHex("17f399f08c67d5ee19d0dc9969c4bb7d5fd46fd3756489069157b282bb200735" +
+		"d82710ca5c22f0ccfa7cbf93d496ac15a56834cbcf98c397b4024a2691233b8d" +
+		"83de3541e4c2b58177e065a9bf7b62ec")
+	got, err := Sm4GcmEncrypt(key, nonce, plaintext, additionalData)
+	if err != nil {
+		t.Fatalf("Sm4GcmEncrypt() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Sm4GcmEncrypt() got = %x, want %x", got, want)
+	}
+	got, err = Sm4GcmDecrypt(key, nonce, want, additionalData)
+	if err != nil {
+		t.Fatalf("Sm4GcmDecrypt() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, plaintext) {
+		t.Errorf("Sm4GcmDecrypt() got = %x, want %x", got, plaintext)
+	}
+	if _, err := Sm4GcmDecrypt(key, nonce[:8], want, additionalData); err == nil {
+		t.Errorf("Sm4GcmDecrypt() with short nonce error = nil, wantErr true")
+	}
+}
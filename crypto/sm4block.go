@@ -0,0 +1,411 @@
+// Package crypto sm4分组密码实现
+package crypto
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+)
+
+/*
+sm4分组密码（GB/T 32907-2016）的纯go实现，替代tjfoc/gmsm中的通用实现，提供两种实现：
+T表实现（NewSm4Cipher）：将s盒与线性变换L合并为4张256项的uint32表（共4KiB），每轮4次查表，
+    是所有sm4工具函数默认使用的实现；查表地址与数据相关，共享cpu的场景下存在缓存时序侧信道。
+比特切片实现（NewSm4BitslicedCipher）：常量时间实现，不做任何与数据相关的查表或分支，
+    s盒为 A·I(A·x+C)+C（I为GF(2^8)上以x^8+x^7+x^6+x^5+x^4+x^2+1为模的求逆，C=0xd3），
+    将求逆同构映射到复合域GF((2^4)^2)（GF(2^4)以z^4+z+1为模，扩域以Y^2+Y+λ为模，λ=z^3+1）后只需要3次GF(2^4)乘法与1次GF(2^4)求逆，
+    仿射变换与同构映射合并为输入、输出两个线性层。
+    EncryptBlocks与DecryptBlocks每次将64个分组转置为按比特位组织的64位字，一次计算64个分组，
+    Encrypt与Decrypt只处理一个分组，将一轮的4个s盒放在同一个字的4个字节中计算，速度远低于批量接口，
+    适合配合ecb、EcbEncryptParallel等可以批量处理的场景使用。
+两种实现均实现MultiBlock接口，创建后不再修改内部状态，可以在多个goroutine中共用。
+*/
+
+// sm4相关常量
+const (
+	sm4BlockSize = 16
+	sm4KeySize   = 16
+	sm4Rounds    = 32
+	// sm4BitslicedLanes 比特切片实现每次批量处理的分组数
+	sm4BitslicedLanes = 64
+)
+
+// MultiBlock 可以一次处理多个分组的分组密码，ecb等模式优先使用该接口
+type MultiBlock interface {
+	cipher.Block
+	// EncryptBlocks 加密多个分组，src长度需要是分组长度的整数倍，dst与src可以完全重叠
+	EncryptBlocks(dst, src []byte)
+	// DecryptBlocks 解密多个分组
+	DecryptBlocks(dst, src []byte)
+}
+
+// sm4Sbox sm4的s盒
+var sm4Sbox = [256]byte{
+	0xd6, 0x90, 0xe9, 0xfe, 0xcc, 0xe1, 0x3d, 0xb7, 0x16, 0xb6, 0x14, 0xc2, 0x28, 0xfb, 0x2c, 0x05,
+	0x2b, 0x67, 0x9a, 0x76, 0x2a, 0xbe, 0x04, 0xc3, 0xaa, 0x44, 0x13, 0x26, 0x49, 0x86, 0x06, 0x99,
+	0x9c, 0x42, 0x50, 0xf4, 0x91, 0xef, 0x98, 0x7a, 0x33, 0x54, 0x0b, 0x43, 0xed, 0xcf, 0xac, 0x62,
+	0xe4, 0xb3, 0x1c, 0xa9, 0xc9, 0x08, 0xe8, 0x95, 0x80, 0xdf, 0x94, 0xfa, 0x75, 0x8f, 0x3f, 0xa6,
+	0x47, 0x07, 0xa7, 0xfc, 0xf3, 0x73, 0x17, 0xba, 0x83, 0x59, 0x3c, 0x19, 0xe6, 0x85, 0x4f, 0xa8,
+	0x68, 0x6b, 0x81, 0xb2, 0x71, 0x64, 0xda, 0x8b, 0xf8, 0xeb, 0x0f, 0x4b, 0x70, 0x56, 0x9d, 0x35,
+	0x1e, 0x24, 0x0e, 0x5e, 0x63, 0x58, 0xd1, 0xa2, 0x25, 0x22, 0x7c, 0x3b, 0x01, 0x21, 0x78, 0x87,
+	0xd4, 0x00, 0x46, 0x57, 0x9f, 0xd3, 0x27, 0x52, 0x4c, 0x36, 0x02, 0xe7, 0xa0, 0xc4, 0xc8, 0x9e,
+	0xea, 0xbf, 0x8a, 0xd2, 0x40, 0xc7, 0x38, 0xb5, 0xa3, 0xf7, 0xf2, 0xce, 0xf9, 0x61, 0x15, 0xa1,
+	0xe0, 0xae, 0x5d, 0xa4, 0x9b, 0x34, 0x1a, 0x55, 0xad, 0x93, 0x32, 0x30, 0xf5, 0x8c, 0xb1, 0xe3,
+	0x1d, 0xf6, 0xe2, 0x2e, 0x82, 0x66, 0xca, 0x60, 0xc0, 0x29, 0x23, 0xab, 0x0d, 0x53, 0x4e, 0x6f,
+	0xd5, 0xdb, 0x37, 0x45, 0xde, 0xfd, 0x8e, 0x2f, 0x03, 0xff, 0x6a, 0x72, 0x6d, 0x6c, 0x5b, 0x51,
+	0x8d, 0x1b, 0xaf, 0x92, 0xbb, 0xdd, 0xbc, 0x7f, 0x11, 0xd9, 0x5c, 0x41, 0x1f, 0x10, 0x5a, 0xd8,
+	0x0a, 0xc1, 0x31, 0x88, 0xa5, 0xcd, 0x7b, 0xbd, 0x2d, 0x74, 0xd0, 0x12, 0xb8, 0xe5, 0xb4, 0xb0,
+	0x89, 0x69, 0x97, 0x4a, 0x0c, 0x96, 0x77, 0x7e, 0x65, 0xb9, 0xf1, 0x09, 0xc5, 0x6e, 0xc6, 0x84,
+	0x18, 0xf0, 0x7d, 0xec, 0x3a, 0xdc, 0x4d, 0x20, 0x79, 0xee, 0x5f, 0x3e, 0xd7, 0xcb, 0x39, 0x48,
+}
+
+// sm4Fk 密钥扩展使用的系统参数
+var sm4Fk = [4]uint32{0xa3b1bac6, 0x56aa3350, 0x677d9197, 0xb27022dc}
+
+// sm4Table T表，sm4Table[k][a] = L(S(a) << (24-8k))，即第k个字节（从高位开始）为a时T变换的结果
+var sm4Table = newSm4Table()
+
+// sm4TableCipher T表实现的sm4
+type sm4TableCipher struct {
+	rk  [sm4Rounds]uint32 // 加密轮密钥
+	drk [sm4Rounds]uint32 // 解密轮密钥，即逆序的加密轮密钥
+}
+
+// sm4BitslicedCipher 比特切片实现的sm4
+type sm4BitslicedCipher struct {
+	rk  [sm4Rounds]uint32 // 加密轮密钥
+	drk [sm4Rounds]uint32 // 解密轮密钥
+}
+
+// NewSm4Cipher 创建T表实现的sm4分组密码，所有sm4工具函数默认使用该实现
+// @param key 16字节密钥
+func NewSm4Cipher(key []byte) (cipher.Block, error) {
+	if len(key) != sm4KeySize {
+		return nil, fmt.Errorf("invalid sm4 key length: %d", len(key))
+	}
+	c := &sm4TableCipher{}
+	c.rk = sm4ExpandKey(key, sm4TableTau)
+	c.drk = sm4ReverseKey(c.rk)
+	return c, nil
+}
+
+// NewSm4BitslicedCipher 创建常量时间的比特切片实现的sm4分组密码，多个分组时应当使用EncryptBlocks与DecryptBlocks
+// @param key 16字节密钥
+func NewSm4BitslicedCipher(key []byte) (cipher.Block, error) {
+	if len(key) != sm4KeySize {
+		return nil, fmt.Errorf("invalid sm4 key length: %d", len(key))
+	}
+	c := &sm4BitslicedCipher{}
+	c.rk = sm4ExpandKey(key, sm4BitslicedTau)
+	c.drk = sm4ReverseKey(c.rk)
+	return c, nil
+}
+
+// BlockSize 分组长度
+func (c *sm4TableCipher) BlockSize() int {
+	return sm4BlockSize
+}
+
+// Encrypt 加密一个分组
+func (c *sm4TableCipher) Encrypt(dst, src []byte) {
+	sm4CheckBlocks(dst, src, sm4BlockSize)
+	sm4TableCrypt(&c.rk, dst, src)
+}
+
+// Decrypt 解密一个分组
+func (c *sm4TableCipher) Decrypt(dst, src []byte) {
+	sm4CheckBlocks(dst, src, sm4BlockSize)
+	sm4TableCrypt(&c.drk, dst, src)
+}
+
+// EncryptBlocks 加密多个分组
+func (c *sm4TableCipher) EncryptBlocks(dst, src []byte) {
+	sm4CheckBlocks(dst, src, len(src))
+	for i := 0; i < len(src); i += sm4BlockSize {
+		sm4TableCrypt(&c.rk, dst[i:], src[i:])
+	}
+}
+
+// DecryptBlocks 解密多个分组
+func (c *sm4TableCipher) DecryptBlocks(dst, src []byte) {
+	sm4CheckBlocks(dst, src, len(src))
+	for i := 0; i < len(src); i += sm4BlockSize {
+		sm4TableCrypt(&c.drk, dst[i:], src[i:])
+	}
+}
+
+// BlockSize 分组长度
+func (c *sm4BitslicedCipher) BlockSize() int {
+	return sm4BlockSize
+}
+
+// Encrypt 加密一个分组
+func (c *sm4BitslicedCipher) Encrypt(dst, src []byte) {
+	sm4CheckBlocks(dst, src, sm4BlockSize)
+	sm4BitslicedCryptBlock(&c.rk, dst, src)
+}
+
+// Decrypt 解密一个分组
+func (c *sm4BitslicedCipher) Decrypt(dst, src []byte) {
+	sm4CheckBlocks(dst, src, sm4BlockSize)
+	sm4BitslicedCryptBlock(&c.drk, dst, src)
+}
+
+// EncryptBlocks 批量加密多个分组
+func (c *sm4BitslicedCipher) EncryptBlocks(dst, src []byte) {
+	sm4CheckBlocks(dst, src, len(src))
+	sm4BitslicedCryptBlocks(&c.rk, dst, src)
+}
+
+// DecryptBlocks 批量解密多个分组
+func (c *sm4BitslicedCipher) DecryptBlocks(dst, src []byte) {
+	sm4CheckBlocks(dst, src, len(src))
+	sm4BitslicedCryptBlocks(&c.drk, dst, src)
+}
+
+// sm4CheckBlocks 校验输入输出长度，与标准库分组密码一致，不满足时panic
+func sm4CheckBlocks(dst, src []byte, length int) {
+	if len(src) < length || length%sm4BlockSize != 0 {
+		panic("crypto/sm4: input not full block")
+	}
+	if len(dst) < length {
+		panic("crypto/sm4: output not full block")
+	}
+}
+
+// sm4ExpandKey 密钥扩展，tau为非线性变换τ的实现
+func sm4ExpandKey(key []byte, tau func(uint32) uint32) (rk [sm4Rounds]uint32) {
+	var k [4]uint32
+	for i := range k {
+		k[i] = binary.BigEndian.Uint32(key[4*i:]) ^ sm4Fk[i]
+	}
+	for i := range rk {
+		// 固定参数CK的第j个字节为(4i+j)*7 mod 256
+		var ck uint32
+		for j := 0; j < 4; j++ {
+			ck = ck<<8 | uint32(byte((4*i+j)*7))
+		}
+		b := tau(k[(i+1)%4] ^ k[(i+2)%4] ^ k[(i+3)%4] ^ ck)
+		// 密钥扩展使用的线性变换L'(B) = B ^ (B <<< 13) ^ (B <<< 23)
+		k[i%4] ^= b ^ bits.RotateLeft32(b, 13) ^ bits.RotateLeft32(b, 23)
+		rk[i] = k[i%4]
+	}
+	return rk
+}
+
+// sm4ReverseKey 逆序的轮密钥，用于解密
+func sm4ReverseKey(rk [sm4Rounds]uint32) (drk [sm4Rounds]uint32) {
+	for i := range rk {
+		drk[i] = rk[sm4Rounds-1-i]
+	}
+	return drk
+}
+
+// sm4L 加解密使用的线性变换L(B) = B ^ (B <<< 2) ^ (B <<< 10) ^ (B <<< 18) ^ (B <<< 24)
+func sm4L(b uint32) uint32 {
+	return b ^ bits.RotateLeft32(b, 2) ^ bits.RotateLeft32(b, 10) ^ bits.RotateLeft32(b, 18) ^ bits.RotateLeft32(b, 24)
+}
+
+// newSm4Table 生成T表
+func newSm4Table() (table [4][256]uint32) {
+	for k := range table {
+		for i := range table[k] {
+			table[k][i] = sm4L(uint32(sm4Sbox[i]) << (24 - 8*k))
+		}
+	}
+	return table
+}
+
+// sm4TableTau 查表实现的非线性变换τ，即对每个字节做s盒替换
+func sm4TableTau(a uint32) uint32 {
+	return uint32(sm4Sbox[byte(a>>24)])<<24 | uint32(sm4Sbox[byte(a>>16)])<<16 |
+		uint32(sm4Sbox[byte(a>>8)])<<8 | uint32(sm4Sbox[byte(a)])
+}
+
+// sm4TableCrypt 使用T表处理一个分组，rk为加密或解密轮密钥
+func sm4TableCrypt(rk *[sm4Rounds]uint32, dst, src []byte) {
+	x0 := binary.BigEndian.Uint32(src[0:4])
+	x1 := binary.BigEndian.Uint32(src[4:8])
+	x2 := binary.BigEndian.Uint32(src[8:12])
+	x3 := binary.BigEndian.Uint32(src[12:16])
+	// T(a) = L(τ(a))，L为线性变换，因此可以拆分为4个字节分别查表后异或
+	for i := 0; i < sm4Rounds; i += 4 {
+		t := x1 ^ x2 ^ x3 ^ rk[i]
+		x0 ^= sm4Table[0][byte(t>>24)] ^ sm4Table[1][byte(t>>16)] ^ sm4Table[2][byte(t>>8)] ^ sm4Table[3][byte(t)]
+		t = x2 ^ x3 ^ x0 ^ rk[i+1]
+		x1 ^= sm4Table[0][byte(t>>24)] ^ sm4Table[1][byte(t>>16)] ^ sm4Table[2][byte(t>>8)] ^ sm4Table[3][byte(t)]
+		t = x3 ^ x0 ^ x1 ^ rk[i+2]
+		x2 ^= sm4Table[0][byte(t>>24)] ^ sm4Table[1][byte(t>>16)] ^ sm4Table[2][byte(t>>8)] ^ sm4Table[3][byte(t)]
+		t = x0 ^ x1 ^ x2 ^ rk[i+3]
+		x3 ^= sm4Table[0][byte(t>>24)] ^ sm4Table[1][byte(t>>16)] ^ sm4Table[2][byte(t>>8)] ^ sm4Table[3][byte(t)]
+	}
+	// 反序变换R
+	binary.BigEndian.PutUint32(dst[0:4], x3)
+	binary.BigEndian.PutUint32(dst[4:8], x2)
+	binary.BigEndian.PutUint32(dst[8:12], x1)
+	binary.BigEndian.PutUint32(dst[12:16], x0)
+}
+
+// sm4BitslicedTau 常量时间的非线性变换τ，4个字节分别位于比特切片的4个通道中
+func sm4BitslicedTau(a uint32) uint32 {
+	// 第i个比特切片的第8k位为第k个字节的第i位
+	var x [8]uint64
+	for i := range x {
+		x[i] = uint64(a>>i) & 0x01010101
+	}
+	sm4BitslicedSbox(&x)
+	var b uint32
+	for i := range x {
+		b |= uint32(x[i]&0x01010101) << i
+	}
+	return b
+}
+
+// sm4BitslicedCryptBlock 常量时间处理一个分组
+func sm4BitslicedCryptBlock(rk *[sm4Rounds]uint32, dst, src []byte) {
+	x0 := binary.BigEndian.Uint32(src[0:4])
+	x1 := binary.BigEndian.Uint32(src[4:8])
+	x2 := binary.BigEndian.Uint32(src[8:12])
+	x3 := binary.BigEndian.Uint32(src[12:16])
+	for i := 0; i < sm4Rounds; i += 4 {
+		x0 ^= sm4L(sm4BitslicedTau(x1 ^ x2 ^ x3 ^ rk[i]))
+		x1 ^= sm4L(sm4BitslicedTau(x2 ^ x3 ^ x0 ^ rk[i+1]))
+		x2 ^= sm4L(sm4BitslicedTau(x3 ^ x0 ^ x1 ^ rk[i+2]))
+		x3 ^= sm4L(sm4BitslicedTau(x0 ^ x1 ^ x2 ^ rk[i+3]))
+	}
+	binary.BigEndian.PutUint32(dst[0:4], x3)
+	binary.BigEndian.PutUint32(dst[4:8], x2)
+	binary.BigEndian.PutUint32(dst[8:12], x1)
+	binary.BigEndian.PutUint32(dst[12:16], x0)
+}
+
+// sm4BitslicedCryptBlocks 常量时间批量处理多个分组，每次处理64个分组，不足64个的部分补0后处理
+func sm4BitslicedCryptBlocks(rk *[sm4Rounds]uint32, dst, src []byte) {
+	var buf [sm4BitslicedLanes * sm4BlockSize]byte
+	for len(src) > 0 {
+		n := min(len(src), len(buf))
+		if n < len(buf) {
+			clear(buf[n:])
+		}
+		copy(buf[:], src[:n])
+		sm4BitslicedCryptLanes(rk, &buf)
+		copy(dst, buf[:n])
+		src, dst = src[n:], dst[n:]
+	}
+}
+
+// sm4BitslicedCryptLanes 比特切片处理64个分组
+func sm4BitslicedCryptLanes(rk *[sm4Rounds]uint32, data *[sm4BitslicedLanes * sm4BlockSize]byte) {
+	// 每个分组的前8字节与后8字节分别组成两个64×64的比特矩阵，转置后第i个字的第j位为第j个分组的第i位，
+	// 因此a[32:]与a[:32]分别为所有分组X0与X1的32个比特切片，b同理为X2与X3
+	var a, b [sm4BitslicedLanes]uint64
+	for j := range a {
+		a[j] = binary.BigEndian.Uint64(data[j*sm4BlockSize:])
+		b[j] = binary.BigEndian.Uint64(data[j*sm4BlockSize+8:])
+	}
+	sm4Transpose64(&a)
+	sm4Transpose64(&b)
+	x := [4][]uint64{a[32:], a[:32], b[32:], b[:32]}
+	var t [32]uint64
+	for i := 0; i < sm4Rounds; i++ {
+		x0, x1, x2, x3 := x[i%4], x[(i+1)%4], x[(i+2)%4], x[(i+3)%4]
+		for j := range t {
+			t[j] = x1[j] ^ x2[j] ^ x3[j] ^ -uint64(rk[i]>>j&1)
+		}
+		for k := 0; k < 4; k++ {
+			sm4BitslicedSbox((*[8]uint64)(t[8*k:]))
+		}
+		// 比特切片中循环左移n位即下标平移，第j位来自第(j-n) mod 32位
+		for j := range t {
+			x0[j] ^= t[j] ^ t[(j+30)&31] ^ t[(j+22)&31] ^ t[(j+14)&31] ^ t[(j+8)&31]
+		}
+	}
+	// 32轮之后x[3]、x[2]、x[1]、x[0]依次为输出的4个字，即a与b交换并各自交换高低32个切片
+	var c, d [sm4BitslicedLanes]uint64
+	copy(c[32:], b[:32])
+	copy(c[:32], b[32:])
+	copy(d[32:], a[:32])
+	copy(d[:32], a[32:])
+	sm4Transpose64(&c)
+	sm4Transpose64(&d)
+	for j := range c {
+		binary.BigEndian.PutUint64(data[j*sm4BlockSize:], c[j])
+		binary.BigEndian.PutUint64(data[j*sm4BlockSize+8:], d[j])
+	}
+}
+
+// sm4Transpose64 转置64×64的比特矩阵，m[i]的第j位（从最低位开始）与m[j]的第i位交换
+func sm4Transpose64(m *[64]uint64) {
+	mask := uint64(0x00000000ffffffff)
+	for width := 32; width != 0; width >>= 1 {
+		// 交换每个2width×2width子矩阵中右上与左下的width×width子矩阵
+		for k := 0; k < 64; k = (k | width + 1) &^ width {
+			t := (m[k]>>width ^ m[k|width]) & mask
+			m[k] ^= t << width
+			m[k|width] ^= t
+		}
+		mask ^= mask << (width >> 1)
+	}
+}
+
+// sm4BitslicedSbox 比特切片的s盒，x[i]为输入的第i位，结果写回x
+func sm4BitslicedSbox(x *[8]uint64) {
+	// 输入线性层：仿射变换A·x+C后同构映射到复合域，u[0:4]为低位al，u[4:8]为高位ah
+	u := [8]uint64{
+		^(x[4] ^ x[5] ^ x[6] ^ x[7]),
+		^(x[1] ^ x[4] ^ x[5] ^ x[6]),
+		^(x[1] ^ x[2] ^ x[4] ^ x[6] ^ x[7]),
+		^(x[3] ^ x[4]),
+		x[0] ^ x[1] ^ x[4] ^ x[7],
+		^x[6],
+		x[2] ^ x[6] ^ x[7],
+		^(x[0] ^ x[1] ^ x[2] ^ x[3] ^ x[4] ^ x[5] ^ x[6]),
+	}
+	al, ah := [4]uint64(u[:4]), [4]uint64(u[4:])
+	// (ah·Y + al)^-1 = (ah·Y + ah + al)·Δ^-1，Δ = λ·ah^2 + ah·al + al^2，其中λ·ah^2 + al^2为线性部分
+	delta := sm4Gf16Mul(ah, al)
+	delta[0] ^= u[0] ^ u[2] ^ u[4]
+	delta[1] ^= u[2] ^ u[5] ^ u[7]
+	delta[2] ^= u[1] ^ u[3] ^ u[7]
+	delta[3] ^= u[3] ^ u[4] ^ u[6]
+	inv := sm4Gf16Inv(delta)
+	vh := sm4Gf16Mul(ah, inv)
+	vl := sm4Gf16Mul([4]uint64{ah[0] ^ al[0], ah[1] ^ al[1], ah[2] ^ al[2], ah[3] ^ al[3]}, inv)
+	v := [8]uint64{vl[0], vl[1], vl[2], vl[3], vh[0], vh[1], vh[2], vh[3]}
+	// 输出线性层：同构逆映射后做仿射变换A·x+C
+	x[0] = ^(v[0] ^ v[1] ^ v[4] ^ v[5])
+	x[1] = ^(v[0] ^ v[2] ^ v[5] ^ v[6])
+	x[2] = v[2] ^ v[4]
+	x[3] = v[0] ^ v[2] ^ v[4] ^ v[5] ^ v[7]
+	x[4] = ^(v[1] ^ v[3] ^ v[7])
+	x[5] = v[1] ^ v[3] ^ v[5]
+	x[6] = ^(v[0] ^ v[1] ^ v[2])
+	x[7] = ^(v[0] ^ v[3] ^ v[5])
+}
+
+// sm4Gf16Mul 比特切片的GF(2^4)乘法，既约多项式为z^4+z+1
+func sm4Gf16Mul(a, b [4]uint64) [4]uint64 {
+	p0 := a[0] & b[0]
+	p1 := a[0]&b[1] ^ a[1]&b[0]
+	p2 := a[0]&b[2] ^ a[1]&b[1] ^ a[2]&b[0]
+	p3 := a[0]&b[3] ^ a[1]&b[2] ^ a[2]&b[1] ^ a[3]&b[0]
+	p4 := a[1]&b[3] ^ a[2]&b[2] ^ a[3]&b[1]
+	p5 := a[2]&b[3] ^ a[3]&b[2]
+	p6 := a[3] & b[3]
+	// z^4 = z+1，z^5 = z^2+z，z^6 = z^3+z^2
+	return [4]uint64{p0 ^ p4, p1 ^ p4 ^ p5, p2 ^ p5 ^ p6, p3 ^ p6}
+}
+
+// sm4Gf16Square 比特切片的GF(2^4)平方，为线性变换
+func sm4Gf16Square(a [4]uint64) [4]uint64 {
+	return [4]uint64{a[0] ^ a[2], a[2], a[1] ^ a[3], a[3]}
+}
+
+// sm4Gf16Inv 比特切片的GF(2^4)求逆，a^14 = a^-1，a为0时结果为0
+func sm4Gf16Inv(a [4]uint64) [4]uint64 {
+	a2 := sm4Gf16Square(a)
+	a3 := sm4Gf16Mul(a2, a)
+	a12 := sm4Gf16Square(sm4Gf16Square(a3))
+	return sm4Gf16Mul(a12, a2)
+}
@@ -0,0 +1,182 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"testing"
+
+	"github.com/tjfoc/gmsm/sm4"
+)
+
+// sm4TestImpls 待测试的sm4实现
+var sm4TestImpls = []struct {
+	name      string
+	newCipher func(key []byte) (cipher.Block, error)
+}{
+	{name: "table", newCipher: NewSm4Cipher},
+	{name: "bitsliced", newCipher: NewSm4BitslicedCipher},
+}
+
+func Test_Sm4Block(t *testing.T) {
+	// GB/T 32907-2016 附录A
+	key := mustHex("0123456789abcdeffedcba9876543210")
+	plaintext := mustHex("0123456789abcdeffedcba9876543210")
+	want := mustHex("681edf34d206965e86b3e94f536e4246")
+	for _, impl := range sm4TestImpls {
+		t.Run(impl.name, func(t *testing.T) {
+			block, err := impl.newCipher(key)
+			if err != nil {
+				t.Fatalf("newCipher() error = %v", err)
+			}
+			got := make([]byte, sm4BlockSize)
+			block.Encrypt(got, plaintext)
+			if !bytes.Equal(got, want) {
+				t.Fatalf("Encrypt() got = %x, want %x", got, want)
+			}
+			block.Decrypt(got, got)
+			if !bytes.Equal(got, plaintext) {
+				t.Fatalf("Decrypt() got = %x, want %x", got, plaintext)
+			}
+			if _, err := impl.newCipher(key[:15]); err == nil {
+				t.Fatal("newCipher() invalid key error = nil")
+			}
+		})
+	}
+}
+
+func Test_Sm4BlockMillion(t *testing.T) {
+	// GB/T 32907-2016 附录A：使用同一密钥加密1000000次
+	key := mustHex("0123456789abcdeffedcba9876543210")
+	want := mustHex("595298c7c6fd271f0402f804c33d3f66")
+	block, _ := NewSm4Cipher(key)
+	got := bytes.Clone(key)
+	for i := 0; i < 1000000; i++ {
+		block.Encrypt(got, got)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("Encrypt() got = %x, want %x", got, want)
+	}
+}
+
+func Test_Sm4BitslicedSbox(t *testing.T) {
+	for i := 0; i < 256; i++ {
+		a := uint32(i) * 0x01010101
+		if got, want := sm4BitslicedTau(a), sm4TableTau(a); got != want {
+			t.Fatalf("sm4BitslicedTau(%08x) = %08x, want %08x", a, got, want)
+		}
+	}
+}
+
+func Test_Sm4Transpose64(t *testing.T) {
+	var m [64]uint64
+	for i := range m {
+		m[i] = uint64(i)*0x9e3779b97f4a7c15 ^ uint64(i)<<7
+	}
+	transposed := m
+	sm4Transpose64(&transposed)
+	for i := range m {
+		for j := range m {
+			if transposed[j]>>i&1 != m[i]>>j&1 {
+				t.Fatalf("transposed[%d] bit %d != m[%d] bit %d", j, i, i, j)
+			}
+		}
+	}
+}
+
+func Test_Sm4BlockDifferential(t *testing.T) {
+	// 与tjfoc/gmsm的实现对比随机密钥与不同分组数的结果
+	for round := 0; round < 8; round++ {
+		key := make([]byte, sm4KeySize)
+		data := make([]byte, 130*sm4BlockSize)
+		_, _ = rand.Read(key)
+		_, _ = rand.Read(data)
+		reference, _ := sm4.NewCipher(key)
+		want := make([]byte, len(data))
+		for i := 0; i < len(data); i += sm4BlockSize {
+			reference.Encrypt(want[i:], data[i:i+sm4BlockSize])
+		}
+		for _, impl := range sm4TestImpls {
+			block, _ := impl.newCipher(key)
+			for _, blocks := range []int{1, 2, 63, 64, 65, 130} {
+				length := blocks * sm4BlockSize
+				got := make([]byte, length)
+				block.(MultiBlock).EncryptBlocks(got, data[:length])
+				if !bytes.Equal(got, want[:length]) {
+					t.Fatalf("%s EncryptBlocks() %d blocks got = %x, want %x", impl.name, blocks, got, want[:length])
+				}
+				block.(MultiBlock).DecryptBlocks(got, got)
+				if !bytes.Equal(got, data[:length]) {
+					t.Fatalf("%s DecryptBlocks() %d blocks got = %x", impl.name, blocks, got)
+				}
+			}
+			got := make([]byte, sm4BlockSize)
+			block.Encrypt(got, data)
+			if !bytes.Equal(got, want[:sm4BlockSize]) {
+				t.Fatalf("%s Encrypt() got = %x, want %x", impl.name, got, want[:sm4BlockSize])
+			}
+		}
+	}
+}
+
+func Test_Sm4BlockEcb(t *testing.T) {
+	// ecb通过MultiBlock使用批量接口，结果需要与逐个分组处理一致
+	key := mustHex("0123456789abcdeffedcba9876543210")
+	plaintext := bytes.Repeat(mustHex("0123456789abcdeffedcba9876543210"), 70)
+	want := bytes.Repeat(mustHex("681edf34d206965e86b3e94f536e4246"), 70)
+	for _, impl := range sm4TestImpls {
+		block, _ := impl.newCipher(key)
+		got, err := EcbEncrypt(block, plaintext)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Fatalf("%s EcbEncrypt() got = %x, err = %v", impl.name, got, err)
+		}
+		got, err = EcbDecrypt(block, got)
+		if err != nil || !bytes.Equal(got, plaintext) {
+			t.Fatalf("%s EcbDecrypt() got = %x, err = %v", impl.name, got, err)
+		}
+	}
+}
+
+func Test_Sm4BlockPanic(t *testing.T) {
+	for _, impl := range sm4TestImpls {
+		block, _ := impl.newCipher(make([]byte, sm4KeySize))
+		for name, f := range map[string]func(){
+			"short-src":  func() { block.Encrypt(make([]byte, 16), make([]byte, 15)) },
+			"short-dst":  func() { block.Decrypt(make([]byte, 15), make([]byte, 16)) },
+			"blocks-src": func() { block.(MultiBlock).EncryptBlocks(make([]byte, 32), make([]byte, 17)) },
+			"blocks-dst": func() { block.(MultiBlock).DecryptBlocks(make([]byte, 16), make([]byte, 32)) },
+		} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("%s %s did not panic", impl.name, name)
+					}
+				}()
+				f()
+			}()
+		}
+	}
+}
+
+// benchmarkSm4Block 使用单分组接口或批量接口加密
+func benchmarkSm4Block(b *testing.B, newCipher func(key []byte) (cipher.Block, error), batch bool) {
+	block, _ := newCipher(make([]byte, sm4KeySize))
+	data := make([]byte, 64*1024)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		if batch {
+			block.(MultiBlock).EncryptBlocks(data, data)
+			continue
+		}
+		for j := 0; j < len(data); j += sm4BlockSize {
+			block.Encrypt(data[j:], data[j:j+sm4BlockSize])
+		}
+	}
+}
+
+func Benchmark_Sm4Block(b *testing.B) {
+	b.Run("gmsm", func(b *testing.B) { benchmarkSm4Block(b, sm4.NewCipher, false) })
+	b.Run("table", func(b *testing.B) { benchmarkSm4Block(b, NewSm4Cipher, false) })
+	b.Run("bitsliced-single", func(b *testing.B) { benchmarkSm4Block(b, NewSm4BitslicedCipher, false) })
+	b.Run("bitsliced-blocks", func(b *testing.B) { benchmarkSm4Block(b, NewSm4BitslicedCipher, true) })
+}
@@ -0,0 +1,196 @@
+// Package crypto 数据库加密列类型
+package crypto
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+/*
+EncryptedString与EncryptedBytes实现了driver.Valuer与sql.Scanner接口，
+只需要将模型中的字段类型替换为这两种类型，写入数据库时即会自动加密，读取时自动解密，例如：
+type User struct {
+	Phone  crypto.EncryptedString // VARCHAR列，保存编码后的密文
+	IdCard crypto.EncryptedBytes  // BLOB/VARBINARY列，保存原始密文
+}
+EncryptedString与EncryptedBytes使用服务启动时通过SetSqlColumnConfig设置的全局加密配置。
+不同列需要使用不同的算法或密钥时（例如按租户区分密钥），使用以CipherConfigProvider为类型参数的
+EncryptedStringOf与EncryptedBytesOf，每种类型参数对应一份独立的加密配置：
+type User struct {
+	Phone  crypto.EncryptedStringOf[PhoneConfig]
+	IdCard crypto.EncryptedBytesOf[IdCardConfig]
+}
+空值不做加密，读取到NULL时为空值。
+与StructCrypter使用相同的密钥派生方式，因此同一配置下两者加密的数据可以互相解密。
+*/
+
+// sqlColumnConfig 数据库加密列使用的全局加密配置
+var sqlColumnConfig atomic.Pointer[CipherConfig]
+
+// SetSqlColumnConfig 设置EncryptedString与EncryptedBytes使用的全局加密配置
+// @param config 加密配置
+func SetSqlColumnConfig(config CipherConfig) error {
+	if err := config.validate(); err != nil {
+		return err
+	}
+	sqlColumnConfig.Store(&config)
+	return nil
+}
+
+// sqlColumnGlobalConfig 使用全局加密配置的CipherConfigProvider
+type sqlColumnGlobalConfig struct{}
+
+// CipherConfig 获取数据库加密列使用的全局加密配置
+func (sqlColumnGlobalConfig) CipherConfig() (*CipherConfig, error) {
+	config := sqlColumnConfig.Load()
+	if config == nil {
+		return nil, errors.New("sql column config is not set")
+	}
+	return config, nil
+}
+
+// EncryptedString 数据库中以编码后密文保存的字符串，使用全局加密配置
+type EncryptedString string
+
+// Value 实现driver.Valuer，返回编码后的密文
+func (s EncryptedString) Value() (driver.Value, error) {
+	return sqlColumnStringValue(sqlColumnGlobalConfig{}, string(s))
+}
+
+// Scan 实现sql.Scanner，解码并解密数据库中的密文
+func (s *EncryptedString) Scan(src any) error {
+	plaintext, err := sqlColumnStringScan(sqlColumnGlobalConfig{}, src)
+	*s = EncryptedString(plaintext)
+	return err
+}
+
+// EncryptedBytes 数据库中以原始密文保存的字节数组，使用全局加密配置
+type EncryptedBytes []byte
+
+// Value 实现driver.Valuer，返回原始密文
+func (b EncryptedBytes) Value() (driver.Value, error) {
+	return sqlColumnBytesValue(sqlColumnGlobalConfig{}, b)
+}
+
+// Scan 实现sql.Scanner，解密数据库中的密文
+func (b *EncryptedBytes) Scan(src any) error {
+	plaintext, err := sqlColumnBytesScan(sqlColumnGlobalConfig{}, src)
+	*b = plaintext
+	return err
+}
+
+// EncryptedStringOf 数据库中以编码后密文保存的字符串，使用类型参数P提供的加密配置
+type EncryptedStringOf[P CipherConfigProvider] string
+
+// Value 实现driver.Valuer，返回编码后的密文
+func (s EncryptedStringOf[P]) Value() (driver.Value, error) {
+	var provider P
+	return sqlColumnStringValue(provider, string(s))
+}
+
+// Scan 实现sql.Scanner，解码并解密数据库中的密文
+func (s *EncryptedStringOf[P]) Scan(src any) error {
+	var provider P
+	plaintext, err := sqlColumnStringScan(provider, src)
+	*s = EncryptedStringOf[P](plaintext)
+	return err
+}
+
+// EncryptedBytesOf 数据库中以原始密文保存的字节数组，使用类型参数P提供的加密配置
+type EncryptedBytesOf[P CipherConfigProvider] []byte
+
+// Value 实现driver.Valuer，返回原始密文
+func (b EncryptedBytesOf[P]) Value() (driver.Value, error) {
+	var provider P
+	return sqlColumnBytesValue(provider, b)
+}
+
+// Scan 实现sql.Scanner，解密数据库中的密文
+func (b *EncryptedBytesOf[P]) Scan(src any) error {
+	var provider P
+	plaintext, err := sqlColumnBytesScan(provider, src)
+	*b = plaintext
+	return err
+}
+
+// sqlColumnStringValue 加密并编码字符串列
+func sqlColumnStringValue(provider CipherConfigProvider, s string) (driver.Value, error) {
+	if s == "" {
+		return "", nil
+	}
+	config, err := resolveCipherConfig(provider)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := config.encryptToString([]byte(s))
+	if err != nil {
+		return nil, fmt.Errorf("encrypt column failed: %w", err)
+	}
+	return ciphertext, nil
+}
+
+// sqlColumnStringScan 解码并解密字符串列
+func sqlColumnStringScan(provider CipherConfigProvider, src any) (string, error) {
+	ciphertext, err := sqlColumnSource(src)
+	if err != nil || len(ciphertext) == 0 {
+		return "", err
+	}
+	config, err := resolveCipherConfig(provider)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := config.decryptString(string(ciphertext))
+	if err != nil {
+		return "", fmt.Errorf("decrypt column failed: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// sqlColumnBytesValue 加密字节数组列
+func sqlColumnBytesValue(provider CipherConfigProvider, b []byte) (driver.Value, error) {
+	if len(b) == 0 {
+		return []byte{}, nil
+	}
+	config, err := resolveCipherConfig(provider)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := config.encrypt(b)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt column failed: %w", err)
+	}
+	return ciphertext, nil
+}
+
+// sqlColumnBytesScan 解密字节数组列
+func sqlColumnBytesScan(provider CipherConfigProvider, src any) ([]byte, error) {
+	ciphertext, err := sqlColumnSource(src)
+	if err != nil || len(ciphertext) == 0 {
+		return nil, err
+	}
+	config, err := resolveCipherConfig(provider)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := config.decrypt(ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt column failed: %w", err)
+	}
+	return plaintext, nil
+}
+
+// sqlColumnSource 将数据库驱动返回的值转换为字节数组
+func sqlColumnSource(src any) ([]byte, error) {
+	switch v := src.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return nil, fmt.Errorf("unsupported column type: %T", src)
+	}
+}
@@ -0,0 +1,247 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+// memoryDriver 测试使用的内存数据库驱动，只支持两种语句：
+// INSERT：参数为(id, value)，保存value
+// SELECT：参数为(id)，返回保存的value
+type memoryDriver struct {
+	mu   sync.Mutex
+	rows map[int64]driver.Value
+}
+
+func (d *memoryDriver) Open(string) (driver.Conn, error) {
+	return &memoryConn{driver: d}, nil
+}
+
+type memoryConn struct {
+	driver *memoryDriver
+}
+
+func (c *memoryConn) Prepare(query string) (driver.Stmt, error) {
+	return &memoryStmt{conn: c, query: query}, nil
+}
+
+func (c *memoryConn) Close() error { return nil }
+
+func (c *memoryConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type memoryStmt struct {
+	conn  *memoryConn
+	query string
+}
+
+func (s *memoryStmt) Close() error { return nil }
+
+func (s *memoryStmt) NumInput() int { return -1 }
+
+func (s *memoryStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.conn.driver.mu.Lock()
+	defer s.conn.driver.mu.Unlock()
+	s.conn.driver.rows[args[0].(int64)] = args[1]
+	return driver.RowsAffected(1), nil
+}
+
+func (s *memoryStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.conn.driver.mu.Lock()
+	defer s.conn.driver.mu.Unlock()
+	value, ok := s.conn.driver.rows[args[0].(int64)]
+	return &memoryRows{value: value, done: !ok}, nil
+}
+
+type memoryRows struct {
+	value driver.Value
+	done  bool
+}
+
+func (r *memoryRows) Columns() []string { return []string{"value"} }
+
+func (r *memoryRows) Close() error { return nil }
+
+func (r *memoryRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	dest[0], r.done = r.value, true
+	return nil
+}
+
+// testMemoryDriver 测试使用的内存数据库驱动实例
+var testMemoryDriver = &memoryDriver{rows: map[int64]driver.Value{}}
+
+func init() {
+	sql.Register("tutils-memory", testMemoryDriver)
+}
+
+func Test_EncryptedColumn(t *testing.T) {
+	provider := StaticKeyProvider{"pii": []byte("0123456789abcdef")}
+	if err := SetSqlColumnConfig(CipherConfig{Cipher: CipherSm4Gcm, Provider: provider, KeyID: "pii"}); err != nil {
+		t.Fatalf("SetSqlColumnConfig() error = %v", err)
+	}
+	db, err := sql.Open("tutils-memory", "")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	phone := EncryptedString("13800138000")
+	if _, err = db.ExecContext(ctx, "INSERT", 1, phone); err != nil {
+		t.Fatalf("insert string error = %v", err)
+	}
+	stored, ok := testMemoryDriver.rows[1].(string)
+	if !ok || stored == string(phone) {
+		t.Errorf("stored string = %v, want ciphertext", testMemoryDriver.rows[1])
+	}
+	var gotPhone EncryptedString
+	if err = db.QueryRowContext(ctx, "SELECT", 1).Scan(&gotPhone); err != nil || gotPhone != phone {
+		t.Errorf("scan string got = %s, error = %v, want %s", gotPhone, err, phone)
+	}
+	// 与StructCrypter加密的数据可以互相解密
+	crypter, _ := NewStructCrypter(provider, "pii")
+	record := &struct {
+		Phone string `tcrypt:"sm4-gcm,base64"`
+	}{Phone: stored}
+	if err = crypter.DecryptStruct(record); err != nil || record.Phone != string(phone) {
+		t.Errorf("DecryptStruct() got = %s, error = %v, want %s", record.Phone, err, phone)
+	}
+
+	idCard := EncryptedBytes("110105194912310021")
+	if _, err = db.ExecContext(ctx, "INSERT", 2, idCard); err != nil {
+		t.Fatalf("insert bytes error = %v", err)
+	}
+	if storedBytes, ok := testMemoryDriver.rows[2].([]byte); !ok || bytes.Equal(storedBytes, idCard) {
+		t.Errorf("stored bytes = %v, want ciphertext", testMemoryDriver.rows[2])
+	}
+	var gotIdCard EncryptedBytes
+	if err = db.QueryRowContext(ctx, "SELECT", 2).Scan(&gotIdCard); err != nil || !bytes.Equal(gotIdCard, idCard) {
+		t.Errorf("scan bytes got = %s, error = %v, want %s", gotIdCard, err, idCard)
+	}
+
+	// NULL与空值
+	if _, err = db.ExecContext(ctx, "INSERT", 3, nil); err != nil {
+		t.Fatalf("insert null error = %v", err)
+	}
+	gotPhone, gotIdCard = "x", EncryptedBytes("x")
+	if err = db.QueryRowContext(ctx, "SELECT", 3).Scan(&gotPhone); err != nil || gotPhone != "" {
+		t.Errorf("scan null string got = %s, error = %v", gotPhone, err)
+	}
+	if err = db.QueryRowContext(ctx, "SELECT", 3).Scan(&gotIdCard); err != nil || gotIdCard != nil {
+		t.Errorf("scan null bytes got = %s, error = %v", gotIdCard, err)
+	}
+	if value, err := EncryptedString("").Value(); err != nil || value != "" {
+		t.Errorf("empty string Value() got = %v, error = %v", value, err)
+	}
+}
+
+func Test_EncryptedColumnError(t *testing.T) {
+	provider := StaticKeyProvider{"pii": []byte("0123456789abcdef")}
+	tests := []struct {
+		name   string
+		config CipherConfig
+	}{
+		{name: "provider", config: CipherConfig{Cipher: CipherSm4Gcm}},
+		{name: "cipher", config: CipherConfig{Cipher: "des-ecb", Provider: provider}},
+		{name: "encoding", config: CipherConfig{Cipher: CipherSm4Gcm, Encoding: "raw", Provider: provider}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetSqlColumnConfig(tt.config); err == nil {
+				t.Errorf("SetSqlColumnConfig() error = nil, wantErr true")
+			}
+		})
+	}
+	if err := SetSqlColumnConfig(CipherConfig{Cipher: CipherAesGcm, Encoding: "hex", Provider: provider, KeyID: "pii"}); err != nil {
+		t.Fatalf("SetSqlColumnConfig() error = %v", err)
+	}
+	var s EncryptedString
+	if err := s.Scan("not hex"); err == nil {
+		t.Errorf("Scan() with invalid encoding error = nil, wantErr true")
+	}
+	if err := s.Scan(int64(1)); err == nil {
+		t.Errorf("Scan() with unsupported type error = nil, wantErr true")
+	}
+	var b EncryptedBytes
+	if err := b.Scan(make([]byte, 40)); err == nil {
+		t.Errorf("Scan() with tampered ciphertext error = nil, wantErr true")
+	}
+	if err := SetSqlColumnConfig(CipherConfig{Cipher: CipherAesGcm, Provider: provider, KeyID: "missing"}); err != nil {
+		t.Fatalf("SetSqlColumnConfig() error = %v", err)
+	}
+	if _, err := EncryptedString("13800138000").Value(); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Value() error = %v, want %v", err, ErrKeyNotFound)
+	}
+}
+
+// testPhoneColumnConfig 测试使用的手机号列加密配置
+type testPhoneColumnConfig struct{}
+
+func (testPhoneColumnConfig) CipherConfig() (*CipherConfig, error) {
+	return &CipherConfig{Cipher: CipherSm4Gcm, Provider: StaticKeyProvider{"phone": []byte("0123456789abcdef")}, KeyID: "phone"}, nil
+}
+
+// testIdCardColumnConfig 测试使用的身份证列加密配置，与手机号列使用不同的算法与密钥
+type testIdCardColumnConfig struct{}
+
+func (testIdCardColumnConfig) CipherConfig() (*CipherConfig, error) {
+	return &CipherConfig{Cipher: CipherAesGcm, Encoding: "hex", Provider: StaticKeyProvider{"id": []byte("fedcba9876543210")}, KeyID: "id"}, nil
+}
+
+// testMissingColumnConfig 测试使用的获取失败的加密配置
+type testMissingColumnConfig struct{}
+
+func (testMissingColumnConfig) CipherConfig() (*CipherConfig, error) {
+	return nil, ErrKeyNotFound
+}
+
+func Test_EncryptedColumnOf(t *testing.T) {
+	phone := EncryptedStringOf[testPhoneColumnConfig]("13800138000")
+	idCard := EncryptedStringOf[testIdCardColumnConfig]("110105194912310021")
+	phoneValue, err := phone.Value()
+	if err != nil {
+		t.Fatalf("phone Value() error = %v", err)
+	}
+	idCardValue, err := idCard.Value()
+	if err != nil {
+		t.Fatalf("idCard Value() error = %v", err)
+	}
+
+	var gotPhone EncryptedStringOf[testPhoneColumnConfig]
+	if err = gotPhone.Scan(phoneValue); err != nil || gotPhone != phone {
+		t.Errorf("phone Scan() got = %s, error = %v, want %s", gotPhone, err, phone)
+	}
+	var gotIdCard EncryptedStringOf[testIdCardColumnConfig]
+	if err = gotIdCard.Scan(idCardValue); err != nil || gotIdCard != idCard {
+		t.Errorf("idCard Scan() got = %s, error = %v, want %s", gotIdCard, err, idCard)
+	}
+	// 使用其它列的配置无法解密
+	if err = gotPhone.Scan(idCardValue); err == nil {
+		t.Errorf("phone Scan() with idCard ciphertext error = nil, wantErr true")
+	}
+
+	data := EncryptedBytesOf[testIdCardColumnConfig]("secret")
+	value, err := data.Value()
+	if err != nil {
+		t.Fatalf("bytes Value() error = %v", err)
+	}
+	var gotData EncryptedBytesOf[testIdCardColumnConfig]
+	if err = gotData.Scan(value); err != nil || !bytes.Equal(gotData, data) {
+		t.Errorf("bytes Scan() got = %s, error = %v, want %s", gotData, err, data)
+	}
+	if err = gotData.Scan(nil); err != nil || gotData != nil {
+		t.Errorf("bytes Scan() null got = %s, error = %v", gotData, err)
+	}
+
+	if _, err = EncryptedStringOf[testMissingColumnConfig]("x").Value(); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Value() error = %v, want %v", err, ErrKeyNotFound)
+	}
+}
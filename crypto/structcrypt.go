@@ -0,0 +1,288 @@
+// Package crypto 基于struct tag的字段加密工具包
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+/*
+StructCrypter根据struct tag加解密结构体中的字段，常用于请求或数据库模型在持久化前加密敏感字段，tag格式为：
+tcrypt:"<算法>[,<编码>][,key=<密钥id>]"
+算法：cipher.go中的加密算法名称，例如sm4-gcm、aes-gcm、aes-siv。
+编码：密文的编码方式，支持encoding.go中的编码名称与raw（不编码），string字段默认为base64且不支持raw，[]byte字段默认为raw。
+密钥id：覆盖构造StructCrypter时指定的默认密钥id。
+例如：
+type User struct {
+	Phone  string `tcrypt:"sm4-gcm,base64"`
+	Email  string `tcrypt:"aes-siv,hex,key=email"`
+	IdCard []byte `tcrypt:"sm4-gcm"`
+}
+支持的字段类型为string、[]byte、[]string以及指向它们的指针，空值不做处理。
+未设置tag的字段会递归处理其中的结构体、指针、切片、数组与接口，map不做处理；
+接口中直接保存的结构体等值会复制后处理再写回，同一个指针只处理一次，因此循环引用与共享引用都是安全的。
+实际使用的密钥由KeyProvider中的主密钥通过DeriveKey派生（info为"tutils-tcrypt/"+算法名称），
+因此同一个主密钥可以用于不同长度密钥的算法，也与盲索引等其他用途的密钥互不相关。
+*/
+
+// structCryptTag struct tag名称
+const structCryptTag = "tcrypt"
+
+// structCryptInfoPrefix 字段加密密钥派生info前缀
+const structCryptInfoPrefix = "tutils-tcrypt/"
+
+// StructCrypter 结构体字段加解密器
+type StructCrypter struct {
+	provider KeyProvider // 密钥提供者
+	keyID    string      // 默认主密钥id
+}
+
+// NewStructCrypter 创建结构体字段加解密器
+// @param provider 密钥提供者
+// @param keyID 默认主密钥id，tag中未指定密钥id时使用
+func NewStructCrypter(provider KeyProvider, keyID string) (*StructCrypter, error) {
+	if provider == nil {
+		return nil, errors.New("key provider is nil")
+	}
+	return &StructCrypter{provider: provider, keyID: keyID}, nil
+}
+
+// EncryptStruct 原地加密结构体中设置了tcrypt tag的字段
+// @param v 结构体指针
+func (c *StructCrypter) EncryptStruct(v any) error {
+	return c.crypt(v, true)
+}
+
+// DecryptStruct 原地解密结构体中设置了tcrypt tag的字段
+// @param v 结构体指针
+func (c *StructCrypter) DecryptStruct(v any) error {
+	return c.crypt(v, false)
+}
+
+// crypt 加解密入口
+func (c *StructCrypter) crypt(v any, encrypt bool) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("v must be a non-nil pointer")
+	}
+	w := &structCryptWalker{crypter: c, encrypt: encrypt, keys: map[string][]byte{},
+		visited: map[structCryptVisited]struct{}{}}
+	return w.walk(rv, "")
+}
+
+// structCryptOptions 解析后的tag
+type structCryptOptions struct {
+	cipherName string // 加密算法
+	encoding   string // 编码方式，空表示使用字段类型的默认值
+	keyID      string // 主密钥id
+}
+
+// parseStructCryptTag 解析tag
+func parseStructCryptTag(tag, defaultKeyID string) (*structCryptOptions, error) {
+	parts := strings.Split(tag, ",")
+	opts := &structCryptOptions{cipherName: strings.ToLower(strings.TrimSpace(parts[0])), keyID: defaultKeyID}
+	if _, err := getCipherSuite(opts.cipherName); err != nil {
+		return nil, err
+	}
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		if keyID, ok := strings.CutPrefix(part, "key="); ok {
+			opts.keyID = keyID
+			continue
+		}
+		part = strings.ToLower(part)
+		if !isEncodingSupported(part) && part != "raw" {
+			return nil, fmt.Errorf("unsupported encoding: %s", part)
+		}
+		opts.encoding = part
+	}
+	return opts, nil
+}
+
+// structCryptWalker 单次加解密的遍历状态
+type structCryptWalker struct {
+	crypter *StructCrypter
+	encrypt bool                            // true加密，false解密
+	keys    map[string][]byte               // 已派生的密钥缓存，key为密钥id与算法名称
+	visited map[structCryptVisited]struct{} // 已处理的指针
+}
+
+// structCryptVisited 已处理的指针，同一地址可能同时是结构体与其第一个字段，因此需要同时记录类型
+type structCryptVisited struct {
+	pointer uintptr
+	typ     reflect.Type
+}
+
+// walk 递归遍历未设置tag的值
+func (w *structCryptWalker) walk(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		// 同一个指针只处理一次，既避免循环引用导致无限递归，也避免多处引用同一对象时被重复加密
+		visited := structCryptVisited{pointer: v.Pointer(), typ: v.Type()}
+		if _, ok := w.visited[visited]; ok {
+			return nil
+		}
+		w.visited[visited] = struct{}{}
+		return w.walk(v.Elem(), path)
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		elem := v.Elem()
+		if elem.Kind() == reflect.Pointer {
+			return w.walk(elem, path)
+		}
+		// 接口中直接保存的值不可寻址，复制后处理再写回接口
+		if !v.CanSet() {
+			return fmt.Errorf("field %s: value held by interface cannot be set", path)
+		}
+		copied := reflect.New(elem.Type()).Elem()
+		copied.Set(elem)
+		if err := w.walk(copied, path); err != nil {
+			return err
+		}
+		v.Set(copied)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldPath := field.Name
+			if path != "" {
+				fieldPath = path + "." + field.Name
+			}
+			tag, ok := field.Tag.Lookup(structCryptTag)
+			if !ok || tag == "-" {
+				if err := w.walk(v.Field(i), fieldPath); err != nil {
+					return err
+				}
+				continue
+			}
+			opts, err := parseStructCryptTag(tag, w.crypter.keyID)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", fieldPath, err)
+			}
+			if err = w.cryptField(v.Field(i), opts); err != nil {
+				return fmt.Errorf("field %s: %w", fieldPath, err)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := w.walk(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cryptField 加解密设置了tag的字段
+func (w *structCryptWalker) cryptField(v reflect.Value, opts *structCryptOptions) error {
+	if v.Kind() != reflect.Pointer && !v.CanSet() {
+		return errors.New("field cannot be set")
+	}
+	switch {
+	case v.Kind() == reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		// 与walk相同，多个字段指向同一个值时只处理一次
+		visited := structCryptVisited{pointer: v.Pointer(), typ: v.Type()}
+		if _, ok := w.visited[visited]; ok {
+			return nil
+		}
+		w.visited[visited] = struct{}{}
+		return w.cryptField(v.Elem(), opts)
+	case v.Kind() == reflect.String:
+		if v.Len() == 0 {
+			return nil
+		}
+		encoding := opts.encoding
+		if encoding == "" {
+			encoding = EncodingBase64
+		} else if encoding == "raw" {
+			return errors.New("raw encoding is not supported for string field")
+		}
+		out, err := w.cryptValue([]byte(v.String()), opts, encoding)
+		if err != nil {
+			return err
+		}
+		v.SetString(string(out))
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		if v.Len() == 0 {
+			return nil
+		}
+		encoding := opts.encoding
+		if encoding == "" {
+			encoding = "raw"
+		}
+		out, err := w.cryptValue(v.Bytes(), opts, encoding)
+		if err != nil {
+			return err
+		}
+		v.SetBytes(out)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		for i := 0; i < v.Len(); i++ {
+			if err := w.cryptField(v.Index(i), opts); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported field type: %s", v.Type())
+	}
+	return nil
+}
+
+// cryptValue 加密并编码，或解码并解密单个值
+func (w *structCryptWalker) cryptValue(src []byte, opts *structCryptOptions, encoding string) ([]byte, error) {
+	key, err := w.key(opts)
+	if err != nil {
+		return nil, err
+	}
+	encoded := encoding != "raw"
+	if w.encrypt {
+		ciphertext, err := Encrypt(opts.cipherName, key, src, nil)
+		if err != nil {
+			return nil, fmt.Errorf("encrypt failed: %w", err)
+		}
+		if encoded {
+			s, err := Encode(encoding, ciphertext)
+			return []byte(s), err
+		}
+		return ciphertext, nil
+	}
+	if encoded {
+		if src, err = Decode(encoding, string(src)); err != nil {
+			return nil, err
+		}
+	}
+	plaintext, err := Decrypt(opts.cipherName, key, src, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt failed: %w", err)
+	}
+	return plaintext, nil
+}
+
+// key 获取并派生字段使用的密钥
+func (w *structCryptWalker) key(opts *structCryptOptions) ([]byte, error) {
+	cacheKey := opts.keyID + "/" + opts.cipherName
+	if key, ok := w.keys[cacheKey]; ok {
+		return key, nil
+	}
+	key, err := deriveCipherKey(w.crypter.provider, opts.keyID, opts.cipherName)
+	if err != nil {
+		return nil, err
+	}
+	w.keys[cacheKey] = key
+	return key, nil
+}
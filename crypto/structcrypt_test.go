@@ -0,0 +1,204 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testStructAddress struct {
+	City   string
+	Detail string `tcrypt:"sm4-gcm"`
+}
+
+type testStructUser struct {
+	Name      string
+	Phone     string   `tcrypt:"sm4-gcm,base64"`
+	Email     string   `tcrypt:"aes-siv,hex,key=email"`
+	IdCard    []byte   `tcrypt:"chacha20-poly1305"`
+	Nickname  *string  `tcrypt:"aes-gcm,base64url"`
+	Tags      []string `tcrypt:"sm4-siv"`
+	Empty     string   `tcrypt:"sm4-gcm"`
+	Ignored   string   `tcrypt:"-"`
+	Address   testStructAddress
+	Addresses []*testStructAddress
+	Extra     any
+	secret    string
+}
+
+func newTestStructUser() *testStructUser {
+	nickname := "tyan"
+	return &testStructUser{
+		Name:      "tyanxie",
+		Phone:     "13800138000",
+		Email:     "foo@example.com",
+		IdCard:    []byte("110105194912310021"),
+		Nickname:  &nickname,
+		Tags:      []string{"vip", "beta"},
+		Ignored:   "ignored",
+		Address:   testStructAddress{City: "shenzhen", Detail: "nanshan"},
+		Addresses: []*testStructAddress{{City: "beijing", Detail: "haidian"}, nil},
+		Extra:     &testStructAddress{City: "shanghai", Detail: "pudong"},
+		secret:    "secret",
+	}
+}
+
+func Test_StructCrypter(t *testing.T) {
+	provider := StaticKeyProvider{
+		"pii":   []byte("0123456789abcdef0123456789abcdef"),
+		"email": []byte("fedcba9876543210"),
+	}
+	crypter, err := NewStructCrypter(provider, "pii")
+	if err != nil {
+		t.Fatalf("NewStructCrypter() error = %v", err)
+	}
+	user := newTestStructUser()
+	if err = crypter.EncryptStruct(user); err != nil {
+		t.Fatalf("EncryptStruct() error = %v", err)
+	}
+	want := newTestStructUser()
+	if user.Name != want.Name || user.Ignored != want.Ignored || user.secret != want.secret ||
+		user.Address.City != want.Address.City || user.Empty != "" || user.Addresses[1] != nil {
+		t.Errorf("EncryptStruct() changed untagged fields: %+v", user)
+	}
+	if user.Phone == want.Phone || *user.Nickname == *want.Nickname || bytes.Equal(user.IdCard, want.IdCard) ||
+		user.Tags[0] == want.Tags[0] || user.Address.Detail == want.Address.Detail ||
+		user.Addresses[0].Detail == want.Addresses[0].Detail || user.Extra.(*testStructAddress).Detail == "pudong" {
+		t.Errorf("EncryptStruct() left tagged fields unencrypted: %+v", user)
+	}
+	if _, err = hex.DecodeString(user.Email); err != nil {
+		t.Errorf("EncryptStruct() got email = %s, want hex", user.Email)
+	}
+	// 确定性加密的字段可以用于等值查询
+	again := newTestStructUser()
+	if err = crypter.EncryptStruct(again); err != nil {
+		t.Fatalf("EncryptStruct() error = %v", err)
+	}
+	if again.Email != user.Email || again.Tags[1] != user.Tags[1] || again.Phone == user.Phone {
+		t.Errorf("EncryptStruct() got unexpected determinism: %s %s", again.Email, again.Phone)
+	}
+	if err = crypter.DecryptStruct(user); err != nil {
+		t.Fatalf("DecryptStruct() error = %v", err)
+	}
+	if !reflect.DeepEqual(user, want) {
+		t.Errorf("DecryptStruct() got = %+v, want %+v", user, want)
+	}
+}
+
+type testStructNode struct {
+	Name     string `tcrypt:"sm4-gcm"`
+	Parent   *testStructNode
+	Children []*testStructNode
+}
+
+func Test_StructCrypterInterfaceAndCycle(t *testing.T) {
+	crypter, _ := NewStructCrypter(StaticKeyProvider{"pii": []byte("0123456789abcdef")}, "pii")
+	// 接口中直接保存结构体值
+	holder := &struct{ Any any }{Any: testStructAddress{City: "shenzhen", Detail: "nanshan"}}
+	if err := crypter.EncryptStruct(holder); err != nil {
+		t.Fatalf("EncryptStruct() with struct value in interface error = %v", err)
+	}
+	if got := holder.Any.(testStructAddress); got.Detail == "nanshan" || got.City != "shenzhen" {
+		t.Errorf("EncryptStruct() got = %+v, want detail encrypted", got)
+	}
+	if err := crypter.DecryptStruct(holder); err != nil {
+		t.Fatalf("DecryptStruct() error = %v", err)
+	}
+	if got := holder.Any.(testStructAddress); got.Detail != "nanshan" {
+		t.Errorf("DecryptStruct() got = %+v, want detail nanshan", got)
+	}
+	// 子节点通过Parent指回根节点形成循环，根节点同时被两处引用，只能加密一次
+	root := &testStructNode{Name: "root"}
+	child := &testStructNode{Name: "child", Parent: root}
+	root.Children = []*testStructNode{child, root}
+	if err := crypter.EncryptStruct(root); err != nil {
+		t.Fatalf("EncryptStruct() with cycle error = %v", err)
+	}
+	if root.Name == "root" || child.Name == "child" {
+		t.Errorf("EncryptStruct() left names unencrypted: %s %s", root.Name, child.Name)
+	}
+	if err := crypter.DecryptStruct(root); err != nil {
+		t.Fatalf("DecryptStruct() with cycle error = %v", err)
+	}
+	if root.Name != "root" || child.Name != "child" {
+		t.Errorf("DecryptStruct() got names %s %s, want root child", root.Name, child.Name)
+	}
+	// 两个tag不同的字段共用同一个指针，只按第一个字段加密一次
+	secret := "secret"
+	shared := &struct {
+		A *string `tcrypt:"sm4-gcm"`
+		B *string `tcrypt:"aes-gcm"`
+	}{A: &secret, B: &secret}
+	if err := crypter.EncryptStruct(shared); err != nil {
+		t.Fatalf("EncryptStruct() with shared pointer error = %v", err)
+	}
+	single := &struct {
+		A string `tcrypt:"sm4-gcm"`
+	}{A: secret}
+	if err := crypter.DecryptStruct(single); err != nil || single.A != "secret" {
+		t.Errorf("shared pointer encrypted more than once: got = %s, error = %v", single.A, err)
+	}
+	if err := crypter.DecryptStruct(shared); err != nil || secret != "secret" {
+		t.Errorf("DecryptStruct() with shared pointer got = %s, error = %v, want secret", secret, err)
+	}
+}
+
+func Test_StructCrypterError(t *testing.T) {
+	provider := StaticKeyProvider{"pii": []byte("0123456789abcdef")}
+	crypter, _ := NewStructCrypter(provider, "pii")
+	var unknownCipher struct {
+		Phone string `tcrypt:"des-ecb"`
+	}
+	unknownCipher.Phone = "13800138000"
+	var unknownEncoding struct {
+		Phone string `tcrypt:"sm4-gcm,base58x"`
+	}
+	unknownEncoding.Phone = "13800138000"
+	var rawString struct {
+		Phone string `tcrypt:"sm4-gcm,raw"`
+	}
+	rawString.Phone = "13800138000"
+	var unsupportedType struct {
+		Age int `tcrypt:"sm4-gcm"`
+	}
+	var missingKey struct {
+		Phone string `tcrypt:"sm4-gcm,key=missing"`
+	}
+	missingKey.Phone = "13800138000"
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{name: "nil", v: nil},
+		{name: "value", v: unknownCipher},
+		{name: "cipher", v: &unknownCipher},
+		{name: "encoding", v: &unknownEncoding},
+		{name: "raw", v: &rawString},
+		{name: "type", v: &unsupportedType},
+		{name: "key", v: &missingKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := crypter.EncryptStruct(tt.v); err == nil {
+				t.Errorf("EncryptStruct() error = nil, wantErr true")
+			}
+		})
+	}
+	if err := crypter.EncryptStruct(&missingKey); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("EncryptStruct() error = %v, want %v", err, ErrKeyNotFound)
+	}
+	// 解密被篡改的密文
+	user := &testStructAddress{Detail: "nanshan"}
+	_ = crypter.EncryptStruct(user)
+	tampered := []byte(user.Detail)
+	tampered[0] = map[bool]byte{true: 'B', false: 'A'}[tampered[0] == 'A']
+	user.Detail = string(tampered)
+	if err := crypter.DecryptStruct(user); err == nil {
+		t.Errorf("DecryptStruct() with tampered ciphertext error = nil, wantErr true")
+	}
+	if _, err := NewStructCrypter(nil, "pii"); err == nil {
+		t.Errorf("NewStructCrypter() with nil provider error = nil, wantErr true")
+	}
+}
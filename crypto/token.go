@@ -0,0 +1,241 @@
+// Package crypto 带有效期的加密或签名令牌工具包
+package crypto
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+/*
+令牌用于密码重置、下载链接、会话cookie等短期有效的不透明凭证，有两种类型：
+local:  使用aead（aes-gcm或sm4-gcm）加密，内容对持有者不可见，格式为t1.local.<kid>.BASE64URL(nonce||密文||标签)
+public: 使用Signer签名（sm2、ed25519等），内容对持有者可见但不可篡改，格式为t1.public.<kid>.BASE64URL(内容).BASE64URL(签名)
+其中base64url不带填充，"t1.<类型>.<kid>"作为附加认证数据或签名内容的一部分，因此无法修改kid或令牌类型。
+内容为：8字节签发时间（unix秒，大端） || 8字节过期时间（unix秒，大端） || 业务载荷。
+
+密钥轮换：签发时使用当前密钥id，校验时根据令牌中的kid选择密钥，因此轮换后旧密钥签发的令牌在过期前仍然可以校验，
+直到旧密钥从KeyProvider或verifiers中移除。local令牌的实际密钥由主密钥通过DeriveKey派生（info为"tutils-token/"+算法名称）。
+校验失败时返回的错误可以使用errors.Is区分：
+ErrMalformed: 格式错误，例如分段数量、编码不正确
+ErrTampered:  认证或验签失败，包括密钥id不存在
+ErrExpired:   已过期，或签发时间晚于当前时间（均已考虑时钟偏差）
+*/
+
+// 校验错误枚举，令牌、签名url、安全cookie与文件加密共用
+var (
+	// ErrMalformed 格式错误
+	ErrMalformed = errors.New("malformed")
+	// ErrTampered 被篡改或密钥不匹配
+	ErrTampered = errors.New("tampered")
+	// ErrExpired 不在有效期内
+	ErrExpired = errors.New("expired")
+)
+
+// 令牌类型
+const (
+	tokenVersion      = "t1"
+	tokenPurposeLocal = "local"
+	tokenPurposePub   = "public"
+	tokenInfoPrefix   = "tutils-token/"
+	tokenTimeLength   = 16
+)
+
+// TokenOptions 令牌配置
+type TokenOptions struct {
+	ClockSkew time.Duration    // 校验时允许的时钟偏差
+	Now       func() time.Time // 获取当前时间，为空时使用time.Now
+}
+
+// TokenInfo 令牌元数据
+type TokenInfo struct {
+	KeyID     string    // 密钥id
+	IssuedAt  time.Time // 签发时间
+	ExpiresAt time.Time // 过期时间
+}
+
+// Tokenizer 令牌签发与校验器
+type Tokenizer struct {
+	purpose string       // 令牌类型
+	keyID   string       // 签发使用的密钥id
+	options TokenOptions // 配置
+	// seal 加密或签名，prefix为"t1.<类型>.<kid>"，返回prefix之后的部分（不包含分隔的"."）
+	seal func(prefix string, body []byte) (string, error)
+	// open 解密或验签，返回内容
+	open func(prefix, kid, sealed string) ([]byte, error)
+}
+
+// NewAeadTokenizer 创建local令牌签发与校验器
+// @param cipherName 加密算法，CipherAesGcm或CipherSm4Gcm
+// @param provider 密钥提供者
+// @param keyID 签发使用的密钥id，不能包含"."
+// @param options 令牌配置
+func NewAeadTokenizer(cipherName string, provider KeyProvider, keyID string, options TokenOptions) (*Tokenizer, error) {
+	cipherName = strings.ToLower(cipherName)
+	if cipherName != CipherAesGcm && cipherName != CipherSm4Gcm {
+		return nil, fmt.Errorf("unsupported token cipher: %s", cipherName)
+	}
+	if provider == nil {
+		return nil, errors.New("key provider is nil")
+	}
+	if err := validateTokenKeyID(keyID); err != nil {
+		return nil, err
+	}
+	suite, _ := getCipherSuite(cipherName)
+	key := func(kid string) ([]byte, error) {
+		masterKey, err := provider.Key(kid)
+		if err != nil {
+			return nil, err
+		}
+		return DeriveKey(suite.hash, masterKey, tokenInfoPrefix+cipherName, suite.keySize)
+	}
+	t := &Tokenizer{purpose: tokenPurposeLocal, keyID: keyID, options: options}
+	t.seal = func(prefix string, body []byte) (string, error) {
+		k, err := key(keyID)
+		if err != nil {
+			return "", fmt.Errorf("get key failed: %w", err)
+		}
+		ciphertext, err := Encrypt(cipherName, k, body, []byte(prefix))
+		if err != nil {
+			return "", err
+		}
+		return base64.RawURLEncoding.EncodeToString(ciphertext), nil
+	}
+	t.open = func(prefix, kid, sealed string) ([]byte, error) {
+		ciphertext, err := base64.RawURLEncoding.DecodeString(sealed)
+		if err != nil {
+			return nil, fmt.Errorf("%w: decode token failed: %w", ErrMalformed, err)
+		}
+		k, err := key(kid)
+		if err != nil {
+			return nil, fmt.Errorf("%w: get key failed: %w", ErrTampered, err)
+		}
+		body, err := Decrypt(cipherName, k, ciphertext, []byte(prefix))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrTampered, err)
+		}
+		return body, nil
+	}
+	return t, nil
+}
+
+// NewSignedTokenizer 创建public令牌签发与校验器
+// @param signer 签发使用的签名器，为空时只能校验令牌
+// @param keyID 签发使用的密钥id，不能包含"."
+// @param verifiers 校验使用的验签器，key为密钥id，需要包含signer对应的验签器
+// @param options 令牌配置
+func NewSignedTokenizer(signer Signer, keyID string, verifiers map[string]Verifier,
+	options TokenOptions) (*Tokenizer, error) {
+	if err := validateTokenKeyID(keyID); err != nil {
+		return nil, err
+	}
+	t := &Tokenizer{purpose: tokenPurposePub, keyID: keyID, options: options}
+	t.seal = func(prefix string, body []byte) (string, error) {
+		if signer == nil {
+			return "", errors.New("signer is nil")
+		}
+		encodedBody := base64.RawURLEncoding.EncodeToString(body)
+		signature, err := signer.Sign([]byte(prefix + "." + encodedBody))
+		if err != nil {
+			return "", err
+		}
+		return encodedBody + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+	}
+	t.open = func(prefix, kid, sealed string) ([]byte, error) {
+		encodedBody, encodedSignature, ok := strings.Cut(sealed, ".")
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid token format", ErrMalformed)
+		}
+		body, err := base64.RawURLEncoding.DecodeString(encodedBody)
+		if err != nil {
+			return nil, fmt.Errorf("%w: decode token failed: %w", ErrMalformed, err)
+		}
+		signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+		if err != nil {
+			return nil, fmt.Errorf("%w: decode signature failed: %w", ErrMalformed, err)
+		}
+		verifier, ok := verifiers[kid]
+		if !ok {
+			return nil, fmt.Errorf("%w: %w: %s", ErrTampered, ErrKeyNotFound, kid)
+		}
+		if err = verifier.Verify([]byte(prefix+"."+encodedBody), signature); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrTampered, err)
+		}
+		return body, nil
+	}
+	return t, nil
+}
+
+// Issue 签发令牌
+// @param payload 业务载荷
+// @param ttl 有效期
+func (t *Tokenizer) Issue(payload []byte, ttl time.Duration) (string, error) {
+	if ttl <= 0 {
+		return "", errors.New("ttl must be positive")
+	}
+	now := t.now()
+	body := make([]byte, tokenTimeLength, tokenTimeLength+len(payload))
+	binary.BigEndian.PutUint64(body, uint64(now.Unix()))
+	binary.BigEndian.PutUint64(body[8:], uint64(now.Add(ttl).Unix()))
+	body = append(body, payload...)
+	prefix := t.prefix(t.keyID)
+	sealed, err := t.seal(prefix, body)
+	if err != nil {
+		return "", fmt.Errorf("seal token failed: %w", err)
+	}
+	return prefix + "." + sealed, nil
+}
+
+// Parse 校验令牌并返回业务载荷与元数据
+// @param token 令牌
+func (t *Tokenizer) Parse(token string) ([]byte, *TokenInfo, error) {
+	parts := strings.SplitN(token, ".", 4)
+	if len(parts) != 4 || parts[0] != tokenVersion || parts[1] != t.purpose {
+		return nil, nil, fmt.Errorf("%w: invalid token format", ErrMalformed)
+	}
+	kid := parts[2]
+	body, err := t.open(t.prefix(kid), kid, parts[3])
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(body) < tokenTimeLength {
+		return nil, nil, fmt.Errorf("%w: token body too short", ErrMalformed)
+	}
+	info := &TokenInfo{
+		KeyID:     kid,
+		IssuedAt:  time.Unix(int64(binary.BigEndian.Uint64(body)), 0),
+		ExpiresAt: time.Unix(int64(binary.BigEndian.Uint64(body[8:])), 0),
+	}
+	now := t.now()
+	if now.Add(t.options.ClockSkew).Before(info.IssuedAt) {
+		return nil, nil, fmt.Errorf("%w: token issued in the future", ErrExpired)
+	}
+	if !now.Add(-t.options.ClockSkew).Before(info.ExpiresAt) {
+		return nil, nil, fmt.Errorf("%w: token expired at %s", ErrExpired, info.ExpiresAt.Format(time.RFC3339))
+	}
+	return body[tokenTimeLength:], info, nil
+}
+
+// prefix 令牌前缀
+func (t *Tokenizer) prefix(kid string) string {
+	return tokenVersion + "." + t.purpose + "." + kid
+}
+
+// now 获取当前时间
+func (t *Tokenizer) now() time.Time {
+	if t.options.Now != nil {
+		return t.options.Now()
+	}
+	return time.Now()
+}
+
+// validateTokenKeyID 校验密钥id
+func validateTokenKeyID(keyID string) error {
+	if strings.Contains(keyID, ".") {
+		return errors.New("key id must not contain '.'")
+	}
+	return nil
+}
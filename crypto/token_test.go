@@ -0,0 +1,159 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testClock 测试使用的可调整时钟
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+// tamperTail 修改base64url字符串倒数第二个字符，保证与原字符串不同且仍然可以解码
+func tamperTail(s string) string {
+	c := byte('A')
+	if s[len(s)-2] == c {
+		c = 'B'
+	}
+	return s[:len(s)-2] + string(c) + s[len(s)-1:]
+}
+
+func Test_AeadTokenizer(t *testing.T) {
+	provider := StaticKeyProvider{"k1": []byte("0123456789abcdef"), "k2": []byte("fedcba9876543210")}
+	for _, cipherName := range []string{CipherAesGcm, CipherSm4Gcm} {
+		t.Run(cipherName, func(t *testing.T) {
+			clock := &testClock{now: time.Unix(1700000000, 0)}
+			options := TokenOptions{ClockSkew: 30 * time.Second, Now: clock.Now}
+			old, err := NewAeadTokenizer(cipherName, provider, "k1", options)
+			if err != nil {
+				t.Fatalf("NewAeadTokenizer() error = %v", err)
+			}
+			token, err := old.Issue([]byte("user:1"), time.Minute)
+			if err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+			if !strings.HasPrefix(token, "t1.local.k1.") || strings.Contains(token, "user") {
+				t.Errorf("Issue() got = %s", token)
+			}
+			// 轮换后旧密钥签发的令牌仍然可以校验
+			rotated, _ := NewAeadTokenizer(cipherName, provider, "k2", options)
+			payload, info, err := rotated.Parse(token)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if string(payload) != "user:1" || info.KeyID != "k1" || !info.IssuedAt.Equal(clock.now) ||
+				!info.ExpiresAt.Equal(clock.now.Add(time.Minute)) {
+				t.Errorf("Parse() got = %s, %+v", payload, info)
+			}
+			clock.now = clock.now.Add(time.Minute + 20*time.Second)
+			if _, _, err = rotated.Parse(token); err != nil {
+				t.Errorf("Parse() within clock skew error = %v", err)
+			}
+			clock.now = clock.now.Add(20 * time.Second)
+			if _, _, err = rotated.Parse(token); !errors.Is(err, ErrExpired) {
+				t.Errorf("Parse() expired error = %v, want %v", err, ErrExpired)
+			}
+		})
+	}
+}
+
+func Test_SignedTokenizer(t *testing.T) {
+	_, ed25519Key, _ := ed25519.GenerateKey(rand.Reader)
+	ed25519Signer, _ := NewSigner(ed25519Key)
+	sm2Signer, _ := NewSigner(privateKey)
+	verifiers := map[string]Verifier{"ed": ed25519Signer.Verifier(), "sm2": sm2Signer.Verifier()}
+	tests := []struct {
+		name   string
+		signer Signer
+		keyID  string
+	}{
+		{name: "ed25519", signer: ed25519Signer, keyID: "ed"},
+		{name: "sm2", signer: sm2Signer, keyID: "sm2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer, err := NewSignedTokenizer(tt.signer, tt.keyID, verifiers, TokenOptions{})
+			if err != nil {
+				t.Fatalf("NewSignedTokenizer() error = %v", err)
+			}
+			token, err := issuer.Issue([]byte("download:42"), time.Hour)
+			if err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+			verifierOnly, _ := NewSignedTokenizer(nil, "", verifiers, TokenOptions{})
+			payload, info, err := verifierOnly.Parse(token)
+			if err != nil || string(payload) != "download:42" || info.KeyID != tt.keyID {
+				t.Errorf("Parse() got = %s, %+v, error = %v", payload, info, err)
+			}
+			if _, err = verifierOnly.Issue(nil, time.Hour); err == nil {
+				t.Errorf("Issue() without signer error = nil, wantErr true")
+			}
+		})
+	}
+}
+
+func Test_TokenizerError(t *testing.T) {
+	provider := StaticKeyProvider{"k1": []byte("0123456789abcdef"), "k2": []byte("fedcba9876543210")}
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	tokenizer, _ := NewAeadTokenizer(CipherSm4Gcm, provider, "k1", TokenOptions{ClockSkew: time.Second, Now: clock.Now})
+	token, _ := tokenizer.Issue([]byte("user:1"), time.Minute)
+	parts := strings.Split(token, ".")
+	_, ed25519Key, _ := ed25519.GenerateKey(rand.Reader)
+	signer, _ := NewSigner(ed25519Key)
+	signed, _ := NewSignedTokenizer(signer, "ed", map[string]Verifier{"ed": signer.Verifier()}, TokenOptions{})
+	signedToken, _ := signed.Issue([]byte("user:1"), time.Minute)
+	signedParts := strings.Split(signedToken, ".")
+	tests := []struct {
+		name      string
+		tokenizer *Tokenizer
+		token     string
+		want      error
+	}{
+		{name: "format", tokenizer: tokenizer, token: "t1.local.k1", want: ErrMalformed},
+		{name: "version", tokenizer: tokenizer, token: "t2" + token[2:], want: ErrMalformed},
+		{name: "purpose", tokenizer: signed, token: token, want: ErrMalformed},
+		{name: "base64", tokenizer: tokenizer, token: token + "!", want: ErrMalformed},
+		// 修改kid后附加认证数据不一致
+		{name: "kid", tokenizer: tokenizer, token: "t1.local.k2." + parts[3], want: ErrTampered},
+		{name: "unknown-kid", tokenizer: tokenizer, token: "t1.local.k3." + parts[3], want: ErrTampered},
+		{name: "ciphertext", tokenizer: tokenizer, token: tamperTail(token), want: ErrTampered},
+		{name: "signed-format", tokenizer: signed, token: strings.Join(signedParts[:4], "."), want: ErrMalformed},
+		{name: "signed-body", tokenizer: signed, token: strings.Join(signedParts[:3], ".") + ".AAAA." + signedParts[4],
+			want: ErrTampered},
+		{name: "signed-kid", tokenizer: signed, token: "t1.public.ed2." + strings.Join(signedParts[3:], "."),
+			want: ErrTampered},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tt.tokenizer.Parse(tt.token); !errors.Is(err, tt.want) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	// 签发时间晚于当前时间
+	clock.now = clock.now.Add(-time.Minute)
+	if _, _, err := tokenizer.Parse(token); !errors.Is(err, ErrExpired) {
+		t.Errorf("Parse() issued in the future error = %v, want %v", err, ErrExpired)
+	}
+	if _, err := tokenizer.Issue(nil, 0); err == nil {
+		t.Errorf("Issue() with zero ttl error = nil, wantErr true")
+	}
+	if _, err := NewAeadTokenizer(CipherChaCha20Poly1305, provider, "k1", TokenOptions{}); err == nil {
+		t.Errorf("NewAeadTokenizer() with unsupported cipher error = nil, wantErr true")
+	}
+	if _, err := NewAeadTokenizer(CipherAesGcm, provider, "k.1", TokenOptions{}); err == nil {
+		t.Errorf("NewAeadTokenizer() with invalid key id error = nil, wantErr true")
+	}
+	if _, err := NewAeadTokenizer(CipherAesGcm, nil, "k1", TokenOptions{}); err == nil {
+		t.Errorf("NewAeadTokenizer() with nil provider error = nil, wantErr true")
+	}
+}
@@ -0,0 +1,189 @@
+// Package crypto xts磁盘加密模式工具包
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+/*
+XTS是用于磁盘、块存储等固定长度扇区加密的可调分组密码模式，密文与明文等长，不需要填充也不需要额外保存iv：
+密钥为两个等长的分组密码密钥拼接，前半部分K1用于加密数据，后半部分K2用于加密调整值（tweak）。
+调整值的初值为K2加密后的16字节扇区号（小端），每个分组使用后在GF(2^128)上乘以α得到下一个分组的调整值，
+每个分组的密文为 E_K1(P ⊕ T) ⊕ T。
+数据长度不是16字节的整数倍时使用密文挪用（ciphertext stealing）：
+最后一个不完整分组借用前一个分组密文的末尾字节补齐后加密，因此数据至少需要16字节。
+两种标准的区别只在于乘以α时的比特序：
+ieee1619: IEEE 1619，调整值按小端解释，左移后溢出时异或0x87，AES-XTS通常使用该标准
+gb17964:  GB/T 17964-2021，调整值按大端解释、比特反序（与gcm相同），右移后溢出时异或0xe1
+XTS不提供完整性校验，同一扇区重复写入时相同位置相同明文的密文也相同，只应当用于无法扩展数据长度的存储加密场景。
+*/
+
+// XTS标准枚举
+const (
+	// XtsStandardIeee IEEE 1619
+	XtsStandardIeee = "ieee1619"
+	// XtsStandardGb GB/T 17964-2021
+	XtsStandardGb = "gb17964"
+)
+
+// xtsBlockSize xts要求的分组长度
+const xtsBlockSize = 16
+
+// xtsMulMap 不同标准的调整值乘以α的实现
+var xtsMulMap = map[string]func(tweak *[xtsBlockSize]byte){
+	XtsStandardIeee: xtsMulIeee,
+	XtsStandardGb:   xtsMulGb,
+}
+
+// AesXtsEncrypt xts模式的aes加密
+// @param key 32、48或64字节密钥，分别对应AES-128、AES-192与AES-256
+// @param sector 扇区号
+// @param plaintext 明文内容，至少16字节
+// @param standard 标准，XtsStandardIeee或XtsStandardGb
+func AesXtsEncrypt(key []byte, sector uint64, plaintext []byte, standard string) ([]byte, error) {
+	return xtsCrypt(aes.NewCipher, key, xtsSectorTweak(sector), plaintext, standard, true)
+}
+
+// AesXtsDecrypt xts模式的aes解密
+// @param key 32、48或64字节密钥
+// @param sector 扇区号
+// @param ciphertext 密文
+// @param standard 标准，需要与加密时一致
+func AesXtsDecrypt(key []byte, sector uint64, ciphertext []byte, standard string) ([]byte, error) {
+	return xtsCrypt(aes.NewCipher, key, xtsSectorTweak(sector), ciphertext, standard, false)
+}
+
+// Sm4XtsEncrypt xts模式的sm4加密
+// @param key 32字节密钥
+// @param sector 扇区号
+// @param plaintext 明文内容，至少16字节
+// @param standard 标准，XtsStandardIeee或XtsStandardGb
+func Sm4XtsEncrypt(key []byte, sector uint64, plaintext []byte, standard string) ([]byte, error) {
+	return xtsCrypt(NewSm4Cipher, key, xtsSectorTweak(sector), plaintext, standard, true)
+}
+
+// Sm4XtsDecrypt xts模式的sm4解密
+// @param key 32字节密钥
+// @param sector 扇区号
+// @param ciphertext 密文
+// @param standard 标准，需要与加密时一致
+func Sm4XtsDecrypt(key []byte, sector uint64, ciphertext []byte, standard string) ([]byte, error) {
+	return xtsCrypt(NewSm4Cipher, key, xtsSectorTweak(sector), ciphertext, standard, false)
+}
+
+// xtsSectorTweak 扇区号对应的16字节调整值
+func xtsSectorTweak(sector uint64) [xtsBlockSize]byte {
+	var tweak [xtsBlockSize]byte
+	binary.LittleEndian.PutUint64(tweak[:], sector)
+	return tweak
+}
+
+// xtsCrypt xts加密或解密
+func xtsCrypt(newCipher func(key []byte) (cipher.Block, error), key []byte, tweak [xtsBlockSize]byte,
+	src []byte, standard string, encrypt bool) ([]byte, error) {
+	k1, mul, err := xtsInit(newCipher, key, &tweak, len(src), standard)
+	if err != nil {
+		return nil, err
+	}
+	dst := make([]byte, len(src))
+	xtsBlocks(k1, mul, tweak, dst, src, encrypt)
+	return dst, nil
+}
+
+// xtsInit 校验参数，返回数据密钥对应的分组密码与乘以α的实现，并使用调整值密钥加密初始调整值
+func xtsInit(newCipher func(key []byte) (cipher.Block, error), key []byte, tweak *[xtsBlockSize]byte,
+	length int, standard string) (cipher.Block, func(tweak *[xtsBlockSize]byte), error) {
+	mul, ok := xtsMulMap[strings.ToLower(standard)]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported xts standard: %s", standard)
+	}
+	if len(key)%2 != 0 {
+		return nil, nil, fmt.Errorf("invalid key length: %d", len(key))
+	}
+	if length < xtsBlockSize {
+		return nil, nil, errors.New("xts data must be at least one block")
+	}
+	k1, err := newCipher(key[:len(key)/2])
+	if err != nil {
+		return nil, nil, fmt.Errorf("create block failed: %w", err)
+	}
+	k2, err := newCipher(key[len(key)/2:])
+	if err != nil {
+		return nil, nil, fmt.Errorf("create block failed: %w", err)
+	}
+	if k1.BlockSize() != xtsBlockSize {
+		return nil, nil, errors.New("xts requires a 16-byte block cipher")
+	}
+	k2.Encrypt(tweak[:], tweak[:])
+	return k1, mul, nil
+}
+
+// xtsBlocks 从调整值tweak开始处理src，src末尾不完整的分组使用密文挪用，因此src不足16字节整数倍时至少需要16字节
+func xtsBlocks(k1 cipher.Block, mul func(tweak *[xtsBlockSize]byte), tweak [xtsBlockSize]byte,
+	dst, src []byte, encrypt bool) {
+	crypt := k1.Decrypt
+	if encrypt {
+		crypt = k1.Encrypt
+	}
+	// block 使用调整值处理一个分组
+	block := func(dst, src []byte, t *[xtsBlockSize]byte) {
+		subtle.XORBytes(dst, src, t[:])
+		crypt(dst, dst)
+		subtle.XORBytes(dst, dst, t[:])
+	}
+	remain := len(src) % xtsBlockSize
+	// 存在不完整分组时，最后两个分组需要做密文挪用
+	full := len(src) - remain
+	if remain > 0 {
+		full -= xtsBlockSize
+	}
+	for i := 0; i < full; i += xtsBlockSize {
+		block(dst[i:], src[i:i+xtsBlockSize], &tweak)
+		mul(&tweak)
+	}
+	if remain == 0 {
+		return
+	}
+	// 加密时倒数第二个分组使用当前调整值，最后的不完整分组使用下一个调整值；
+	// 解密时顺序相反，需要先用下一个调整值解密倒数第二个分组
+	next := tweak
+	mul(&next)
+	first, second := &tweak, &next
+	if !encrypt {
+		first, second = &next, &tweak
+	}
+	var cc [xtsBlockSize]byte
+	block(cc[:], src[full:full+xtsBlockSize], first)
+	copy(dst[full+xtsBlockSize:], cc[:remain])
+	// 不完整分组借用cc的末尾字节补齐
+	copy(cc[:], src[full+xtsBlockSize:])
+	block(dst[full:full+xtsBlockSize], cc[:], second)
+}
+
+// xtsMulIeee IEEE 1619中调整值乘以α，调整值为小端的128位整数，既约多项式为x^128+x^7+x^2+x+1
+func xtsMulIeee(tweak *[xtsBlockSize]byte) {
+	var carry byte
+	for i := range tweak {
+		next := tweak[i] >> 7
+		tweak[i] = tweak[i]<<1 | carry
+		carry = next
+	}
+	tweak[0] ^= 0x87 & -carry
+}
+
+// xtsMulGb GB/T 17964中调整值乘以α，比特序与gcm相同，右移后溢出时异或0xe1
+func xtsMulGb(tweak *[xtsBlockSize]byte) {
+	var carry byte
+	for i := range tweak {
+		next := tweak[i] & 1
+		tweak[i] = tweak[i]>>1 | carry<<7
+		carry = next
+	}
+	tweak[0] ^= 0xe1 & -carry
+}
@@ -0,0 +1,181 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_XtsEncrypt(t *testing.T) {
+	tests := []struct {
+		name       string
+		encrypt    func(key []byte, sector uint64, plaintext []byte, standard string) ([]byte, error)
+		decrypt    func(key []byte, sector uint64, ciphertext []byte, standard string) ([]byte, error)
+		key        []byte
+		sector     uint64
+		plaintext  []byte
+		ciphertext []byte
+	}{
+		// IEEE 1619 附录B与GB/T 17964中使用小端扇区号调整值的示例
+		{
+			name:       "aes-vector1",
+			encrypt:    AesXtsEncrypt,
+			decrypt:    AesXtsDecrypt,
+			key:        make([]byte, 32),
+			sector:     0,
+			plaintext:  make([]byte, 32),
+			ciphertext: mustHex("917cf69ebd68b2ec9b9fe9a3eadda692cd43d2f59598ed858c02c2652fbf922e"),
+		},
+		{
+			name:       "aes-vector2",
+			encrypt:    AesXtsEncrypt,
+			decrypt:    AesXtsDecrypt,
+			key:        mustHex("1111111111111111111111111111111122222222222222222222222222222222"),
+			sector:     0x3333333333,
+			plaintext:  bytes.Repeat([]byte{0x44}, 32),
+			ciphertext: mustHex("c454185e6a16936e39334038acef838bfb186fff7480adc4289382ecd6d394f0"),
+		},
+		{
+			name:       "sm4-zero",
+			encrypt:    Sm4XtsEncrypt,
+			decrypt:    Sm4XtsDecrypt,
+			key:        make([]byte, 32),
+			sector:     0,
+			plaintext:  make([]byte, 32),
+			ciphertext: mustHex("d9b421f731c894fdc35b77291fe4e3b02a1fb76698d59f0e51376c4ada5bc75d"),
+		},
+		{
+			name:       "sm4-sector",
+			encrypt:    Sm4XtsEncrypt,
+			decrypt:    Sm4XtsDecrypt,
+			key:        mustHex("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f022222222222222222222222222222222"),
+			sector:     0x3333333333,
+			plaintext:  bytes.Repeat([]byte{0x44}, 32),
+			ciphertext: mustHex("7f76088effadf70c02ea9f95da0628d351bfcb9eac0563bcf17b710dab0a9826"),
+		},
+		{
+			name:       "sm4-stealing",
+			encrypt:    Sm4XtsEncrypt,
+			decrypt:    Sm4XtsDecrypt,
+			key:        mustHex("c46acc2e7e013cb71cdbf750cf76b000249fbf4fb6cd17607773c23ffa2c4330"),
+			sector:     94,
+			plaintext:  mustHex("7e9c2289cba460e470222953439cdaa892a5433d4dab2a3f67"),
+			ciphertext: mustHex("c3cf5445c64aa518f4abce2848faddfb4605d9fb66f1f12c0c"),
+		},
+		{
+			name:       "sm4-stealing2",
+			encrypt:    Sm4XtsEncrypt,
+			decrypt:    Sm4XtsDecrypt,
+			key:        mustHex("56ffcc9bbbdf413f0fc0f888f44b7493bb1925a39b8adf02d9009bb16db0a887"),
+			sector:     144,
+			plaintext:  mustHex("9a839cc14363bafcfc0cc93b14f8e769d35b94cc98267438e3"),
+			ciphertext: mustHex("af027012c829206c32a31706999d046f10a83bcacbc5c96353"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ciphertext, err := tt.encrypt(tt.key, tt.sector, tt.plaintext, XtsStandardIeee)
+			if err != nil {
+				t.Fatalf("encrypt error: %v", err)
+			}
+			if !bytes.Equal(ciphertext, tt.ciphertext) {
+				t.Fatalf("encrypt = %x, want %x", ciphertext, tt.ciphertext)
+			}
+			plaintext, err := tt.decrypt(tt.key, tt.sector, ciphertext, XtsStandardIeee)
+			if err != nil {
+				t.Fatalf("decrypt error: %v", err)
+			}
+			if !bytes.Equal(plaintext, tt.plaintext) {
+				t.Fatalf("decrypt = %x, want %x", plaintext, tt.plaintext)
+			}
+		})
+	}
+}
+
+func Test_XtsGb(t *testing.T) {
+	// GB/T 17964-2021 附录中SM4-XTS的示例，调整值直接给出而不是由扇区号生成
+	key := mustHex("2b7e151628aed2a6abf7158809cf4f3c000102030405060708090a0b0c0d0e0f")
+	var tweak [xtsBlockSize]byte
+	copy(tweak[:], mustHex("f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff"))
+	plaintext := mustHex("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17")
+	want := mustHex("e9538251c71d7b80bbe4483fef497bd12c5c581bd6242fc51e08964fb4f60fdb0ba42f63499279213d318d2c11f6886e903be7f93a1b3479")
+	ciphertext, err := xtsCrypt(NewSm4Cipher, key, tweak, plaintext, XtsStandardGb, true)
+	if err != nil {
+		t.Fatalf("encrypt error: %v", err)
+	}
+	if !bytes.Equal(ciphertext, want) {
+		t.Fatalf("encrypt = %x, want %x", ciphertext, want)
+	}
+	decrypted, err := xtsCrypt(NewSm4Cipher, key, tweak, ciphertext, XtsStandardGb, false)
+	if err != nil {
+		t.Fatalf("decrypt error: %v", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Fatalf("decrypt = %x, want %x", decrypted, plaintext)
+	}
+}
+
+func Test_XtsRoundTrip(t *testing.T) {
+	key := bytes.Repeat([]byte{0x5a}, 32)
+	key[31] = 0xa5
+	for _, standard := range []string{XtsStandardIeee, XtsStandardGb} {
+		for _, length := range []int{16, 17, 31, 32, 33, 100, 512, 4096} {
+			plaintext := make([]byte, length)
+			for i := range plaintext {
+				plaintext[i] = byte(i)
+			}
+			for name, f := range map[string][2]func([]byte, uint64, []byte, string) ([]byte, error){
+				"aes": {AesXtsEncrypt, AesXtsDecrypt},
+				"sm4": {Sm4XtsEncrypt, Sm4XtsDecrypt},
+			} {
+				ciphertext, err := f[0](key, 7, plaintext, standard)
+				if err != nil {
+					t.Fatalf("%s %s %d encrypt error: %v", name, standard, length, err)
+				}
+				if len(ciphertext) != length || bytes.Equal(ciphertext, plaintext) {
+					t.Fatalf("%s %s %d ciphertext = %x", name, standard, length, ciphertext)
+				}
+				other, _ := f[0](key, 8, plaintext, standard)
+				if bytes.Equal(ciphertext, other) {
+					t.Fatalf("%s %s %d ciphertext does not depend on sector", name, standard, length)
+				}
+				decrypted, err := f[1](key, 7, ciphertext, standard)
+				if err != nil {
+					t.Fatalf("%s %s %d decrypt error: %v", name, standard, length, err)
+				}
+				if !bytes.Equal(decrypted, plaintext) {
+					t.Fatalf("%s %s %d decrypt = %x", name, standard, length, decrypted)
+				}
+			}
+		}
+	}
+	// 两种标准只有第一个分组相同
+	ieee, _ := Sm4XtsEncrypt(key, 1, make([]byte, 32), XtsStandardIeee)
+	gb, _ := Sm4XtsEncrypt(key, 1, make([]byte, 32), XtsStandardGb)
+	if !bytes.Equal(ieee[:16], gb[:16]) || bytes.Equal(ieee[16:], gb[16:]) {
+		t.Fatalf("ieee = %x, gb = %x", ieee, gb)
+	}
+}
+
+func Test_XtsError(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      []byte
+		data     []byte
+		standard string
+	}{
+		{name: "standard", key: make([]byte, 32), data: make([]byte, 16), standard: "xex"},
+		{name: "odd-key", key: make([]byte, 33), data: make([]byte, 16), standard: XtsStandardIeee},
+		{name: "short-key", key: make([]byte, 16), data: make([]byte, 16), standard: XtsStandardIeee},
+		{name: "short-data", key: make([]byte, 32), data: make([]byte, 15), standard: XtsStandardGb},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Sm4XtsEncrypt(tt.key, 0, tt.data, tt.standard); err == nil {
+				t.Fatal("Sm4XtsEncrypt error = nil")
+			}
+			if _, err := AesXtsDecrypt(tt.key, 0, tt.data, tt.standard); err == nil {
+				t.Fatal("AesXtsDecrypt error = nil")
+			}
+		})
+	}
+}
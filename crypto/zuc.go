@@ -0,0 +1,369 @@
+// Package crypto zuc祖冲之序列密码工具包
+package crypto
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+/*
+ZUC（祖冲之序列密码，GB/T 33133）是除sm4之外的另一种国密对称算法，主要用于4G/5G等移动通信：
+ZUC-128: 16字节密钥，16字节iv
+ZUC-256: 32字节密钥，25字节iv，其中前17字节为8位，后8字节只使用低6位（需要小于64）
+两者每次输出一个32位密钥字，按大端序转换为密钥流字节后与明文异或，加密与解密为同一个操作。
+128-EEA3（机密性算法）与128-EIA3（完整性算法）为3GPP标准中基于ZUC-128的算法，
+使用count、bearer（5位）、direction（1位）构造iv，消息长度均以位为单位：
+128-EEA3输出ceil(bitLength/8)字节，最后一个字节中超出bitLength的位置0；128-EIA3的mac为4字节。
+与其它序列密码相同，同一个密钥下iv（或count、bearer、direction的组合）不能重复使用。
+*/
+
+// 祖冲之算法常量
+const (
+	zuc128KeySize = 16
+	zuc128IvSize  = 16
+	zuc256KeySize = 32
+	zuc256IvSize  = 25
+	zucEia3MacLen = 4
+)
+
+// zucD ZUC-128的常量D
+var zucD = [16]uint32{
+	0x44d7, 0x26bc, 0x626b, 0x135e, 0x5789, 0x35e2, 0x7135, 0x09af,
+	0x4d78, 0x2f13, 0x6bc4, 0x1af1, 0x5e26, 0x3c4d, 0x789a, 0x47ac,
+}
+
+// zuc256D ZUC-256密钥流生成使用的常量D
+var zuc256D = [16]uint32{
+	0x22, 0x2f, 0x24, 0x2a, 0x6d, 0x40, 0x40, 0x40,
+	0x40, 0x40, 0x40, 0x40, 0x40, 0x52, 0x10, 0x30,
+}
+
+// zucS0 S盒S0
+var zucS0 = [256]byte{
+	0x3e, 0x72, 0x5b, 0x47, 0xca, 0xe0, 0x00, 0x33, 0x04, 0xd1, 0x54, 0x98, 0x09, 0xb9, 0x6d, 0xcb,
+	0x7b, 0x1b, 0xf9, 0x32, 0xaf, 0x9d, 0x6a, 0xa5, 0xb8, 0x2d, 0xfc, 0x1d, 0x08, 0x53, 0x03, 0x90,
+	0x4d, 0x4e, 0x84, 0x99, 0xe4, 0xce, 0xd9, 0x91, 0xdd, 0xb6, 0x85, 0x48, 0x8b, 0x29, 0x6e, 0xac,
+	0xcd, 0xc1, 0xf8, 0x1e, 0x73, 0x43, 0x69, 0xc6, 0xb5, 0xbd, 0xfd, 0x39, 0x63, 0x20, 0xd4, 0x38,
+	0x76, 0x7d, 0xb2, 0xa7, 0xcf, 0xed, 0x57, 0xc5, 0xf3, 0x2c, 0xbb, 0x14, 0x21, 0x06, 0x55, 0x9b,
+	0xe3, 0xef, 0x5e, 0x31, 0x4f, 0x7f, 0x5a, 0xa4, 0x0d, 0x82, 0x51, 0x49, 0x5f, 0xba, 0x58, 0x1c,
+	0x4a, 0x16, 0xd5, 0x17, 0xa8, 0x92, 0x24, 0x1f, 0x8c, 0xff, 0xd8, 0xae, 0x2e, 0x01, 0xd3, 0xad,
+	0x3b, 0x4b, 0xda, 0x46, 0xeb, 0xc9, 0xde, 0x9a, 0x8f, 0x87, 0xd7, 0x3a, 0x80, 0x6f, 0x2f, 0xc8,
+	0xb1, 0xb4, 0x37, 0xf7, 0x0a, 0x22, 0x13, 0x28, 0x7c, 0xcc, 0x3c, 0x89, 0xc7, 0xc3, 0x96, 0x56,
+	0x07, 0xbf, 0x7e, 0xf0, 0x0b, 0x2b, 0x97, 0x52, 0x35, 0x41, 0x79, 0x61, 0xa6, 0x4c, 0x10, 0xfe,
+	0xbc, 0x26, 0x95, 0x88, 0x8a, 0xb0, 0xa3, 0xfb, 0xc0, 0x18, 0x94, 0xf2, 0xe1, 0xe5, 0xe9, 0x5d,
+	0xd0, 0xdc, 0x11, 0x66, 0x64, 0x5c, 0xec, 0x59, 0x42, 0x75, 0x12, 0xf5, 0x74, 0x9c, 0xaa, 0x23,
+	0x0e, 0x86, 0xab, 0xbe, 0x2a, 0x02, 0xe7, 0x67, 0xe6, 0x44, 0xa2, 0x6c, 0xc2, 0x93, 0x9f, 0xf1,
+	0xf6, 0xfa, 0x36, 0xd2, 0x50, 0x68, 0x9e, 0x62, 0x71, 0x15, 0x3d, 0xd6, 0x40, 0xc4, 0xe2, 0x0f,
+	0x8e, 0x83, 0x77, 0x6b, 0x25, 0x05, 0x3f, 0x0c, 0x30, 0xea, 0x70, 0xb7, 0xa1, 0xe8, 0xa9, 0x65,
+	0x8d, 0x27, 0x1a, 0xdb, 0x81, 0xb3, 0xa0, 0xf4, 0x45, 0x7a, 0x19, 0xdf, 0xee, 0x78, 0x34, 0x60,
+}
+
+// zucS1 S盒S1
+var zucS1 = [256]byte{
+	0x55, 0xc2, 0x63, 0x71, 0x3b, 0xc8, 0x47, 0x86, 0x9f, 0x3c, 0xda, 0x5b, 0x29, 0xaa, 0xfd, 0x77,
+	0x8c, 0xc5, 0x94, 0x0c, 0xa6, 0x1a, 0x13, 0x00, 0xe3, 0xa8, 0x16, 0x72, 0x40, 0xf9, 0xf8, 0x42,
+	0x44, 0x26, 0x68, 0x96, 0x81, 0xd9, 0x45, 0x3e, 0x10, 0x76, 0xc6, 0xa7, 0x8b, 0x39, 0x43, 0xe1,
+	0x3a, 0xb5, 0x56, 0x2a, 0xc0, 0x6d, 0xb3, 0x05, 0x22, 0x66, 0xbf, 0xdc, 0x0b, 0xfa, 0x62, 0x48,
+	0xdd, 0x20, 0x11, 0x06, 0x36, 0xc9, 0xc1, 0xcf, 0xf6, 0x27, 0x52, 0xbb, 0x69, 0xf5, 0xd4, 0x87,
+	0x7f, 0x84, 0x4c, 0xd2, 0x9c, 0x57, 0xa4, 0xbc, 0x4f, 0x9a, 0xdf, 0xfe, 0xd6, 0x8d, 0x7a, 0xeb,
+	0x2b, 0x53, 0xd8, 0x5c, 0xa1, 0x14, 0x17, 0xfb, 0x23, 0xd5, 0x7d, 0x30, 0x67, 0x73, 0x08, 0x09,
+	0xee, 0xb7, 0x70, 0x3f, 0x61, 0xb2, 0x19, 0x8e, 0x4e, 0xe5, 0x4b, 0x93, 0x8f, 0x5d, 0xdb, 0xa9,
+	0xad, 0xf1, 0xae, 0x2e, 0xcb, 0x0d, 0xfc, 0xf4, 0x2d, 0x46, 0x6e, 0x1d, 0x97, 0xe8, 0xd1, 0xe9,
+	0x4d, 0x37, 0xa5, 0x75, 0x5e, 0x83, 0x9e, 0xab, 0x82, 0x9d, 0xb9, 0x1c, 0xe0, 0xcd, 0x49, 0x89,
+	0x01, 0xb6, 0xbd, 0x58, 0x24, 0xa2, 0x5f, 0x38, 0x78, 0x99, 0x15, 0x90, 0x50, 0xb8, 0x95, 0xe4,
+	0xd0, 0x91, 0xc7, 0xce, 0xed, 0x0f, 0xb4, 0x6f, 0xa0, 0xcc, 0xf0, 0x02, 0x4a, 0x79, 0xc3, 0xde,
+	0xa3, 0xef, 0xea, 0x51, 0xe6, 0x6b, 0x18, 0xec, 0x1b, 0x2c, 0x80, 0xf7, 0x74, 0xe7, 0xff, 0x21,
+	0x5a, 0x6a, 0x54, 0x1e, 0x41, 0x31, 0x92, 0x35, 0xc4, 0x33, 0x07, 0x0a, 0xba, 0x7e, 0x0e, 0x34,
+	0x88, 0xb1, 0x98, 0x7c, 0xf3, 0x3d, 0x60, 0x6c, 0x7b, 0xca, 0xd3, 0x1f, 0x32, 0x65, 0x04, 0x28,
+	0x64, 0xbe, 0x85, 0x9b, 0x2f, 0x59, 0x8a, 0xd7, 0xb0, 0x25, 0xac, 0xaf, 0x12, 0x03, 0xe2, 0xf2,
+}
+
+// zucState ZUC算法状态
+type zucState struct {
+	lfsr   [16]uint32 // 线性反馈移位寄存器，每个单元31位
+	r1, r2 uint32     // 非线性函数F的记忆单元
+	x      [4]uint32  // 比特重组的输出
+	buf    [4]byte    // 未使用完的密钥流字节
+	bufLen int        // buf中剩余的字节数
+}
+
+// NewZucCipher 创建祖冲之序列密码，密钥与iv的长度决定使用ZUC-128或ZUC-256
+// @param key 16字节（ZUC-128）或32字节（ZUC-256）密钥
+// @param iv 16字节（ZUC-128）或25字节（ZUC-256）iv
+func NewZucCipher(key, iv []byte) (cipher.Stream, error) {
+	return newZucState(key, iv)
+}
+
+// ZucEncrypt 祖冲之序列密码加密
+// @param key 16字节（ZUC-128）或32字节（ZUC-256）密钥
+// @param iv 16字节（ZUC-128）或25字节（ZUC-256）iv
+// @param plaintext 明文内容
+func ZucEncrypt(key, iv, plaintext []byte) ([]byte, error) {
+	s, err := newZucState(key, iv)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := make([]byte, len(plaintext))
+	s.XORKeyStream(ciphertext, plaintext)
+	return ciphertext, nil
+}
+
+// ZucDecrypt 祖冲之序列密码解密
+// @param key 密钥
+// @param iv iv
+// @param ciphertext 密文
+func ZucDecrypt(key, iv, ciphertext []byte) ([]byte, error) {
+	return ZucEncrypt(key, iv, ciphertext)
+}
+
+// ZucKeystream 生成指定数量的32位密钥字
+// @param key 16字节（ZUC-128）或32字节（ZUC-256）密钥
+// @param iv 16字节（ZUC-128）或25字节（ZUC-256）iv
+// @param n 密钥字数量
+func ZucKeystream(key, iv []byte, n int) ([]uint32, error) {
+	s, err := newZucState(key, iv)
+	if err != nil {
+		return nil, err
+	}
+	words := make([]uint32, n)
+	for i := range words {
+		words[i] = s.keyword()
+	}
+	return words, nil
+}
+
+// Zuc128Eea3Encrypt 128-EEA3加密
+// @param key 16字节密钥
+// @param count 计数器
+// @param bearer 承载层标识，5位
+// @param direction 传输方向，1位
+// @param plaintext 明文内容
+// @param bitLength 明文的位数，通常为len(plaintext)*8
+func Zuc128Eea3Encrypt(key []byte, count uint32, bearer, direction byte, plaintext []byte,
+	bitLength int) ([]byte, error) {
+	if bearer > 0x1f || direction > 1 {
+		return nil, errors.New("invalid bearer or direction")
+	}
+	if bitLength < 0 || bitLength > len(plaintext)*8 {
+		return nil, fmt.Errorf("invalid bit length: %d", bitLength)
+	}
+	iv := make([]byte, zuc128IvSize)
+	binary.BigEndian.PutUint32(iv, count)
+	iv[4] = bearer<<3 | direction<<2
+	copy(iv[8:], iv[:8])
+	ciphertext, err := ZucEncrypt(key, iv, plaintext[:(bitLength+7)/8])
+	if err != nil {
+		return nil, err
+	}
+	// 清除最后一个字节中超出bitLength的位
+	if remain := bitLength % 8; remain != 0 {
+		ciphertext[len(ciphertext)-1] &= byte(0xff << (8 - remain))
+	}
+	return ciphertext, nil
+}
+
+// Zuc128Eea3Decrypt 128-EEA3解密
+// @param key 16字节密钥
+// @param count 计数器
+// @param bearer 承载层标识，5位
+// @param direction 传输方向，1位
+// @param ciphertext 密文
+// @param bitLength 密文的位数，通常为len(ciphertext)*8
+func Zuc128Eea3Decrypt(key []byte, count uint32, bearer, direction byte, ciphertext []byte,
+	bitLength int) ([]byte, error) {
+	return Zuc128Eea3Encrypt(key, count, bearer, direction, ciphertext, bitLength)
+}
+
+// Zuc128Eia3 计算128-EIA3消息认证码
+// @param key 16字节密钥
+// @param count 计数器
+// @param bearer 承载层标识，5位
+// @param direction 传输方向，1位
+// @param message 消息
+// @param bitLength 消息的位数，通常为len(message)*8
+func Zuc128Eia3(key []byte, count uint32, bearer, direction byte, message []byte, bitLength int) ([]byte, error) {
+	if bearer > 0x1f || direction > 1 {
+		return nil, errors.New("invalid bearer or direction")
+	}
+	if bitLength < 0 || bitLength > len(message)*8 {
+		return nil, fmt.Errorf("invalid bit length: %d", bitLength)
+	}
+	if len(key) != zuc128KeySize {
+		return nil, fmt.Errorf("invalid key length: %d", len(key))
+	}
+	iv := make([]byte, zuc128IvSize)
+	binary.BigEndian.PutUint32(iv, count)
+	iv[4] = bearer << 3
+	copy(iv[8:], iv[:8])
+	iv[8] ^= direction << 7
+	iv[14] ^= direction << 7
+	s, err := newZucState(key, iv)
+	if err != nil {
+		return nil, err
+	}
+	// 需要ceil(bitLength/32)+2个密钥字，word(i)为从密钥流第i位开始的32位
+	z := make([]uint32, (bitLength+31)/32+2)
+	for i := range z {
+		z[i] = s.keyword()
+	}
+	word := func(i int) uint32 {
+		j, shift := i/32, i%32
+		if shift == 0 {
+			return z[j]
+		}
+		return z[j]<<shift | z[j+1]>>(32-shift)
+	}
+	t := uint32(0)
+	for i := 0; i < bitLength; i++ {
+		bit := uint32(message[i/8]>>(7-i%8)) & 1
+		t ^= word(i) & -bit
+	}
+	t ^= word(bitLength) ^ z[len(z)-1]
+	return binary.BigEndian.AppendUint32(nil, t), nil
+}
+
+// Zuc128Eia3Verify 校验128-EIA3消息认证码
+// @param key 16字节密钥
+// @param count 计数器
+// @param bearer 承载层标识，5位
+// @param direction 传输方向，1位
+// @param message 消息
+// @param bitLength 消息的位数
+// @param mac 4字节消息认证码
+func Zuc128Eia3Verify(key []byte, count uint32, bearer, direction byte, message []byte, bitLength int,
+	mac []byte) error {
+	expected, err := Zuc128Eia3(key, count, bearer, direction, message, bitLength)
+	if err != nil {
+		return err
+	}
+	if len(mac) != zucEia3MacLen || subtle.ConstantTimeCompare(expected, mac) != 1 {
+		return ErrMacMismatch
+	}
+	return nil
+}
+
+// newZucState 加载密钥与iv并完成初始化
+func newZucState(key, iv []byte) (*zucState, error) {
+	s := &zucState{}
+	switch {
+	case len(key) == zuc128KeySize && len(iv) == zuc128IvSize:
+		for i := range s.lfsr {
+			s.lfsr[i] = uint32(key[i])<<23 | zucD[i]<<8 | uint32(iv[i])
+		}
+	case len(key) == zuc256KeySize && len(iv) == zuc256IvSize:
+		for _, b := range iv[17:] {
+			if b > 0x3f {
+				return nil, errors.New("the last 8 bytes of zuc-256 iv must be 6-bit")
+			}
+		}
+		s.loadKeyIv256(key, iv)
+	default:
+		return nil, fmt.Errorf("invalid key length %d or iv length %d", len(key), len(iv))
+	}
+	// 初始化阶段32轮，非线性函数的输出右移1位后参与lfsr反馈
+	for i := 0; i < 32; i++ {
+		s.bitReorganization()
+		s.lfsrInit(s.f() >> 1)
+	}
+	// 工作阶段第一轮的输出丢弃
+	s.bitReorganization()
+	s.f()
+	s.lfsrInit(0)
+	return s, nil
+}
+
+// loadKeyIv256 ZUC-256加载密钥与iv，每个单元为 8位 || 7位d常量 || 8位 || 8位
+func (s *zucState) loadKeyIv256(key, iv []byte) {
+	k, v, d := func(i int) uint32 { return uint32(key[i]) }, func(i int) uint32 { return uint32(iv[i]) }, zuc256D
+	cell := func(a, b, c, e uint32) uint32 { return a<<23 | b<<16 | c<<8 | e }
+	s.lfsr[0] = cell(k(0), d[0], k(21), k(16))
+	s.lfsr[1] = cell(k(1), d[1], k(22), k(17))
+	s.lfsr[2] = cell(k(2), d[2], k(23), k(18))
+	s.lfsr[3] = cell(k(3), d[3], k(24), k(19))
+	s.lfsr[4] = cell(k(4), d[4], k(25), k(20))
+	s.lfsr[5] = cell(v(0), d[5]|v(17), k(5), k(26))
+	s.lfsr[6] = cell(v(1), d[6]|v(18), k(6), k(27))
+	s.lfsr[7] = cell(v(10), d[7]|v(19), k(7), v(2))
+	s.lfsr[8] = cell(k(8), d[8]|v(20), v(3), v(11))
+	s.lfsr[9] = cell(k(9), d[9]|v(21), v(12), v(4))
+	s.lfsr[10] = cell(v(5), d[10]|v(22), k(10), k(28))
+	s.lfsr[11] = cell(k(11), d[11]|v(23), v(6), v(13))
+	s.lfsr[12] = cell(k(12), d[12]|v(24), v(7), v(14))
+	s.lfsr[13] = cell(k(13), d[13], v(15), v(8))
+	s.lfsr[14] = cell(k(14), d[14]|k(31)>>4, v(16), v(9))
+	s.lfsr[15] = cell(k(15), d[15]|k(31)&0x0f, k(30), k(29))
+}
+
+// bitReorganization 比特重组
+func (s *zucState) bitReorganization() {
+	s.x[0] = s.lfsr[15]&0x7fff8000<<1 | s.lfsr[14]&0xffff
+	s.x[1] = s.lfsr[11]&0xffff<<16 | s.lfsr[9]>>15
+	s.x[2] = s.lfsr[7]&0xffff<<16 | s.lfsr[5]>>15
+	s.x[3] = s.lfsr[2]&0xffff<<16 | s.lfsr[0]>>15
+}
+
+// f 非线性函数F
+func (s *zucState) f() uint32 {
+	w := (s.x[0] ^ s.r1) + s.r2
+	w1 := s.r1 + s.x[1]
+	w2 := s.r2 ^ s.x[2]
+	s.r1 = zucSbox(zucL1(w1<<16 | w2>>16))
+	s.r2 = zucSbox(zucL2(w2<<16 | w1>>16))
+	return w
+}
+
+// lfsrInit lfsr反馈，工作阶段u为0，计算在模2^31-1的域上进行
+func (s *zucState) lfsrInit(u uint32) {
+	v := uint64(s.lfsr[15])<<15 + uint64(s.lfsr[13])<<17 + uint64(s.lfsr[10])<<21 +
+		uint64(s.lfsr[4])<<20 + uint64(s.lfsr[0])<<8 + uint64(s.lfsr[0]) + uint64(u)
+	v = v&0x7fffffff + v>>31
+	v = v&0x7fffffff + v>>31
+	if v == 0 {
+		v = 0x7fffffff
+	}
+	copy(s.lfsr[:], s.lfsr[1:])
+	s.lfsr[15] = uint32(v)
+}
+
+// keyword 生成一个32位密钥字
+func (s *zucState) keyword() uint32 {
+	s.bitReorganization()
+	z := s.f() ^ s.x[3]
+	s.lfsrInit(0)
+	return z
+}
+
+// XORKeyStream 实现cipher.Stream，支持分多次调用
+func (s *zucState) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("zuc: output smaller than input")
+	}
+	for len(src) > 0 {
+		if s.bufLen == 0 {
+			binary.BigEndian.PutUint32(s.buf[:], s.keyword())
+			s.bufLen = len(s.buf)
+		}
+		n := subtle.XORBytes(dst, src, s.buf[len(s.buf)-s.bufLen:])
+		s.bufLen -= n
+		dst, src = dst[n:], src[n:]
+	}
+}
+
+// zucSbox 对32位的4个字节依次使用S0、S1、S0、S1
+func zucSbox(x uint32) uint32 {
+	return uint32(zucS0[x>>24])<<24 | uint32(zucS1[x>>16&0xff])<<16 | uint32(zucS0[x>>8&0xff])<<8 | uint32(zucS1[x&0xff])
+}
+
+// zucL1 线性变换L1
+func zucL1(x uint32) uint32 {
+	return x ^ bits.RotateLeft32(x, 2) ^ bits.RotateLeft32(x, 10) ^ bits.RotateLeft32(x, 18) ^ bits.RotateLeft32(x, 24)
+}
+
+// zucL2 线性变换L2
+func zucL2(x uint32) uint32 {
+	return x ^ bits.RotateLeft32(x, 8) ^ bits.RotateLeft32(x, 14) ^ bits.RotateLeft32(x, 22) ^ bits.RotateLeft32(x, 30)
+}
@@ -0,0 +1,248 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_ZucKeystream(t *testing.T) {
+	tests := []struct {
+		name string
+		key  []byte
+		iv   []byte
+		want []uint32
+	}{
+		// GB/T 33133.1 附录A
+		{
+			name: "zuc128-zero",
+			key:  make([]byte, 16),
+			iv:   make([]byte, 16),
+			want: []uint32{0x27bede74, 0x018082da},
+		},
+		{
+			name: "zuc128-ff",
+			key:  bytes.Repeat([]byte{0xff}, 16),
+			iv:   bytes.Repeat([]byte{0xff}, 16),
+			want: []uint32{0x0657cfa0, 0x7096398b},
+		},
+		{
+			name: "zuc128-random",
+			key:  mustHex("3d4c4be96a82fdaeb58f641db17b455b"),
+			iv:   mustHex("84319aa8de6915ca1f6bda6bfbd8c766"),
+			want: []uint32{0x14f1c272, 0x3279c419},
+		},
+		// ZUC-256算法说明中的测试向量
+		{
+			name: "zuc256-zero",
+			key:  make([]byte, 32),
+			iv:   make([]byte, 25),
+			want: []uint32{
+				0x58d03ad6, 0x2e032ce2, 0xdafc683a, 0x39bdcb03, 0x52a2bc67,
+				0xf1b7de74, 0x163ce3a1, 0x01ef5558, 0x9639d75b, 0x95fa681b,
+				0x7f090df7, 0x56391ccc, 0x903b7612, 0x744d544c, 0x17bc3fad,
+				0x8b163b08, 0x21787c0b, 0x97775bb8, 0x4943c6bb, 0xe8ad8afd,
+			},
+		},
+		{
+			name: "zuc256-ff",
+			key:  bytes.Repeat([]byte{0xff}, 32),
+			iv:   append(bytes.Repeat([]byte{0xff}, 17), bytes.Repeat([]byte{0x3f}, 8)...),
+			want: []uint32{
+				0x3356cbae, 0xd1a1c18b, 0x6baa4ffe, 0x343f777c, 0x9e15128f,
+				0x251ab65b, 0x949f7b26, 0xef7157f2, 0x96dd2fa9, 0xdf95e3ee,
+				0x7a5be02e, 0xc32ba585, 0x505af316, 0xc2f9ded2, 0x7cdbd935,
+				0xe441ce11, 0x15fd0a80, 0xbb7aef67, 0x68989416, 0xb8fac8c2,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ZucKeystream(tt.key, tt.iv, len(tt.want))
+			if err != nil {
+				t.Fatalf("ZucKeystream() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ZucKeystream() got = %x, want %x", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ZucEncryptDecrypt(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     []byte
+		iv      []byte
+		wantErr bool
+	}{
+		{name: "zuc128", key: make([]byte, 16), iv: make([]byte, 16)},
+		{name: "zuc256", key: make([]byte, 32), iv: make([]byte, 25)},
+		{name: "key", key: make([]byte, 24), iv: make([]byte, 16), wantErr: true},
+		{name: "iv", key: make([]byte, 32), iv: make([]byte, 23), wantErr: true},
+		{name: "iv-6bit", key: make([]byte, 32), iv: append(make([]byte, 24), 0x40), wantErr: true},
+	}
+	plaintext := []byte("zuc stream cipher test plaintext")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ciphertext, err := ZucEncrypt(tt.key, tt.iv, plaintext)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ZucEncrypt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got, _ := ZucDecrypt(tt.key, tt.iv, ciphertext)
+			if !bytes.Equal(got, plaintext) {
+				t.Errorf("ZucDecrypt() got = %s", got)
+			}
+			// 分多次调用XORKeyStream结果需要一致
+			stream, _ := NewZucCipher(tt.key, tt.iv)
+			parts := make([]byte, len(plaintext))
+			for _, r := range [][2]int{{0, 1}, {1, 6}, {6, 7}, {7, 20}, {20, len(plaintext)}} {
+				stream.XORKeyStream(parts[r[0]:r[1]], plaintext[r[0]:r[1]])
+			}
+			if !bytes.Equal(parts, ciphertext) {
+				t.Errorf("XORKeyStream() in parts got = %x, want %x", parts, ciphertext)
+			}
+		})
+	}
+}
+
+func Test_Zuc128Eea3(t *testing.T) {
+	// 3GPP 128-EEA3测试集1与2
+	tests := []struct {
+		name       string
+		key        []byte
+		count      uint32
+		bearer     byte
+		direction  byte
+		plaintext  []byte
+		ciphertext []byte
+		bitLength  int
+	}{
+		{
+			name:       "set1",
+			key:        mustHex("173d14ba5003731d7a60049470f00a29"),
+			count:      0x66035492,
+			bearer:     0xf,
+			plaintext:  mustHex("6cf65340735552ab0c9752fa6f9025fe0bd675d9005875b200000000"),
+			ciphertext: mustHex("a6c85fc66afb8533aafc2518dfe784940ee1e4b030238cc800"),
+			bitLength:  193,
+		},
+		{
+			// 测试集1的明文最后一个字节填充1，超出长度的位需要置0
+			name:       "set1-trailing-bits",
+			key:        mustHex("173d14ba5003731d7a60049470f00a29"),
+			count:      0x66035492,
+			bearer:     0xf,
+			plaintext:  mustHex("6cf65340735552ab0c9752fa6f9025fe0bd675d9005875b27f"),
+			ciphertext: mustHex("a6c85fc66afb8533aafc2518dfe784940ee1e4b030238cc800"),
+			bitLength:  193,
+		},
+		{
+			name:       "set1-bytes",
+			key:        mustHex("173d14ba5003731d7a60049470f00a29"),
+			count:      0x66035492,
+			bearer:     0xf,
+			plaintext:  mustHex("6cf65340735552ab0c9752fa6f9025fe0bd675d9005875b2"),
+			ciphertext: mustHex("a6c85fc66afb8533aafc2518dfe784940ee1e4b030238cc8"),
+			bitLength:  192,
+		},
+		{
+			name:      "set2",
+			key:       mustHex("e5bd3ea0eb55ade866c6ac58bd54302a"),
+			count:     0x56823,
+			bearer:    0x18,
+			direction: 1,
+			plaintext: mustHex("14a8ef693d678507bbe7270a7f67ff5006c3525b9807e467c4e56000ba338f5d42955903675182" +
+				"2246c80d3b38f07f4be2d8ff5805f5132229bde93bbbdcaf382bf1ee972fbf9977bada8945847a2a6c9ad34a667554e0" +
+				"4d1f7fa2c33241bd8f01ba220d"),
+			ciphertext: mustHex("131d43e0dea1be5c5a1bfd971d852cbf712d7b4f57961fea3208afa8bca433f456ad09c7417e" +
+				"58bc69cf8866d1353f74865e80781d202dfb3ecff7fcbc3b190fe82a204ed0e350fc0f6f2613b2f2bca6df5a473a57a4" +
+				"a00d985ebad880d6f23864a07b01"),
+			bitLength: 800,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Zuc128Eea3Encrypt(tt.key, tt.count, tt.bearer, tt.direction, tt.plaintext, tt.bitLength)
+			if err != nil || !bytes.Equal(got, tt.ciphertext) {
+				t.Errorf("Zuc128Eea3Encrypt() got = %x, want %x, error = %v", got, tt.ciphertext, err)
+			}
+			// 解密结果为明文的前bitLength位
+			want := append([]byte(nil), tt.plaintext[:(tt.bitLength+7)/8]...)
+			if remain := tt.bitLength % 8; remain != 0 {
+				want[len(want)-1] &= byte(0xff << (8 - remain))
+			}
+			got, err = Zuc128Eea3Decrypt(tt.key, tt.count, tt.bearer, tt.direction, tt.ciphertext, tt.bitLength)
+			if err != nil || !bytes.Equal(got, want) {
+				t.Errorf("Zuc128Eea3Decrypt() got = %x, want %x, error = %v", got, want, err)
+			}
+		})
+	}
+	if _, err := Zuc128Eea3Encrypt(make([]byte, 16), 0, 0x20, 0, nil, 0); err == nil {
+		t.Errorf("Zuc128Eea3Encrypt() with invalid bearer error = nil, wantErr true")
+	}
+	for _, bitLength := range []int{-1, 17} {
+		if _, err := Zuc128Eea3Encrypt(make([]byte, 16), 0, 0, 0, make([]byte, 2), bitLength); err == nil {
+			t.Errorf("Zuc128Eea3Encrypt() with bit length %d error = nil, wantErr true", bitLength)
+		}
+	}
+}
+
+func Test_Zuc128Eia3(t *testing.T) {
+	// 3GPP 128-EIA3测试集1与2
+	tests := []struct {
+		name      string
+		key       []byte
+		count     uint32
+		bearer    byte
+		direction byte
+		message   []byte
+		bitLength int
+		want      []byte
+	}{
+		{
+			name:      "set1",
+			key:       make([]byte, 16),
+			message:   make([]byte, 4),
+			bitLength: 1,
+			want:      mustHex("c8a9595e"),
+		},
+		{
+			name:      "set2",
+			key:       mustHex("c9e6cec4607c72db000aefa88385ab0a"),
+			count:     0xa94059da,
+			bearer:    0x0a,
+			direction: 1,
+			message: mustHex("983b41d47d780c9e1ad11d7eb70391b1de0b35da2dc62f83e7b78d6306ca0ea07e941b7be91348f9" +
+				"fcb170e2217fecd97f9f68adb16e5d7d21e569d280ed775cebde3f4093c5388100000000"),
+			bitLength: 0x241,
+			want:      mustHex("fae8ff0b"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Zuc128Eia3(tt.key, tt.count, tt.bearer, tt.direction, tt.message, tt.bitLength)
+			if err != nil || !bytes.Equal(got, tt.want) {
+				t.Errorf("Zuc128Eia3() got = %x, want %x, error = %v", got, tt.want, err)
+			}
+			if err = Zuc128Eia3Verify(tt.key, tt.count, tt.bearer, tt.direction, tt.message, tt.bitLength,
+				tt.want); err != nil {
+				t.Errorf("Zuc128Eia3Verify() error = %v", err)
+			}
+			err = Zuc128Eia3Verify(tt.key, tt.count, tt.bearer, tt.direction^1, tt.message, tt.bitLength, tt.want)
+			if !errors.Is(err, ErrMacMismatch) {
+				t.Errorf("Zuc128Eia3Verify() with wrong direction error = %v, want %v", err, ErrMacMismatch)
+			}
+		})
+	}
+	if _, err := Zuc128Eia3(make([]byte, 16), 0, 0, 0, make([]byte, 1), 9); err == nil {
+		t.Errorf("Zuc128Eia3() with invalid bit length error = nil, wantErr true")
+	}
+	if _, err := Zuc128Eia3(make([]byte, 32), 0, 0, 0, nil, 0); err == nil {
+		t.Errorf("Zuc128Eia3() with zuc-256 key error = nil, wantErr true")
+	}
+}